@@ -0,0 +1,9 @@
+// Package assets embeds the sprites, fonts and backgrounds the example games are built with,
+//   so they run from wherever they're launched.
+package assets
+
+import "embed"
+
+// FS holds the assets by their paths in this directory, eg: "sprites/character.json"
+//go:embed backgrounds fonts sprites
+var FS embed.FS
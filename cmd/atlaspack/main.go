@@ -0,0 +1,94 @@
+// atlaspack packs images into texture atlas pages ahead of time, for games to load with engine.LoadAtlas.
+//
+//   atlaspack [-o atlas.json] [-size 2048] [-padding 2] [-extrude 1] images or directories...
+//
+// Directories are searched for PNG, BMP, JPEG and GIF images. Each image's region is named after its path
+// as it was found, eg: assets/sprites/overworld.bmp, so it matches the path the game loads it by.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ryanhartje/gogome/pkg/atlas"
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/ryanhartje/gogome/pkg/vfs"
+)
+
+// imageExts are the extensions of the files packed from directories
+var imageExts = map[string]bool{".bmp": true, ".gif": true, ".jpeg": true, ".jpg": true, ".png": true}
+
+func main() {
+	out := flag.String("o", "atlas.json", "where to write the atlas manifest, pages are written next to it")
+	size := flag.Int("size", atlas.DefaultSize, "the most a page can be wide and high")
+	padding := flag.Int("padding", 2, "transparent pixels left between images")
+	extrude := flag.Int("extrude", 1, "pixels to repeat the edges of each image by")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: atlaspack [flags] images or directories...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	paths, err := findImages(flag.Args())
+	checkErr(err)
+	var images []atlas.Image
+	for _, path := range paths {
+		img, err := engine.LoadImage(path)
+		if err != nil {
+			checkErr(fmt.Errorf("%s: %v", path, err))
+		}
+		images = append(images, atlas.Image{Image: img, Name: path})
+	}
+
+	packer := atlas.Packer{Extrude: *extrude, Padding: *padding, Width: *size, Height: *size}
+	packed, err := packer.Pack(images)
+	checkErr(err)
+	checkErr(packed.Save(*out))
+	fmt.Printf("packed %d images into %d pages\n", len(packed.Regions), len(packed.Pages))
+}
+
+// findImages expands directories into the images in them, sorted, keeping files as they're given
+func findImages(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, vfs.Clean(arg))
+			continue
+		}
+		var found []string
+		err = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && imageExts[strings.ToLower(filepath.Ext(path))] {
+				found = append(found, vfs.Clean(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+func checkErr(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "atlaspack:", err)
+		os.Exit(1)
+	}
+}
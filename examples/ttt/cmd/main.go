@@ -4,18 +4,27 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"reflect"
+	"path/filepath"
 	"time"
 
+	"github.com/ryanhartje/gogome/assets"
+	"github.com/ryanhartje/gogome/pkg/atlas"
 	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/ryanhartje/gogome/pkg/input"
+	"github.com/ryanhartje/gogome/pkg/physics"
+	"github.com/ryanhartje/gogome/pkg/ui"
+	"github.com/ryanhartje/gogome/pkg/vfs"
 	"github.com/veandco/go-sdl2/mix"
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/ttf"
 )
 
 const (
 	winW = 800
 	winH = 600
+
+	// actions our game binds on top of the engine's defaults
+	actionDebugGrid = "debug_grid"
+	actionQuit      = "quit"
 )
 
 var (
@@ -28,8 +37,42 @@ func main() {
 		debug = true
 		fmt.Println("debug mode enabled")
 	}
+	// Load the assets built into the game, overlaid by any next to the executable or in the working directory,
+	//   so they can be modded or patched on disk. HMMODS mounts a zip of mods over all of them.
+	files := vfs.New()
+	files.Mount("assets", assets.FS)
+	if exe, err := os.Executable(); err == nil {
+		files.Mount("", vfs.Dir(filepath.Dir(exe)))
+	}
+	files.Mount("", vfs.Dir("."))
+	if path := os.Getenv("HMMODS"); path != "" {
+		mods, err := vfs.Zip(path)
+		checkErr(err)
+		defer mods.Close()
+		files.Mount("", mods)
+	}
+	engine.FS = files
+
 	e := engine.NewEngine()
 	e.Init()
+	// 16 ticks a second looks more natural for our 8 bit style animations
+	e.TickRate = 16
+
+	// HMRECORD records a replay to attach to bug reports, HMREPLAY plays one back
+	if path := os.Getenv("HMRECORD"); path != "" {
+		f, err := os.Create(path)
+		checkErr(err)
+		defer f.Close()
+		checkErr(e.Record(f))
+	}
+	if path := os.Getenv("HMREPLAY"); path != "" {
+		f, err := os.Open(path)
+		checkErr(err)
+		replay, err := engine.LoadReplay(f)
+		f.Close()
+		checkErr(err)
+		e.Play(replay)
+	}
 
 	window, err := sdl.CreateWindow(
 		"hackerman",
@@ -42,20 +85,30 @@ func main() {
 	checkErr(err)
 	window.UpdateSurface()
 
-	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
+	sdlRenderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED)
 	checkErr(err)
-	defer renderer.Destroy()
+	renderer := engine.NewSDLRenderer(sdlRenderer)
+	e.Window = window
+	e.Renderer = renderer
+	// Pick up changes to sprites and maps without restarting while debugging
+	e.Assets.HotReload = debug
 
-	player, err := engine.NewPlayer(renderer, "assets/sprites/character.png")
+	player, err := engine.NewPlayer(e.Assets, "assets/sprites/character.json")
 	checkErr(err)
 
 	// Load in our level asset and generate a random map
 	level, err := engine.NewRandomizedLevel("assets/sprites/overworld.bmp", renderer)
 	checkErr(err)
-	level.CameraX = winW
-	level.CameraY = winH
-	level.XSize = winW * 10
-	level.YSize = winH * 10
+	level.Camera.W = winW
+	level.Camera.H = winH
+
+	// Keep the player inside the level and out of its walls, and have the camera follow them around it loosely
+	player.Bounds = level.Bounds()
+	player.Collider = level
+	level.Camera.DeadzoneW = 128
+	level.Camera.DeadzoneH = 96
+	level.Camera.Smoothing = 8
+	level.Camera.Follow(player)
 
 	// Attempted to provide a lighting effect by providing an alpha layer over the viewport/camera
 	// It had weird implementation effects
@@ -80,14 +133,26 @@ func main() {
 	*/
 
 	// setup a dummy enemy
-	enemy, err := engine.NewEnemy("computer", renderer)
-	enemy.LevelX = 8 * 100
-	enemy.LevelY = 8 * 20
+	enemy, err := engine.NewEnemy("computer", e.Assets)
+	checkErr(err)
+	enemy.SetCoords(8*100, 8*20)
+	enemy.Collider = level
+	level.AddEntity(enemy)
 
-	if reflect.TypeOf(level.EntityMap[0]) == reflect.TypeOf(nil) {
-		panic("EntityMap is nil after assignment. Can't render entities")
-	}
-	level.EntityMap[0][0] = enemy
+	// Pack the level's tiles and our sprites onto one atlas, so drawing a frame doesn't keep switching textures
+	sprites, err := engine.PackAtlas(renderer, atlas.Packer{Extrude: 1, Padding: 2},
+		level.BGFile, player.Anim.Sheet.Image, enemy.Anim.Sheet.Image)
+	checkErr(err)
+	level.UseAtlas(sprites)
+	sprites.Relocate(player.Anim.Sheet, player.Anim.Sheet.Image)
+	sprites.Relocate(enemy.Anim.Sheet, enemy.Anim.Sheet.Image)
+
+	// The level is top down so there's no gravity, physics just keeps the player from walking through the enemy
+	world := engine.NewPhysics(physics.Vec{})
+	world.World.Terrain = level
+	player.Body = physics.NewBody(physics.AABB{W: 32, H: 64}, 1)
+	world.Add(player, player.Body)
+	world.Add(enemy, physics.NewBody(physics.AABB{W: 32, H: 32}, 0))
 
 	// Setup audio
 	if err := mix.OpenAudio(44100, mix.DEFAULT_FORMAT, 2, 4096); err != nil {
@@ -101,138 +166,186 @@ func main() {
 	// level.Sounds["background"] = append(level.Sounds["background"], chunk)
 	//e.PlayWAV(level.Sounds["background"][0])
 
-	font64, err := ttf.OpenFont("assets/fonts/monogram.ttf", 64)
+	monogram64, err := e.Assets.LoadFont("assets/fonts/monogram.ttf", 64)
 	checkErr(err)
-	font32, err := ttf.OpenFont("assets/fonts/monogram.ttf", 32)
+	monogram32, err := e.Assets.LoadFont("assets/fonts/monogram.ttf", 32)
 	checkErr(err)
+	font64, font32 := engine.Glyphs(monogram64, false), engine.Glyphs(monogram32, false)
 
+	// Menus are drawn in monogram, and our labels are placed by hand over the background
+	theme := ui.DefaultTheme(font32)
 	var sineCounter float64
-	sineFunc := func(text *engine.Text) {
+	sineFunc := func(label *ui.Label) {
 		sineCounter += math.Pi / 16
 		if sineCounter >= math.Pi {
 			sineCounter = 0
 		}
 		alpha := math.Sin(sineCounter) * 255
-		text.Color = sdl.Color{R: 255, G: 255, B: 255, A: uint8(alpha)}
-		text.X -= 10
-	}
-	var menuText []engine.Text
-	menuText = append(menuText, engine.Text{
-		Color: sdl.Color{R: 255, G: 255, B: 255, A: 240},
-		Font:  font64,
-		Text:  "Tyler the Tiler",
-		X:     200,
-		Y:     450,
-	})
-	menuText = append(menuText, engine.Text{
-		Color:   sdl.Color{R: 255, G: 255, B: 255, A: 240},
-		Effects: []func(*engine.Text){sineFunc},
-		Font:    font32,
+		label.Color = &sdl.Color{R: 255, G: 255, B: 255, A: uint8(alpha)}
+	}
+	title := &ui.Label{Base: ui.Base{X: 200, Y: 450}, Font: font64, Text: "Tyler the Tiler"}
+	prompt := &ui.Label{
+		Base:    ui.Base{X: 210, Y: 520},
+		Effects: []func(*ui.Label){sineFunc},
 		Text:    "Press spacebar to continue...",
-		X:       210,
-		Y:       520,
-	})
+	}
 
-	// Setup a main menu, real retro like
-	keyMapFuncs := make(map[sdl.Keycode]func(*engine.Menu))
-	keyMapFuncs[sdl.K_SPACE] = func(menu *engine.Menu) {
+	game := &tyler{
+		engine:   e,
+		entities: []engine.Entity{player, enemy},
+		level:    level,
+		physics:  world,
+		player:   player,
+		queue:    engine.NewRenderQueue(),
+	}
+
+	// Setup a pause menu to draw over the level when the player hits escape
+	input.Default.Bind(actionQuit, input.KeyBinding(sdl.SCANCODE_Q), input.ButtonBinding(sdl.CONTROLLER_BUTTON_BACK))
+	input.Default.Bind(actionDebugGrid, input.KeyBinding(sdl.SCANCODE_G))
+	pauseKeys := make(map[string]func(*engine.Menu))
+	pauseKeys[input.Pause] = func(menu *engine.Menu) {
 		menu.Break = true
 	}
-	keyMapFuncs[sdl.K_ESCAPE] = func(menu *engine.Menu) {
-		e.Quit()
-		sdl.Quit()
-		os.Exit(0)
+	pauseKeys[actionQuit] = func(menu *engine.Menu) {
+		e.Stop()
+	}
+	pauseMenu := &engine.Menu{
+		Debug:      debug,
+		KeyMapping: pauseKeys,
+		WinH:       winH,
+		WinW:       winW,
+	}
+	paused := &ui.Label{Font: font64, Text: "Paused"}
+	resume := ui.NewButton("Resume", func() { pauseMenu.Break = true })
+	resume.Anchor = ui.Fill
+	quit := ui.NewButton("Quit", e.Stop)
+	quit.Anchor = ui.Fill
+	hint := ui.NewLabel("Escape to resume, Q to quit")
+	// Buttons fill the width of the stack, the labels are centered in it
+	pauseStack := ui.NewStack(paused, resume, quit, hint)
+	pauseStack.Align = engine.AlignCenter
+	pausePanel := ui.NewPanel(pauseStack)
+	pausePanel.Anchor = ui.Center
+	pauseMenu.Widgets = []engine.Widget{ui.NewScreen(theme, pausePanel)}
+	game.pause = engine.NewMenuScene(pauseMenu)
+
+	// The first time the player bumps into the enemy, it has a word with them in a dialog box over the level
+	talkMenu := &engine.Menu{Debug: debug, WinH: winH, WinW: winW}
+	dialog := ui.NewDialogBox(func() { talkMenu.Break = true })
+	dialog.Background, err = engine.LoadNineSlice("assets/sprites/dialog.png", e.Assets, 4)
+	checkErr(err)
+	talkMenu.Widgets = []engine.Widget{ui.NewScreen(theme, dialog)}
+	talk := engine.NewMenuScene(talkMenu)
+	talked := false
+	player.Body.OnCollide = func(c physics.Collision) {
+		if c.Other.Data != enemy {
+			return
+		}
+		level.Camera.Shake(4, 200*time.Millisecond)
+		if talked {
+			return
+		}
+		talked = true
+		frame, _ := enemy.Anim.Sheet.Frame(0)
+		portrait := &ui.Image{Src: frame, Texture: enemy.Anim.Sheet.Texture}
+		dialog.Say(
+			ui.Message{Portrait: portrait, Speaker: "Computer", Text: "BEEP. Intruder detected in sector [color=red]7G[/color]."},
+			ui.Message{
+				Portrait: portrait,
+				Speaker:  "Computer",
+				Text: "Tiles in this level are placed at random, so no two walks through it are the same. " +
+					"Walls keep you in, and the camera keeps up with you as you go. " +
+					"Press [color=yellow]escape[/color] to pause, and [color=yellow]Q[/color] from there to quit.",
+			},
+		)
+		e.Scenes.PushOverlay(talk, nil)
+	}
+
+	// Setup a main menu, real retro like
+	keyMapFuncs := make(map[string]func(*engine.Menu))
+	keyMapFuncs[input.Confirm] = func(menu *engine.Menu) {
+		e.Scenes.Replace(game, engine.NewFade(time.Second))
+	}
+	keyMapFuncs[input.Cancel] = func(menu *engine.Menu) {
+		e.Stop()
 	}
-	menu := engine.Menu{
+	menu := &engine.Menu{
 		BGImagePath: "assets/backgrounds/main.png",
 		BGSizeX:     384,
 		BGSizeY:     244,
-		Components:  menuText,
+		Widgets:     []engine.Widget{ui.NewScreen(theme, title, prompt)},
 		Debug:       debug,
 		KeyMapping:  keyMapFuncs,
 		WinH:        winH,
 		WinW:        winW,
 	}
-	menu.Loop(renderer)
-
-	// Set tick rate to 8 FPS
-	// 8 looks more natural for our 8 bit style animations
-	tick := time.NewTicker(time.Second / 16)
+	e.Scenes.Push(engine.NewMenuScene(menu), nil)
+	e.Run(e.Scenes)
+	e.Quit()
+}
 
+// tyler is our game, it walks the level and its entities on every tick of the engine
+type tyler struct {
+	engine   *engine.Engine
+	entities []engine.Entity
 	// lastDebugMsg is used as a cache, to make sure we only print debug
 	//   messages if they are new messages. This helps us not flood output
 	//   during the main loop. While it is set to a tick rate, multiple messages per tick get our of hand.
 	//   log is used to log out the combined output of mainloop in debug mode.
-	var lastDebugMsg string
-	entities := []engine.Entity{player, enemy}
-	for {
-		renderer.Clear()
-		// Setup ESC to exit keybinding
-		keys := sdl.GetKeyboardState()
-		if keys[sdl.SCANCODE_ESCAPE] == 1 {
-			os.Exit(0)
-		}
+	lastDebugMsg string
+	level        *engine.Level
+	pause        engine.Scene
+	physics      *engine.Physics
+	player       *engine.Player
+	queue        *engine.RenderQueue
+}
 
-		// Setup a tick rate
-		select {
-		case <-tick.C:
-			for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-				switch t := event.(type) {
-				case *sdl.QuitEvent:
-					e.Quit()
-					sdl.Quit()
-					os.Exit(0)
-
-				case *sdl.KeyboardEvent:
-					if debug && t.Keysym.Scancode == sdl.SCANCODE_G && t.State == 1 {
-						level.Debug = !level.Debug
-					}
-					// If you want to explore keybinding values, you can comment this out and they will log
-					//   to your console.
-					/*
-						if debug {
-							log += fmt.Sprintf("%s[%d ms] Keyboard\ttype:%d\tsym:%c\tmodifiers:%d\tstate:%d\trepeat:%d\n",
-								log, t.Timestamp, t.Type, t.Keysym.Sym, t.Keysym.Mod, t.State, t.Repeat)
-						}
-					*/
-
-					/*
-						case *sdl.MouseButtonEvent:
-									x, y, state := sdl.GetMouseState()
-									if state == 1 {
-										coords := fmt.Sprintf("(%d, %d)", x, y)
-										text := engine.NewText(renderer, coords, float64(x), float64(y))
-										entities = append(entities, text)
-									}
-					*/
-
-				}
-			}
-			originalX := level.X
-			originalY := level.Y
-			level.Draw(renderer)
-			level.Update()
-
-			for _, e := range entities {
-				eX, eY := e.GetLevelCoords()
-				isPlayer := reflect.TypeOf(e) == reflect.TypeOf(player)
-				inCameraView := eX >= level.X && eX < level.X+winW && eY >= level.Y && eY < level.Y+winH
-				if inCameraView || isPlayer {
-					e.Draw(renderer, level.X, level.Y)
-				}
-				e.Update(level.X-originalX, level.Y-originalY)
-			}
-			renderer.Present()
+// Enter satisfies the engine.Scene interface
+func (t *tyler) Enter(e *engine.Engine) {}
 
-			if debug {
-				if log != lastDebugMsg {
-					fmt.Println(log)
-				}
-				lastDebugMsg = log
-				log = ""
-			}
+// Exit satisfies the engine.Scene interface
+func (t *tyler) Exit(e *engine.Engine) {}
+
+// HandleEvent satisfies the engine.Scene interface, our keybindings are read as actions in Update
+func (t *tyler) HandleEvent(event sdl.Event) {}
+
+// Update moves the camera, updates every entity, then steps physics
+func (t *tyler) Update(dt float64) {
+	if input.Default.Pressed(input.Pause) {
+		t.engine.Scenes.PushOverlay(t.pause, nil)
+		return
+	}
+	if debug && input.Default.Pressed(actionDebugGrid) {
+		t.level.Debug = !t.level.Debug
+	}
+
+	t.level.Update(dt)
+	for _, e := range t.entities {
+		e.Update(dt)
+	}
+	t.physics.Update(dt)
+	for _, e := range t.entities {
+		t.level.MoveEntity(e)
+	}
+
+	if debug {
+		if log != t.lastDebugMsg {
+			fmt.Println(log)
 		}
+		t.lastDebugMsg = log
+		log = ""
+	}
+}
+
+// Draw queues the level and any entities in view of the camera, then draws them all at once.
+//   Entities are sorted by where they stand, so the player can walk behind the enemy
+func (t *tyler) Draw(renderer engine.Renderer, alpha float64) {
+	t.level.Submit(t.queue)
+	for _, e := range t.level.VisibleEntities() {
+		engine.SubmitEntity(t.queue, e, t.level.Camera)
 	}
+	engine.SubmitEntity(t.queue, t.player, t.level.Camera)
+	checkErr(t.queue.Flush(renderer))
 }
 
 func checkErr(err error) {
@@ -2,53 +2,71 @@ package ttt
 
 import (
 	"math/rand"
+	"time"
+
 	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/ryanhartje/gogome/pkg/physics"
+	"github.com/veandco/go-sdl2/sdl"
 )
 
+// cosmetic is the random source for overlays. They're drawn at whatever the frame rate is, so using engine.Rand
+//   would change how much of it each tick gets, and replays of the effects using it would play out differently.
+var cosmetic = rand.New(rand.NewSource(time.Now().UnixNano()))
+
 // Wondering creates a casual wondering effect, the entity will move arbitrarily on each update
+//   engine.Rand is used so wondering plays back the same way in a replay
 func Wondering(player *engine.Player) {
-		// 10% of the time, drunkenly step left or right
-	if rand.Intn(10) < 1 {
-	// 10% of the time, drunkenly step left or right
-		if rand.Intn(2) == 1 {
+	// 10% of the time, drunkenly step up or down
+	if engine.Rand.Intn(10) < 1 {
+		if engine.Rand.Intn(2) == 1 {
 			player.Move(0, -1)
 		} else {
 			player.Move(0, 1)
-	}
 		}
 	}
-	if rand.Intn(10) < 1 {
-		if rand.Intn(2) == 1 {
+	// 10% of the time, drunkenly step left or right
+	if engine.Rand.Intn(10) < 1 {
+		if engine.Rand.Intn(2) == 1 {
 			player.Move(-1, 0)
 		} else {
 			player.Move(1, 0)
-	}
 		}
 	}
 }
 
-// Gravity emulates falling until the player reaches 0.
-// Maybe it shouldn't have bounds, but instead kill the player if y > winH + player.SizeY (once the player is off screen)
-func Gravity(player *engine.Player) {
-	// keep in mind that SDL has a reversed Y coordinate system, where 0,0 is the top [left] of the screen, and winH,0 is the bottom [left] of the screen
-	if player.Y < winH-float64(player.SizeY*2) {
-		player.Y += 8
-		player.SpriteXPos = 9
+// Gravity returns an effect that drops the player into a physics world, where they fall until they land on something.
+//   Once they've landed, moving up jumps. The world needs a downward gravity for the player to fall.
+func Gravity(world *engine.Physics) func(*engine.Player) {
+	return func(player *engine.Player) {
+		if player.Body == nil {
+			player.Body = physics.NewBody(physics.AABB{W: float64(player.SizeX), H: float64(player.SizeY)}, 1)
+			player.JumpSpeed = 320
+			world.Add(player, player.Body)
+		}
+		// Show the falling frame while the player is in the air
+		if !player.Body.OnGround {
+			player.Anim.Play("fall")
+		}
 	}
 }
 
-
-// Bleeding renders randomized red boxes to the screen
-func Bleeding(player *engine.Player) {
-	droplets := rand.Intn(10)
+// Bleeding renders randomized red boxes over the player, add it to their Overlays
+func Bleeding(player *engine.Player, renderer engine.Renderer, camera *engine.Camera) {
+	// Droplets land in the middle half of the player, which a player this small doesn't have
+	if player.SizeX < 2 || player.SizeY < 2 {
+		return
+	}
+	x, y := camera.WorldToScreen(player.X, player.Y)
+	droplets := cosmetic.Intn(10)
 	for i := 0; i < droplets; i++ {
-		randX := int32(player.X+8) + rand.Int31n(player.SizeX)
-		randY := int32(player.Y+16) + rand.Int31n(player.SizeY)
-		gfx.RoundedBoxColor(renderer, randX, randY, randX+4, randY+4, 1, sdl.Color{255, 0, 0, uint8(rand.Intn(255))})
+		randX := x + player.SizeX/4 + cosmetic.Int31n(player.SizeX/2)
+		randY := y + player.SizeY/4 + cosmetic.Int31n(player.SizeY/2)
+		renderer.RoundedBox(randX, randY, randX+4, randY+4, 1, sdl.Color{R: 255, G: 0, B: 0, A: uint8(cosmetic.Intn(255))})
 	}
 }
 
-// Hitbox is a debugging friendly way to visualize where sprites collide. Assign this effect to entities to draw a hitbox over the sprite
-func Hitbox(player *engine.Player) {
-	gfx.BoxColor(renderer, int32(player.X), int32(player.Y), int32(player.X)+16, int32(player.Y)+32, sdl.Color{255, 255, 255, 255})
+// Hitbox is a debugging friendly way to visualize where sprites collide. Add it to a player's Overlays to draw a hitbox over the sprite
+func Hitbox(player *engine.Player, renderer engine.Renderer, camera *engine.Camera) {
+	x, y := camera.WorldToScreen(player.X, player.Y)
+	renderer.Box(x, y, x+player.SizeX, y+player.SizeY, sdl.Color{R: 255, G: 255, B: 255, A: 255})
 }
@@ -1,5 +1,5 @@
 module github.com/ryanhartje/gogome
 
-go 1.13
+go 1.16
 
 require github.com/veandco/go-sdl2 v0.4.4
@@ -0,0 +1,264 @@
+// Package atlas packs images into texture atlas pages, so many sprites and tilesets can be drawn from one texture.
+//   Images are placed with the max-rects algorithm, with padding between them and their edges extruded
+//   so neighbouring images don't bleed into each other when drawn scaled or at fractional positions.
+package atlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultSize is how wide and high pages are at most when a Packer doesn't set its own size
+const DefaultSize = 2048
+
+// Image is an image to pack, by the name its region is looked up with
+type Image struct {
+	Image image.Image
+	Name  string
+}
+
+// Region is where a packed image is on its page
+type Region struct {
+	Name string `json:"name"`
+	Page int    `json:"page"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	W    int    `json:"w"`
+	H    int    `json:"h"`
+}
+
+// Atlas is a set of packed pages, and the regions of the images packed into them
+type Atlas struct {
+	Pages []*image.NRGBA
+	// Regions are sorted by name
+	Regions []Region
+}
+
+// Manifest is how an atlas's regions are saved as JSON, alongside its pages saved as PNGs.
+//   Pages are paths relative to the manifest.
+type Manifest struct {
+	Pages   []string `json:"pages"`
+	Regions []Region `json:"regions"`
+}
+
+// Packer packs images into pages. The zero value packs into DefaultSize pages with no padding or extrusion.
+type Packer struct {
+	// Extrude repeats the edge pixels of each image this many pixels outward, so sampling just past
+	//   the edge of a region picks up the image's own colors instead of whatever is next to it
+	Extrude int
+	// Padding is how many transparent pixels are left between extruded images
+	Padding int
+	// Width and Height are the most a page can be, DefaultSize is used when they're 0.
+	//   Pages are cropped to what's packed into them.
+	Width, Height int
+}
+
+// packed is an image and where it went
+type packed struct {
+	image Image
+	page  int
+	cell  rect
+}
+
+// Pack packs images into as many pages as they need, biggest first.
+//   It's an error for two images to share a name, or for an image not to fit on a page by itself.
+func (p Packer) Pack(images []Image) (*Atlas, error) {
+	width, height := p.Width, p.Height
+	if width <= 0 {
+		width = DefaultSize
+	}
+	if height <= 0 {
+		height = DefaultSize
+	}
+	if p.Extrude < 0 || p.Padding < 0 {
+		return nil, fmt.Errorf("atlas: extrude and padding can't be negative")
+	}
+
+	order := make([]Image, len(images))
+	copy(order, images)
+	seen := map[string]bool{}
+	for _, img := range order {
+		if seen[img.Name] {
+			return nil, fmt.Errorf("atlas: more than one image is named %q", img.Name)
+		}
+		seen[img.Name] = true
+	}
+	// Big images first leaves the small ones to fill in the gaps, name breaks ties so packing is repeatable
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i].Image.Bounds(), order[j].Image.Bounds()
+		if sa, sb := maxInt(a.Dx(), a.Dy()), maxInt(b.Dx(), b.Dy()); sa != sb {
+			return sa > sb
+		}
+		if aa, ab := a.Dx()*a.Dy(), b.Dx()*b.Dy(); aa != ab {
+			return aa > ab
+		}
+		return order[i].Name < order[j].Name
+	})
+
+	// Each image takes up a cell of itself, its extruded edges and the padding to its right and below.
+	//   Pages are padded the same, so the last row and column don't need room for padding they'll never use.
+	var pages []*maxRects
+	var placed []packed
+	for _, img := range order {
+		bounds := img.Image.Bounds()
+		w := bounds.Dx() + 2*p.Extrude + p.Padding
+		h := bounds.Dy() + 2*p.Extrude + p.Padding
+		done := false
+		for i, page := range pages {
+			if cell, ok := page.insert(w, h); ok {
+				placed = append(placed, packed{image: img, page: i, cell: cell})
+				done = true
+				break
+			}
+		}
+		if done {
+			continue
+		}
+		page := newMaxRects(width+p.Padding, height+p.Padding)
+		cell, ok := page.insert(w, h)
+		if !ok {
+			return nil, fmt.Errorf("atlas: %s is %dx%d, too big for a %dx%d page", img.Name, bounds.Dx(), bounds.Dy(), width, height)
+		}
+		pages = append(pages, page)
+		placed = append(placed, packed{image: img, page: len(pages) - 1, cell: cell})
+	}
+
+	// Crop each page to the cells on it
+	sizes := make([]image.Point, len(pages))
+	for _, pl := range placed {
+		size := &sizes[pl.page]
+		size.X = maxInt(size.X, pl.cell.x+pl.cell.w-p.Padding)
+		size.Y = maxInt(size.Y, pl.cell.y+pl.cell.h-p.Padding)
+	}
+	atlas := &Atlas{}
+	for _, size := range sizes {
+		atlas.Pages = append(atlas.Pages, image.NewNRGBA(image.Rect(0, 0, size.X, size.Y)))
+	}
+
+	for _, pl := range placed {
+		bounds := pl.image.Image.Bounds()
+		region := Region{
+			Name: pl.image.Name,
+			Page: pl.page,
+			X:    pl.cell.x + p.Extrude,
+			Y:    pl.cell.y + p.Extrude,
+			W:    bounds.Dx(),
+			H:    bounds.Dy(),
+		}
+		page := atlas.Pages[pl.page]
+		draw.Draw(page, image.Rect(region.X, region.Y, region.X+region.W, region.Y+region.H), pl.image.Image, bounds.Min, draw.Src)
+		extrude(page, region, p.Extrude)
+		atlas.Regions = append(atlas.Regions, region)
+	}
+	sort.Slice(atlas.Regions, func(i, j int) bool { return atlas.Regions[i].Name < atlas.Regions[j].Name })
+	return atlas, nil
+}
+
+// Region returns the region of the image packed under name
+func (a *Atlas) Region(name string) (Region, bool) {
+	i := sort.Search(len(a.Regions), func(i int) bool { return a.Regions[i].Name >= name })
+	if i < len(a.Regions) && a.Regions[i].Name == name {
+		return a.Regions[i], true
+	}
+	return Region{}, false
+}
+
+// Save writes the atlas's manifest to path, with its pages as PNGs next to it.
+//   Saving to sprites.json writes pages sprites-0.png, sprites-1.png and so on.
+func (a *Atlas) Save(path string) error {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	manifest := Manifest{Regions: a.Regions}
+	for i, page := range a.Pages {
+		name := fmt.Sprintf("%s-%d.png", base, i)
+		if err := savePNG(filepath.Join(filepath.Dir(path), name), page); err != nil {
+			return err
+		}
+		manifest.Pages = append(manifest.Pages, name)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := manifest.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the manifest as JSON
+func (m Manifest) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// ReadManifest reads a manifest written by Save
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return Manifest{}, err
+	}
+	for _, region := range m.Regions {
+		if region.Page < 0 || region.Page >= len(m.Pages) {
+			return Manifest{}, fmt.Errorf("atlas: %s is on page %d, but there are %d", region.Name, region.Page, len(m.Pages))
+		}
+	}
+	return m, nil
+}
+
+// extrude copies the edge pixels of a region outward by n pixels, corners included
+func extrude(page *image.NRGBA, region Region, n int) {
+	if n <= 0 || region.W == 0 || region.H == 0 {
+		return
+	}
+	for y := region.Y - n; y < region.Y+region.H+n; y++ {
+		for x := region.X - n; x < region.X+region.W+n; x++ {
+			inside := x >= region.X && x < region.X+region.W && y >= region.Y && y < region.Y+region.H
+			if inside {
+				continue
+			}
+			edgeX := clamp(x, region.X, region.X+region.W-1)
+			edgeY := clamp(y, region.Y, region.Y+region.H-1)
+			page.SetNRGBA(x, y, page.NRGBAAt(edgeX, edgeY))
+		}
+	}
+}
+
+func savePNG(path string, page image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, page); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
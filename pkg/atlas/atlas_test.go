@@ -0,0 +1,200 @@
+package atlas
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// solid returns a w x h image of a single color
+func solid(w, h int, c color.NRGBA) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	green = color.NRGBA{G: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+)
+
+// grow returns r grown by n pixels on every side
+func grow(r Region, n int) image.Rectangle {
+	return image.Rect(r.X-n, r.Y-n, r.X+r.W+n, r.Y+r.H+n)
+}
+
+func TestPack(t *testing.T) {
+	images := []Image{
+		{Image: solid(10, 10, red), Name: "red"},
+		{Image: solid(20, 5, green), Name: "green"},
+		{Image: solid(5, 30, blue), Name: "blue"},
+	}
+	for _, p := range []Packer{{}, {Padding: 2}, {Extrude: 1}, {Extrude: 2, Padding: 3, Width: 64, Height: 64}} {
+		a, err := p.Pack(images)
+		if err != nil {
+			t.Fatalf("%+v: %v", p, err)
+		}
+		if len(a.Pages) != 1 {
+			t.Fatalf("%+v: packed onto %d pages, want 1", p, len(a.Pages))
+		}
+		names := []string{}
+		for _, r := range a.Regions {
+			names = append(names, r.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"blue", "green", "red"}) {
+			t.Errorf("%+v: regions are %v, want them sorted by name", p, names)
+		}
+
+		page := a.Pages[0]
+		for i, r := range a.Regions {
+			img := images[map[string]int{"red": 0, "green": 1, "blue": 2}[r.Name]].Image
+			if r.W != img.Bounds().Dx() || r.H != img.Bounds().Dy() {
+				t.Errorf("%+v: %s is %dx%d, want %dx%d", p, r.Name, r.W, r.H, img.Bounds().Dx(), img.Bounds().Dy())
+			}
+			// Extruded edges stay on the page, and the padding keeps them apart from every other region's
+			outer := grow(r, p.Extrude)
+			if !outer.In(page.Bounds()) {
+				t.Errorf("%+v: %s extruded to %v is off the %v page", p, r.Name, outer, page.Bounds())
+			}
+			for _, other := range a.Regions[i+1:] {
+				if outer.Overlaps(grow(other, p.Extrude+p.Padding)) {
+					t.Errorf("%+v: %s and %s are closer than %d pixels", p, r.Name, other.Name, p.Padding)
+				}
+			}
+			// The image and its extruded edges, corners included, are all its color
+			want := img.At(0, 0)
+			for y := outer.Min.Y; y < outer.Max.Y; y++ {
+				for x := outer.Min.X; x < outer.Max.X; x++ {
+					if got := page.NRGBAAt(x, y); got != want {
+						t.Fatalf("%+v: %s has %v at %d,%d, want %v", p, r.Name, got, x, y, want)
+					}
+				}
+			}
+		}
+		// Pages are cropped to what's on them
+		if p.Width == 64 && (page.Bounds().Dx() >= 64 || page.Bounds().Dy() >= 64) {
+			t.Errorf("%+v: page is %v, want it cropped", p, page.Bounds())
+		}
+	}
+}
+
+func TestPackPages(t *testing.T) {
+	var images []Image
+	for i := 0; i < 5; i++ {
+		images = append(images, Image{Image: solid(16, 16, red), Name: fmt.Sprint(i)})
+	}
+	// Four fit on a page
+	a, err := Packer{Width: 32, Height: 32}.Pack(images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Pages) != 2 {
+		t.Fatalf("packed onto %d pages, want 2", len(a.Pages))
+	}
+	perPage := map[int]int{}
+	for _, r := range a.Regions {
+		perPage[r.Page]++
+	}
+	if perPage[0] != 4 || perPage[1] != 1 {
+		t.Errorf("pages have %v regions, want 4 then 1", perPage)
+	}
+	if a.Pages[1].Bounds() != image.Rect(0, 0, 16, 16) {
+		t.Errorf("second page is %v, want it cropped to 16x16", a.Pages[1].Bounds())
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	tests := []struct {
+		packer Packer
+		images []Image
+		want   string
+	}{
+		{Packer{}, []Image{{Image: solid(1, 1, red), Name: "a"}, {Image: solid(2, 2, red), Name: "a"}}, "more than one"},
+		{Packer{Width: 16, Height: 16}, []Image{{Image: solid(17, 1, red), Name: "wide"}}, "too big"},
+		// Extruding makes an image that fits by itself too big
+		{Packer{Width: 16, Height: 16, Extrude: 1}, []Image{{Image: solid(16, 16, red), Name: "full"}}, "too big"},
+		{Packer{Padding: -1}, nil, "negative"},
+	}
+	for _, test := range tests {
+		if _, err := test.packer.Pack(test.images); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%+v packing %d images: got %v, want an error about %q", test.packer, len(test.images), err, test.want)
+		}
+	}
+}
+
+func TestRegion(t *testing.T) {
+	a, err := Packer{}.Pack([]Image{{Image: solid(1, 1, red), Name: "b"}, {Image: solid(1, 1, red), Name: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := a.Region("b"); !ok || r.Name != "b" {
+		t.Errorf("Region(b) = %+v, %v", r, ok)
+	}
+	if _, ok := a.Region("c"); ok {
+		t.Error("found a region that wasn't packed")
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	m := Manifest{
+		Pages:   []string{"sprites-0.png", "sprites-1.png"},
+		Regions: []Region{{Name: "a", X: 1, Y: 2, W: 3, H: 4}, {Name: "b", Page: 1, W: 5, H: 6}},
+	}
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, m) {
+		t.Errorf("read back %+v, want %+v", read, m)
+	}
+
+	if _, err := ReadManifest(strings.NewReader(`{"pages": ["a.png"], "regions": [{"name": "b", "page": 1}]}`)); err == nil {
+		t.Error("read a manifest with a region on a page that isn't there")
+	}
+}
+
+func TestSave(t *testing.T) {
+	a, err := Packer{Width: 16, Height: 16}.Pack([]Image{
+		{Image: solid(16, 16, red), Name: "red"},
+		{Image: solid(8, 8, green), Name: "green"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := a.Save(filepath.Join(dir, "sprites.json")); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, "sprites.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := ReadManifest(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Pages, []string{"sprites-0.png", "sprites-1.png"}) || !reflect.DeepEqual(m.Regions, a.Regions) {
+		t.Errorf("saved %+v, want both pages and %+v", m, a.Regions)
+	}
+	for _, page := range m.Pages {
+		if _, err := os.Stat(filepath.Join(dir, page)); err != nil {
+			t.Error(err)
+		}
+	}
+}
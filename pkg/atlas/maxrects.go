@@ -0,0 +1,93 @@
+package atlas
+
+// rect is an area of a page in pixels
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) intersects(o rect) bool {
+	return r.x < o.x+o.w && o.x < r.x+r.w && r.y < o.y+o.h && o.y < r.y+r.h
+}
+
+func (r rect) contains(o rect) bool {
+	return o.x >= r.x && o.y >= r.y && o.x+o.w <= r.x+r.w && o.y+o.h <= r.y+r.h
+}
+
+// maxRects packs rects into a page by keeping track of every maximal free rect left on it.
+//   Each rect goes in the free rect it fits most snugly along its shorter side (best short side fit).
+type maxRects struct {
+	free []rect
+}
+
+func newMaxRects(w, h int) *maxRects {
+	return &maxRects{free: []rect{{w: w, h: h}}}
+}
+
+// insert finds room for a w by h rect, false if it doesn't fit anywhere
+func (m *maxRects) insert(w, h int) (rect, bool) {
+	best := -1
+	bestShort, bestLong := 0, 0
+	for i, f := range m.free {
+		if w > f.w || h > f.h {
+			continue
+		}
+		short, long := f.w-w, f.h-h
+		if short > long {
+			short, long = long, short
+		}
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return rect{}, false
+	}
+
+	placed := rect{x: m.free[best].x, y: m.free[best].y, w: w, h: h}
+	m.split(placed)
+	m.prune()
+	return placed, true
+}
+
+// split carves used out of every free rect it overlaps, leaving the largest free rects around it
+func (m *maxRects) split(used rect) {
+	var free []rect
+	for _, f := range m.free {
+		if !f.intersects(used) {
+			free = append(free, f)
+			continue
+		}
+		if used.x > f.x {
+			free = append(free, rect{x: f.x, y: f.y, w: used.x - f.x, h: f.h})
+		}
+		if used.x+used.w < f.x+f.w {
+			free = append(free, rect{x: used.x + used.w, y: f.y, w: f.x + f.w - used.x - used.w, h: f.h})
+		}
+		if used.y > f.y {
+			free = append(free, rect{x: f.x, y: f.y, w: f.w, h: used.y - f.y})
+		}
+		if used.y+used.h < f.y+f.h {
+			free = append(free, rect{x: f.x, y: used.y + used.h, w: f.w, h: f.y + f.h - used.y - used.h})
+		}
+	}
+	m.free = free
+}
+
+// prune drops free rects that lie inside another, they'd never be a better fit
+func (m *maxRects) prune() {
+	var free []rect
+	for i, f := range m.free {
+		contained := false
+		for j, o := range m.free {
+			// Of two identical rects, keep the first
+			if i != j && o.contains(f) && (!f.contains(o) || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			free = append(free, f)
+		}
+	}
+	m.free = free
+}
@@ -0,0 +1,51 @@
+package atlas
+
+import "testing"
+
+func TestMaxRectsFillsPage(t *testing.T) {
+	// Sixteen 16x16 rects exactly fill a 64x64 page, with nowhere left for another
+	m := newMaxRects(64, 64)
+	var placed []rect
+	for i := 0; i < 16; i++ {
+		r, ok := m.insert(16, 16)
+		if !ok {
+			t.Fatalf("rect %d didn't fit", i)
+		}
+		if !(rect{w: 64, h: 64}).contains(r) {
+			t.Errorf("rect %d at %+v is off the page", i, r)
+		}
+		for j, other := range placed {
+			if r.intersects(other) {
+				t.Errorf("rect %d at %+v overlaps rect %d at %+v", i, r, j, other)
+			}
+		}
+		placed = append(placed, r)
+	}
+	if r, ok := m.insert(1, 1); ok {
+		t.Errorf("a rect fit at %+v on a full page", r)
+	}
+}
+
+func TestMaxRectsMixedSizes(t *testing.T) {
+	m := newMaxRects(100, 100)
+	sizes := [][2]int{{60, 40}, {40, 100}, {60, 60}}
+	var placed []rect
+	for _, size := range sizes {
+		r, ok := m.insert(size[0], size[1])
+		if !ok {
+			t.Fatalf("%dx%d didn't fit", size[0], size[1])
+		}
+		if r.w != size[0] || r.h != size[1] {
+			t.Errorf("%dx%d was placed as %dx%d", size[0], size[1], r.w, r.h)
+		}
+		for _, other := range placed {
+			if r.intersects(other) {
+				t.Errorf("%+v overlaps %+v", r, other)
+			}
+		}
+		placed = append(placed, r)
+	}
+	if _, ok := m.insert(101, 1); ok {
+		t.Error("a rect wider than the page fit")
+	}
+}
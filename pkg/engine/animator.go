@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// PlayMode is what a clip does when it reaches its last frame
+type PlayMode int
+
+const (
+	// Loop starts the clip over from its first frame
+	Loop PlayMode = iota
+	// PingPong plays the clip backwards to its first frame, then forwards again
+	PingPong
+	// Once stops on the last frame
+	Once
+)
+
+// Clip is a named sequence of frames from a SpriteSheet
+type Clip struct {
+	// Durations is how long each frame is shown for. A clip with fewer durations than frames
+	//   shows the rest of its frames for its last duration
+	Durations []time.Duration
+	// Events are fired when the frame at an index of Frames is shown, eg: a footstep sound
+	Events map[int]string
+	// Frames are frame numbers in the sheet
+	Frames []int
+	Mode   PlayMode
+	Name   string
+}
+
+// NewClip creates a clip showing each of its frames for the same amount of time
+func NewClip(name string, mode PlayMode, frameTime time.Duration, frames ...int) *Clip {
+	return &Clip{
+		Durations: []time.Duration{frameTime},
+		Frames:    frames,
+		Mode:      mode,
+		Name:      name,
+	}
+}
+
+// On fires event when the frame at index of the clip is shown, returning the clip so calls can be chained
+func (c *Clip) On(index int, event string) *Clip {
+	if c.Events == nil {
+		c.Events = map[int]string{}
+	}
+	c.Events[index] = event
+	return c
+}
+
+// duration returns how long the frame at index is shown for
+func (c *Clip) duration(index int) float64 {
+	if len(c.Durations) == 0 {
+		return 0
+	}
+	if index >= len(c.Durations) {
+		index = len(c.Durations) - 1
+	}
+	return c.Durations[index].Seconds()
+}
+
+// Animator plays clips from a sprite sheet. It's advanced by time rather than ticks, so
+//   clips play at the same speed whatever the engine's tick rate is.
+type Animator struct {
+	// OnEvent is called with the clip and event name when a frame with an event is shown
+	OnEvent func(clip, event string)
+	Sheet   *SpriteSheet
+
+	clips     map[string]*Clip
+	current   *Clip
+	direction int
+	done      bool
+	elapsed   float64
+	index     int
+}
+
+// NewAnimator creates an animator for a sheet with the given clips
+func NewAnimator(sheet *SpriteSheet, clips ...*Clip) *Animator {
+	a := &Animator{
+		Sheet: sheet,
+		clips: map[string]*Clip{},
+	}
+	for _, clip := range clips {
+		a.Add(clip)
+	}
+	return a
+}
+
+// Add adds a clip to the animator, replacing any clip with the same name
+func (a *Animator) Add(clip *Clip) {
+	a.clips[clip.Name] = clip
+}
+
+// Clips returns the clip of each name
+func (a *Animator) Clips() map[string]*Clip {
+	return a.clips
+}
+
+// Play switches to the named clip, returning false if there's no such clip.
+//   Playing the clip that's already playing carries on from where it is, so it's safe to call every tick.
+func (a *Animator) Play(name string) bool {
+	clip, ok := a.clips[name]
+	if !ok {
+		return false
+	}
+	if clip != a.current {
+		a.current = clip
+		a.restart()
+	}
+	return true
+}
+
+// Restart plays the current clip again from its first frame
+func (a *Animator) Restart() {
+	if a.current != nil {
+		a.restart()
+	}
+}
+
+// Playing returns the name of the current clip
+func (a *Animator) Playing() string {
+	if a.current == nil {
+		return ""
+	}
+	return a.current.Name
+}
+
+// Done reports whether a clip played Once has reached its last frame
+func (a *Animator) Done() bool {
+	return a.done
+}
+
+// Update advances the current clip by dt seconds, firing the events of any frames it shows
+func (a *Animator) Update(dt float64) {
+	clip := a.current
+	if clip == nil || a.done || len(clip.Frames) < 2 {
+		return
+	}
+	a.elapsed += dt
+	for !a.done {
+		d := clip.duration(a.index)
+		if d <= 0 || a.elapsed < d {
+			return
+		}
+		a.elapsed -= d
+		a.advance()
+	}
+}
+
+// Frame returns the frame number in the sheet being shown
+func (a *Animator) Frame() int {
+	if a.current == nil || len(a.current.Frames) == 0 {
+		return 0
+	}
+	return a.current.Frames[a.index]
+}
+
+// Rect returns the part of the sheet's texture being shown
+func (a *Animator) Rect() sdl.Rect {
+	rect, _ := a.Sheet.Frame(a.Frame())
+	return rect
+}
+
+// Draw draws the frame being shown to dst on the screen
+func (a *Animator) Draw(renderer Renderer, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	return a.Sheet.Draw(renderer, a.Frame(), dst, angle, flip)
+}
+
+// Submit queues the frame being shown to be drawn to dst on the screen, on a layer of queue at sort key
+func (a *Animator) Submit(queue *RenderQueue, layer int, key float64, dst sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	cmd, err := a.Sheet.Command(a.Frame(), dst, angle, flip)
+	if err != nil {
+		return err
+	}
+	cmd.Layer, cmd.SortKey = layer, key
+	queue.Submit(cmd)
+	return nil
+}
+
+// restart goes back to the first frame of the current clip
+func (a *Animator) restart() {
+	a.index, a.direction, a.elapsed, a.done = 0, 1, 0, false
+	a.fire()
+}
+
+// advance moves to the next frame of the current clip as its mode says to
+func (a *Animator) advance() {
+	clip := a.current
+	last := len(clip.Frames) - 1
+	next := a.index + a.direction
+	switch {
+	case next > last && clip.Mode == Loop:
+		next = 0
+	case next > last && clip.Mode == PingPong:
+		a.direction = -1
+		next = last - 1
+	case next > last:
+		next = last
+		a.done = true
+	case next < 0:
+		a.direction = 1
+		next = 1
+	}
+	if next == a.index {
+		return
+	}
+	a.index = next
+	a.fire()
+}
+
+// fire calls OnEvent if the frame being shown has an event
+func (a *Animator) fire() {
+	if a.OnEvent == nil {
+		return
+	}
+	if event, ok := a.current.Events[a.index]; ok {
+		a.OnEvent(a.current.Name, event)
+	}
+}
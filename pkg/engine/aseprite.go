@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Slice is a named area of a sheet's frames marked out in Aseprite, like a hitbox.
+//   It can change from frame to frame, each key holds from its frame until the next key.
+type Slice struct {
+	Keys []SliceKey
+	Name string
+}
+
+// SliceKey is where a slice is from a frame onwards
+type SliceKey struct {
+	Bounds sdl.Rect
+	// Frame is the index into the sheet's frames the key starts at
+	Frame int
+	// Pivot is relative to the top left of Bounds, HasPivot is false when the slice has no pivot
+	HasPivot bool
+	Pivot    sdl.Point
+}
+
+// At returns the key of the slice that holds at a frame
+func (s *Slice) At(frame int) (SliceKey, bool) {
+	var key SliceKey
+	found := false
+	for _, k := range s.Keys {
+		if k.Frame <= frame && (!found || k.Frame >= key.Frame) {
+			key, found = k, true
+		}
+	}
+	return key, found
+}
+
+// asepriteRect is a rect as Aseprite exports it
+type asepriteRect struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+func (r asepriteRect) rect() sdl.Rect {
+	return sdl.Rect{X: r.X, Y: r.Y, W: r.W, H: r.H}
+}
+
+type asepriteFrame struct {
+	Filename         string       `json:"filename"`
+	Frame            asepriteRect `json:"frame"`
+	Rotated          bool         `json:"rotated"`
+	Trimmed          bool         `json:"trimmed"`
+	SpriteSourceSize asepriteRect `json:"spriteSourceSize"`
+	SourceSize       struct {
+		W int32 `json:"w"`
+		H int32 `json:"h"`
+	} `json:"sourceSize"`
+	// Duration is in milliseconds
+	Duration int `json:"duration"`
+}
+
+type asepriteFile struct {
+	// Frames is an array in the array format, and an object keyed by file name in the hash format
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"`
+		} `json:"frameTags"`
+		Slices []struct {
+			Name string `json:"name"`
+			Keys []struct {
+				Frame  int          `json:"frame"`
+				Bounds asepriteRect `json:"bounds"`
+				Pivot  *sdl.Point   `json:"pivot"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+// LoadAseprite loads a sprite sheet exported from Aseprite as JSON, in either the hash or array format,
+//   along with the image it names. Each frame tag becomes a clip of the same name, played in the tag's
+//   direction with the frames' own durations. A tag repeated once is played Once, any other tag loops.
+//   Frames are named regions of the sheet by their file name, and slices are kept in the sheet's Slices.
+//   The image is loaded with textures, pass Assets to share it with everything else that uses it.
+func LoadAseprite(path string, textures TextureLoader) (*Animator, error) {
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file asepriteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("engine: %s: %v", path, err)
+	}
+	frames, err := file.frames()
+	if err != nil {
+		return nil, fmt.Errorf("engine: %s: %v", path, err)
+	}
+	if file.Meta.Image == "" {
+		return nil, fmt.Errorf("engine: %s doesn't name its image", path)
+	}
+	image := filepath.Join(filepath.Dir(path), file.Meta.Image)
+	texture, err := textures.LoadTexture(image)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := NewRegionSheet(texture)
+	sheet.Image = image
+	durations := make([]time.Duration, len(frames))
+	for i, frame := range frames {
+		if frame.Rotated {
+			return nil, fmt.Errorf("engine: %s: frame %q is rotated, which isn't supported", path, frame.Filename)
+		}
+		sheet.AddRegion(frame.Filename, frame.Frame.rect())
+		sheet.Sources = append(sheet.Sources, sdl.Rect{
+			X: frame.SpriteSourceSize.X,
+			Y: frame.SpriteSourceSize.Y,
+			W: frame.SourceSize.W,
+			H: frame.SourceSize.H,
+		})
+		durations[i] = time.Duration(frame.Duration) * time.Millisecond
+	}
+
+	for _, s := range file.Meta.Slices {
+		slice := &Slice{Name: s.Name}
+		for _, k := range s.Keys {
+			key := SliceKey{Bounds: k.Bounds.rect(), Frame: k.Frame}
+			if k.Pivot != nil {
+				key.HasPivot, key.Pivot = true, *k.Pivot
+			}
+			slice.Keys = append(slice.Keys, key)
+		}
+		sheet.Slices[s.Name] = slice
+	}
+
+	anim := NewAnimator(sheet)
+	for _, tag := range file.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return nil, fmt.Errorf("engine: %s: tag %q covers frames %d to %d, but there are %d", path, tag.Name, tag.From, tag.To, len(frames))
+		}
+		clip := &Clip{Mode: Loop, Name: tag.Name}
+		for i := tag.From; i <= tag.To; i++ {
+			clip.Frames = append(clip.Frames, i)
+			clip.Durations = append(clip.Durations, durations[i])
+		}
+		switch tag.Direction {
+		case "reverse":
+			reverseClip(clip)
+		case "pingpong":
+			clip.Mode = PingPong
+		case "pingpong_reverse":
+			reverseClip(clip)
+			clip.Mode = PingPong
+		}
+		if repeat, err := strconv.Atoi(tag.Repeat); err == nil && repeat == 1 {
+			clip.Mode = Once
+		}
+		anim.Add(clip)
+	}
+	return anim, nil
+}
+
+// frames decodes the frames of either format, in the order they're in the file
+func (file *asepriteFile) frames() ([]asepriteFrame, error) {
+	data := bytes.TrimSpace(file.Frames)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no frames")
+	}
+	var frames []asepriteFrame
+	if data[0] == '[' {
+		err := json.Unmarshal(data, &frames)
+		return frames, err
+	}
+
+	// The hash format is an object keyed by file name, a map would lose the order of the frames
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame asepriteFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.Filename = token.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// reverseClip reverses the order a clip's frames play in
+func reverseClip(clip *Clip) {
+	for i, j := 0, len(clip.Frames)-1; i < j; i, j = i+1, j-1 {
+		clip.Frames[i], clip.Frames[j] = clip.Frames[j], clip.Frames[i]
+		clip.Durations[i], clip.Durations[j] = clip.Durations[j], clip.Durations[i]
+	}
+}
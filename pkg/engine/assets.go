@@ -0,0 +1,392 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// DefaultPollInterval is how often Poll looks for changed files when Assets doesn't set its own interval
+const DefaultPollInterval = time.Second
+
+// TextureLoader is anything textures can be loaded from by path, like a Renderer or Assets
+type TextureLoader interface {
+	LoadTexture(path string) (Texture, error)
+}
+
+// Assets loads textures, fonts, sounds and maps by name from FS, and shares them between everything that loads them.
+//   A name is a path unless it's been given one with Define. Loading an asset takes a reference to it for its owner,
+//   however many times that owner loads it, and it's freed once every owner has released it.
+//   Assets loaded while a scene on the engine's SceneStack is entered, updated or drawn are released
+//   when the scene exits, anything loaded outside of a scene is held until Destroy.
+type Assets struct {
+	// HotReload has Poll reload textures and maps whose files have changed, for use while developing
+	HotReload bool
+	// OnReload is called after Poll reloads an asset, with the error if it couldn't be reloaded.
+	//   Failed reloads keep the asset as it was, and are printed if OnReload is nil.
+	OnReload func(name string, err error)
+	// PollInterval is how often Poll looks at the files on disk, DefaultPollInterval is used when it's 0
+	PollInterval time.Duration
+	// Renderer loads textures, the engine's Renderer is used when it's nil
+	Renderer Renderer
+
+	assets   map[string]*asset
+	engine   *Engine
+	held     map[interface{}]map[string]bool
+	lastPoll time.Time
+	names    map[string]string
+	owner    interface{}
+}
+
+// assetKind is what sort of thing an asset is, so it can be freed and reloaded the right way
+type assetKind int
+
+const (
+	textureAsset assetKind = iota
+	fontAsset
+	soundAsset
+	levelAsset
+)
+
+// asset is a single loaded file and how many references there are to it
+type asset struct {
+	kind    assetKind
+	modTime time.Time
+	path    string
+	refs    int
+	size    int
+	value   interface{}
+}
+
+// replaceable textures can take on the pixels of a freshly loaded texture of the same kind in place,
+//   so everything holding them sees a reloaded file without having to load it again
+type replaceable interface {
+	replace(fresh Texture) bool
+}
+
+// NewAssets creates an asset manager that loads textures with renderer
+func NewAssets(renderer Renderer) *Assets {
+	return &Assets{
+		Renderer: renderer,
+		assets:   map[string]*asset{},
+		held:     map[interface{}]map[string]bool{},
+		names:    map[string]string{},
+	}
+}
+
+// Define gives the file at path a name to load it by, eg: a.Define("player", "assets/sprites/character.json")
+func (a *Assets) Define(name, path string) {
+	a.names[name] = path
+}
+
+// Path returns the path of the file a name loads
+func (a *Assets) Path(name string) string {
+	if path, ok := a.names[name]; ok {
+		return path
+	}
+	return name
+}
+
+// LoadTexture returns the texture named name, loading it the first time it's asked for
+func (a *Assets) LoadTexture(name string) (Texture, error) {
+	value, err := a.acquire(name, textureAsset, 0, func(path string) (interface{}, error) {
+		renderer := a.renderer()
+		if renderer == nil {
+			return nil, fmt.Errorf("engine: no renderer to load %s with", path)
+		}
+		return renderer.LoadTexture(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(Texture), nil
+}
+
+// LoadFont returns the font named name at a point size, loading it the first time it's asked for at that size
+func (a *Assets) LoadFont(name string, size int) (*ttf.Font, error) {
+	value, err := a.acquire(name, fontAsset, size, func(path string) (interface{}, error) {
+		return OpenFont(path, size)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*ttf.Font), nil
+}
+
+// LoadSound returns the sound named name, loading it the first time it's asked for. The mixer must be open
+func (a *Assets) LoadSound(name string) (*mix.Chunk, error) {
+	value, err := a.acquire(name, soundAsset, 0, func(path string) (interface{}, error) {
+		return LoadSound(path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*mix.Chunk), nil
+}
+
+// LoadLevel returns the map named name, loading it the first time it's asked for.
+//   Tiled maps (.tmx or .tmj) are loaded with LoadTiledLevel, anything else is a level saved with Level.Save.
+//   Everything loading the same map shares the one Level. Its entities load their textures through a,
+//   so they're shared and released along with the map.
+func (a *Assets) LoadLevel(name string) (*Level, error) {
+	value, err := a.acquire(name, levelAsset, 0, func(path string) (interface{}, error) {
+		return a.loadLevel(path, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Level), nil
+}
+
+// LoadAseprite loads the Aseprite sprite sheet named name, see LoadAseprite.
+//   Animators aren't shared, but the image they're cut from is.
+func (a *Assets) LoadAseprite(name string) (*Animator, error) {
+	return LoadAseprite(a.Path(name), a)
+}
+
+// LoadBMFont loads the BMFont file named name, see LoadBMFont. Its pages are shared like any other texture
+func (a *Assets) LoadBMFont(name string) (*BitmapFont, error) {
+	return LoadBMFont(a.Path(name), a)
+}
+
+// Release gives up the current owner's reference to the asset named name, freeing it once nothing else holds it.
+//   Fonts are released with ReleaseFont, since each size of a font is its own asset
+func (a *Assets) Release(name string) {
+	a.release(a.holder(), assetKey(a.Path(name), 0))
+}
+
+// ReleaseFont gives up the current owner's reference to a font loaded with LoadFont
+func (a *Assets) ReleaseFont(name string, size int) {
+	a.release(a.holder(), assetKey(a.Path(name), size))
+}
+
+// WithOwner calls fn with everything it loads held by owner, until ReleaseOwner is called for it.
+//   The SceneStack does this for each of its scenes.
+func (a *Assets) WithOwner(owner interface{}, fn func()) {
+	if a == nil {
+		fn()
+		return
+	}
+	previous := a.owner
+	a.owner = owner
+	defer func() { a.owner = previous }()
+	fn()
+}
+
+// ReleaseOwner releases everything loaded on behalf of owner
+func (a *Assets) ReleaseOwner(owner interface{}) {
+	if a == nil {
+		return
+	}
+	for key := range a.held[owner] {
+		a.release(owner, key)
+	}
+}
+
+// Loaded returns the paths of every asset currently loaded, sorted
+func (a *Assets) Loaded() []string {
+	var paths []string
+	for _, asset := range a.assets {
+		paths = append(paths, asset.path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Poll reloads any textures and maps whose files have changed since they were loaded,
+//   at most once every PollInterval. The engine's Run calls it each frame when HotReload is on.
+//   Textures are reloaded in place, and so are a map's tiles, leaving its camera and entities be.
+func (a *Assets) Poll() {
+	interval := a.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	now := time.Now()
+	if now.Sub(a.lastPoll) < interval {
+		return
+	}
+	a.lastPoll = now
+
+	// Reload in a stable order, so one change reloading before another is predictable
+	var keys []string
+	for key, asset := range a.assets {
+		if asset.kind == textureAsset || asset.kind == levelAsset {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		asset := a.assets[key]
+		info, err := StatFile(asset.path)
+		if err != nil || !info.ModTime().After(asset.modTime) {
+			continue
+		}
+		asset.modTime = info.ModTime()
+		err = a.reload(asset)
+		if a.OnReload != nil {
+			a.OnReload(asset.path, err)
+		} else if err != nil {
+			fmt.Printf("engine: reloading %s: %v\n", asset.path, err)
+		}
+	}
+}
+
+// Destroy frees every asset, whatever holds it
+func (a *Assets) Destroy() {
+	for _, asset := range a.assets {
+		free(asset)
+	}
+	a.assets = map[string]*asset{}
+	a.held = map[interface{}]map[string]bool{}
+}
+
+// acquire returns the asset at name's path, loading it with load if it isn't loaded yet,
+//   and counts a reference to it unless the current owner already holds one
+func (a *Assets) acquire(name string, kind assetKind, size int, load func(path string) (interface{}, error)) (interface{}, error) {
+	path := a.Path(name)
+	key := assetKey(path, size)
+	loaded, ok := a.assets[key]
+	if !ok {
+		value, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		loaded = &asset{kind: kind, path: path, size: size, value: value}
+		if info, err := StatFile(path); err == nil {
+			loaded.modTime = info.ModTime()
+		}
+		a.assets[key] = loaded
+	}
+	if loaded.kind != kind {
+		return nil, fmt.Errorf("engine: %s is already loaded as something else", path)
+	}
+	owner := a.holder()
+	holds := a.held[owner]
+	if holds == nil {
+		holds = map[string]bool{}
+		a.held[owner] = holds
+	}
+	if !holds[key] {
+		holds[key] = true
+		loaded.refs++
+	}
+	return loaded.value, nil
+}
+
+// unowned holds the references to assets loaded outside of any owner
+type unowned struct{}
+
+// holder returns who holds what's loaded now, the current owner or unowned
+func (a *Assets) holder() interface{} {
+	if a.owner != nil {
+		return a.owner
+	}
+	return unowned{}
+}
+
+// release drops owner's reference to an asset, freeing it when it was the last one.
+//   Nothing happens if owner doesn't hold it, so an asset is never released twice for the same load.
+func (a *Assets) release(owner interface{}, key string) {
+	holds := a.held[owner]
+	if !holds[key] {
+		return
+	}
+	delete(holds, key)
+	if len(holds) == 0 {
+		delete(a.held, owner)
+	}
+	asset, ok := a.assets[key]
+	if !ok {
+		return
+	}
+	asset.refs--
+	if asset.refs > 0 {
+		return
+	}
+	delete(a.assets, key)
+	free(asset)
+}
+
+// reload loads an asset's file again and swaps it in for what's loaded
+func (a *Assets) reload(loaded *asset) error {
+	switch loaded.kind {
+	case textureAsset:
+		renderer := a.renderer()
+		if renderer == nil {
+			return fmt.Errorf("engine: no renderer to load %s with", loaded.path)
+		}
+		fresh, err := renderer.LoadTexture(loaded.path)
+		if err != nil {
+			return err
+		}
+		texture, ok := loaded.value.(replaceable)
+		if !ok || !texture.replace(fresh) {
+			fresh.Destroy()
+			return fmt.Errorf("engine: %s can't be reloaded in place", loaded.path)
+		}
+	case levelAsset:
+		// Only the tiles are swapped in, so there's no need to spawn the entities again
+		fresh, err := a.loadLevel(loaded.path, nil)
+		if err != nil {
+			return err
+		}
+		loaded.value.(*Level).replaceTiles(fresh.(*Level))
+	}
+	return nil
+}
+
+// loadLevel loads a map from path, picking the loader by its extension. Entities load their textures with entities
+func (a *Assets) loadLevel(path string, entities TextureLoader) (interface{}, error) {
+	renderer := a.renderer()
+	if renderer == nil {
+		return nil, fmt.Errorf("engine: no renderer to load %s with", path)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx", ".tmj":
+		return loadTiledLevel(path, renderer, entities)
+	}
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return readLevel(bytes.NewReader(data), renderer, entities)
+}
+
+// renderer returns the renderer textures are loaded with
+func (a *Assets) renderer() Renderer {
+	if a.Renderer != nil {
+		return a.Renderer
+	}
+	if a.engine != nil {
+		return a.engine.Renderer
+	}
+	return nil
+}
+
+// assetKey is what an asset is cached under, fonts are cached per size
+func assetKey(path string, size int) string {
+	if size == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s@%d", path, size)
+}
+
+// free releases whatever an asset holds onto
+func free(loaded *asset) {
+	switch value := loaded.value.(type) {
+	case Texture:
+		value.Destroy()
+	case *ttf.Font:
+		CloseFont(value)
+	case *mix.Chunk:
+		value.Free()
+	case *Level:
+		value.Destroy()
+	}
+}
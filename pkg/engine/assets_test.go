@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// testAssets returns assets that load textures from a software renderer, with a.png and b.png to load
+func testAssets(t *testing.T) *Assets {
+	withFS(t, fstest.MapFS{
+		"a.png": {Data: testPNG(t, 1, 1)},
+		"b.png": {Data: testPNG(t, 1, 1)},
+	})
+	return NewAssets(NewSoftwareRenderer(1, 1))
+}
+
+// load loads a texture on behalf of owner, nil loading it outside of any owner
+func load(t *testing.T, a *Assets, owner interface{}, name string) *ImageTexture {
+	t.Helper()
+	var texture Texture
+	var err error
+	a.WithOwner(owner, func() { texture, err = a.LoadTexture(name) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return texture.(*ImageTexture)
+}
+
+func TestAssetsLoadShared(t *testing.T) {
+	a := testAssets(t)
+	first := load(t, a, "scene", "a.png")
+	if load(t, a, "other", "a.png") != first {
+		t.Error("loading a.png twice loaded two textures")
+	}
+	if refs := a.assets["a.png"].refs; refs != 2 {
+		t.Errorf("a.png has %d references, want one for each owner", refs)
+	}
+}
+
+func TestAssetsLoadRepeatedly(t *testing.T) {
+	a := testAssets(t)
+	// Loading every frame holds the one reference
+	for i := 0; i < 100; i++ {
+		load(t, a, "scene", "a.png")
+		load(t, a, nil, "b.png")
+	}
+	if refs := a.assets["a.png"].refs; refs != 1 {
+		t.Errorf("a.png has %d references, want 1", refs)
+	}
+	if refs := a.assets["b.png"].refs; refs != 1 {
+		t.Errorf("b.png has %d references, want 1", refs)
+	}
+	if len(a.held["scene"]) != 1 {
+		t.Errorf("scene holds %d assets, want 1", len(a.held["scene"]))
+	}
+
+	texture := load(t, a, "scene", "a.png")
+	a.ReleaseOwner("scene")
+	if !texture.destroyed {
+		t.Error("a.png wasn't freed when the only owner released it")
+	}
+	texture = load(t, a, nil, "b.png")
+	a.Release("b.png")
+	if !texture.destroyed {
+		t.Error("b.png wasn't freed when it was released")
+	}
+	if loaded := a.Loaded(); len(loaded) != 0 {
+		t.Errorf("%v are still loaded", loaded)
+	}
+}
+
+func TestAssetsReleaseOwner(t *testing.T) {
+	a := testAssets(t)
+	texture := load(t, a, "scene", "a.png")
+	load(t, a, "scene", "b.png")
+	load(t, a, "other", "a.png")
+
+	a.ReleaseOwner("scene")
+	if texture.destroyed {
+		t.Error("a.png was freed while other still holds it")
+	}
+	if loaded := a.Loaded(); len(loaded) != 1 || loaded[0] != "a.png" {
+		t.Errorf("loaded %v, want only a.png", loaded)
+	}
+	// Releasing an owner again, or one that holds nothing, doesn't touch what others hold
+	a.ReleaseOwner("scene")
+	a.ReleaseOwner("nobody")
+	if refs := a.assets["a.png"].refs; refs != 1 {
+		t.Errorf("a.png has %d references, want 1", refs)
+	}
+
+	a.ReleaseOwner("other")
+	if !texture.destroyed {
+		t.Error("a.png wasn't freed when its last owner released it")
+	}
+}
+
+func TestAssetsReleaseThenReleaseOwner(t *testing.T) {
+	a := testAssets(t)
+	texture := load(t, a, "scene", "a.png")
+	load(t, a, "other", "a.png")
+
+	// The scene lets go of a.png itself, then exits
+	a.WithOwner("scene", func() { a.Release("a.png") })
+	a.ReleaseOwner("scene")
+	if texture.destroyed {
+		t.Fatal("a.png was released twice for the scene, freeing it from under other")
+	}
+	if refs := a.assets["a.png"].refs; refs != 1 {
+		t.Errorf("a.png has %d references, want 1", refs)
+	}
+
+	// Releasing what an owner doesn't hold does nothing
+	a.Release("a.png")
+	if texture.destroyed {
+		t.Error("releasing a.png outside of its owners freed it")
+	}
+}
+
+// sprite is an entity with a texture, to check where spawned entities load their textures from
+type sprite struct {
+	marker
+	texture Texture
+}
+
+func init() {
+	RegisterEntity("test_sprite", func(spawn Spawn, textures TextureLoader) (Entity, error) {
+		texture, err := textures.LoadTexture("a.png")
+		if err != nil {
+			return nil, err
+		}
+		return &sprite{marker: marker{name: spawn.Name}, texture: texture}, nil
+	})
+}
+
+func TestAssetsLoadLevelEntities(t *testing.T) {
+	a := testAssets(t)
+	FS.(fstest.MapFS)["level.tmj"] = &fstest.MapFile{Data: []byte(`{"width": 1, "height": 1, "tilewidth": 16, "tileheight": 16,
+		"layers": [{"type": "objectgroup", "objects": [{"class": "test_sprite", "x": 0, "y": 0}, {"class": "test_sprite", "x": 16, "y": 0}]}]}`)}
+
+	var level *Level
+	var err error
+	a.WithOwner("scene", func() { level, err = a.LoadLevel("level.tmj") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := level.Entities.Items()
+	if len(items) != 2 || items[0].(*sprite).texture != items[1].(*sprite).texture {
+		t.Fatalf("spawned %d sprites, want 2 sharing a texture", len(items))
+	}
+	texture := items[0].(*sprite).texture.(*ImageTexture)
+	if refs := a.assets["a.png"].refs; refs != 1 {
+		t.Errorf("a.png has %d references, want 1", refs)
+	}
+
+	a.ReleaseOwner("scene")
+	if !texture.destroyed {
+		t.Error("the sprites' texture wasn't freed with the level")
+	}
+	if loaded := a.Loaded(); len(loaded) != 0 {
+		t.Errorf("%v are still loaded", loaded)
+	}
+}
@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/ryanhartje/gogome/pkg/atlas"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Atlas is images packed onto a few large textures, so sprites and tiles cut from them can be drawn
+//   without switching textures. Pack one at runtime with PackAtlas, or ahead of time with cmd/atlaspack
+//   and load it with LoadAtlas.
+type Atlas struct {
+	// Pages are region sheets of each page, with a region named after every image packed onto it
+	Pages []*SpriteSheet
+}
+
+// LoadAtlas loads an atlas saved by atlas.Atlas.Save, eg: with cmd/atlaspack. Its pages are loaded with textures
+func LoadAtlas(path string, textures TextureLoader) (*Atlas, error) {
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := atlas.ReadManifest(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("engine: %s: %v", path, err)
+	}
+
+	a := &Atlas{}
+	for _, page := range manifest.Pages {
+		texture, err := textures.LoadTexture(filepath.Join(filepath.Dir(path), page))
+		if err != nil {
+			a.Destroy()
+			return nil, err
+		}
+		a.Pages = append(a.Pages, NewRegionSheet(texture))
+	}
+	for _, region := range manifest.Regions {
+		a.Pages[region.Page].AddRegion(region.Name, regionRect(region))
+	}
+	return a, nil
+}
+
+// PackAtlas loads images from FS and packs them into an atlas with packer, naming each region after the path it was loaded from
+func PackAtlas(renderer Renderer, packer atlas.Packer, paths ...string) (*Atlas, error) {
+	var images []atlas.Image
+	for _, path := range paths {
+		img, err := LoadImage(path)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, atlas.Image{Image: img, Name: path})
+	}
+	packed, err := packer.Pack(images)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Atlas{}
+	for _, page := range packed.Pages {
+		texture, err := TextureFromImage(renderer, page)
+		if err != nil {
+			a.Destroy()
+			return nil, err
+		}
+		a.Pages = append(a.Pages, NewRegionSheet(texture))
+	}
+	for _, region := range packed.Regions {
+		a.Pages[region.Page].AddRegion(region.Name, regionRect(region))
+	}
+	return a, nil
+}
+
+// Find returns the page an image was packed onto and the frame of its region there
+func (a *Atlas) Find(name string) (*SpriteSheet, int, bool) {
+	for _, page := range a.Pages {
+		if frame, ok := page.Region(name); ok {
+			return page, frame, true
+		}
+	}
+	return nil, 0, false
+}
+
+// Relocate moves a sheet cut from the image at path onto the atlas, so it draws from the atlas page instead,
+//   eg: a.Relocate(anim.Sheet, anim.Sheet.Image). It returns false, leaving the sheet as it was, if the image isn't in the atlas.
+//   A sheet that's already been relocated is moved from where it is, so relocating it again, or onto another atlas, is safe.
+func (a *Atlas) Relocate(sheet *SpriteSheet, path string) bool {
+	page, frame, ok := a.Find(path)
+	if !ok {
+		return false
+	}
+	region := page.Frames[frame]
+	dx, dy := region.X-sheet.origin.X, region.Y-sheet.origin.Y
+	for i := range sheet.Frames {
+		sheet.Frames[i].X += dx
+		sheet.Frames[i].Y += dy
+	}
+	sheet.origin = sdl.Point{X: region.X, Y: region.Y}
+	sheet.Texture = page.Texture
+	return true
+}
+
+// Destroy frees the atlas's pages
+func (a *Atlas) Destroy() {
+	for _, page := range a.Pages {
+		page.Texture.Destroy()
+	}
+}
+
+// UseAtlas moves the level's tiles onto an atlas holding the background and tilesets they're cut from,
+//   so the whole level draws from the atlas. Tiles cut from images that aren't in the atlas are left as they are.
+//   It returns how many tiles were moved.
+func (level *Level) UseAtlas(a *Atlas) int {
+	if level.TileMap == nil {
+		return 0
+	}
+	paths := map[Texture]string{}
+	if level.Texture != nil {
+		paths[level.Texture] = level.BGFile
+	}
+	for path, texture := range level.Textures {
+		paths[texture] = path
+	}
+
+	moved := 0
+	tileset := level.TileMap.Tileset
+	for id := 1; id <= tileset.Len(); id++ {
+		tile, _ := tileset.Tile(TileID(id))
+		texture := tile.Texture
+		if texture == nil {
+			texture = level.Texture
+		}
+		page, frame, ok := a.Find(paths[texture])
+		if !ok {
+			continue
+		}
+		region := page.Frames[frame]
+		tile.Texture = page.Texture
+		tile.X0 += region.X
+		tile.X1 += region.X
+		tile.Y0 += region.Y
+		tile.Y1 += region.Y
+		tileset.Replace(TileID(id), tile)
+		moved++
+	}
+	return moved
+}
+
+// LoadImage decodes an image file from FS. PNG, JPEG and GIF are decoded in Go, anything else (eg: BMP) with SDL_image
+func LoadImage(path string) (image.Image, error) {
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err == nil {
+		return decoded, nil
+	}
+	if err != image.ErrFormat {
+		return nil, err
+	}
+
+	surface, err := LoadSurface(path)
+	if err != nil {
+		return nil, err
+	}
+	defer surface.Free()
+	converted, err := surface.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer converted.Free()
+
+	img := image.NewNRGBA(image.Rect(0, 0, int(converted.W), int(converted.H)))
+	pixels := converted.Pixels()
+	for y := 0; y < int(converted.H); y++ {
+		row := pixels[y*int(converted.Pitch):]
+		copy(img.Pix[y*img.Stride:(y+1)*img.Stride], row[:img.Stride])
+	}
+	return img, nil
+}
+
+// TextureFromImage creates a texture renderer can draw from an image
+func TextureFromImage(renderer Renderer, img *image.NRGBA) (Texture, error) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, int32(w), int32(h), 32, uint32(sdl.PIXELFORMAT_RGBA32))
+	if err != nil {
+		return nil, err
+	}
+	defer surface.Free()
+	pixels := surface.Pixels()
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		copy(pixels[y*int(surface.Pitch):], row[:w*4])
+	}
+	return renderer.CreateTextureFromSurface(surface)
+}
+
+// regionRect is where a packed region is on its page
+func regionRect(region atlas.Region) sdl.Rect {
+	return sdl.Rect{X: int32(region.X), Y: int32(region.Y), W: int32(region.W), H: int32(region.H)}
+}
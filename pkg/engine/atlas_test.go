@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"image"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// testAtlas is an atlas of a single page with hero.png packed at x, y
+func testAtlas(x, y int32) *Atlas {
+	page := NewRegionSheet(NewImageTexture(image.NewRGBA(image.Rect(0, 0, 256, 256))))
+	page.AddRegion("hero.png", sdl.Rect{X: x, Y: y, W: 64, H: 32})
+	return &Atlas{Pages: []*SpriteSheet{page}}
+}
+
+func TestAtlasRelocate(t *testing.T) {
+	sheet, err := NewSpriteSheet(NewImageTexture(image.NewRGBA(image.Rect(0, 0, 64, 32))), 32, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(x, y int32) {
+		t.Helper()
+		want := []sdl.Rect{{X: x, Y: y, W: 32, H: 32}, {X: x + 32, Y: y, W: 32, H: 32}}
+		for i, frame := range sheet.Frames {
+			if frame != want[i] {
+				t.Errorf("frame %d is %v, want %v", i, frame, want[i])
+			}
+		}
+	}
+
+	a := testAtlas(32, 16)
+	if !a.Relocate(sheet, "hero.png") {
+		t.Fatal("hero.png wasn't found")
+	}
+	expect(32, 16)
+	if sheet.Texture != a.Pages[0].Texture {
+		t.Error("the sheet doesn't draw from the atlas page")
+	}
+	// Again, eg: after a reload, leaves it where it is
+	a.Relocate(sheet, "hero.png")
+	expect(32, 16)
+	// and onto another atlas moves it from where it is
+	b := testAtlas(0, 100)
+	b.Relocate(sheet, "hero.png")
+	expect(0, 100)
+
+	if b.Relocate(sheet, "villain.png") {
+		t.Error("relocated onto an image that isn't in the atlas")
+	}
+	expect(0, 100)
+}
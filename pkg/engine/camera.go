@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"math"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Camera looks at part of the level. It tracks where in the world it is, how big its view is on screen,
+//   and converts between world and screen coordinates for anything being drawn.
+type Camera struct {
+	// Bounds is the area of the world the camera is kept inside of, a zero sized rect leaves it unclamped
+	Bounds sdl.Rect
+	// DeadzoneW and DeadzoneH are the size, in world pixels, of a box in the middle of the view
+	//   the target can move around in without the camera following
+	DeadzoneW, DeadzoneH float64
+	// Smoothing is how quickly the camera catches up with its target, 0 snaps straight to it
+	//   and higher values close the gap faster
+	Smoothing float64
+	// Target is the entity the camera follows, or nil to leave the camera where it's put
+	Target Entity
+	// W and H are the size of the viewport on screen in pixels
+	W, H int
+	// X and Y are the world coordinates of the top left of the view
+	X, Y float64
+	// Zoom scales the world when it's drawn, 2 draws everything twice as big
+	Zoom float64
+
+	shakeDuration  float64
+	shakeLeft      float64
+	shakeMagnitude float64
+	shakeX, shakeY float64
+}
+
+// NewCamera creates a camera with a w by h viewport at the origin of the world
+func NewCamera(w, h int) *Camera {
+	return &Camera{
+		W:    w,
+		H:    h,
+		Zoom: 1,
+	}
+}
+
+// Follow sets the entity the camera follows and centers the camera on it
+func (c *Camera) Follow(target Entity) {
+	c.Target = target
+	if target == nil {
+		return
+	}
+	x, y := c.targetCenter()
+	viewW, viewH := c.ViewSize()
+	c.X = x - viewW/2
+	c.Y = y - viewH/2
+	c.clamp()
+}
+
+// ViewSize returns how much of the world the camera can see, taking zoom into account
+func (c *Camera) ViewSize() (float64, float64) {
+	zoom := c.zoom()
+	return float64(c.W) / zoom, float64(c.H) / zoom
+}
+
+// Update moves the camera towards its target, keeps it inside its bounds and advances any shake
+func (c *Camera) Update(dt float64) {
+	if c.Target != nil {
+		x, y := c.targetCenter()
+		viewW, viewH := c.ViewSize()
+		wantX := follow(c.X+viewW/2, x, c.DeadzoneW) - viewW/2
+		wantY := follow(c.Y+viewH/2, y, c.DeadzoneH) - viewH/2
+		if c.Smoothing > 0 {
+			// Exponential smoothing closes the same fraction of the gap each second, whatever the tick rate
+			t := 1 - math.Exp(-c.Smoothing*dt)
+			c.X += (wantX - c.X) * t
+			c.Y += (wantY - c.Y) * t
+		} else {
+			c.X, c.Y = wantX, wantY
+		}
+	}
+	c.clamp()
+
+	c.shakeX, c.shakeY = 0, 0
+	if c.shakeLeft > 0 {
+		c.shakeLeft -= dt
+		// Shake fades out over its duration. engine.Rand is used so it plays back the same in a replay
+		strength := c.shakeMagnitude * math.Max(c.shakeLeft, 0) / c.shakeDuration
+		c.shakeX = (Rand.Float64()*2 - 1) * strength
+		c.shakeY = (Rand.Float64()*2 - 1) * strength
+	}
+}
+
+// Shake jiggles the view by up to magnitude world pixels, easing off over d
+func (c *Camera) Shake(magnitude float64, d time.Duration) {
+	c.shakeMagnitude = magnitude
+	c.shakeDuration = d.Seconds()
+	c.shakeLeft = d.Seconds()
+}
+
+// WorldToScreen converts world coordinates to where they are drawn on screen
+func (c *Camera) WorldToScreen(x, y float64) (int32, int32) {
+	zoom := c.zoom()
+	return int32(math.Floor((x - c.X - c.shakeX) * zoom)), int32(math.Floor((y - c.Y - c.shakeY) * zoom))
+}
+
+// ScreenToWorld converts screen coordinates, eg: the mouse position, to world coordinates
+func (c *Camera) ScreenToWorld(x, y int32) (float64, float64) {
+	zoom := c.zoom()
+	return float64(x)/zoom + c.X + c.shakeX, float64(y)/zoom + c.Y + c.shakeY
+}
+
+// WorldRect converts a rect in the world to the rect it's drawn to on screen.
+//   Both corners are converted so neighbouring rects, like tiles, don't leave gaps when zoomed.
+func (c *Camera) WorldRect(x, y, w, h float64) *sdl.Rect {
+	x0, y0 := c.WorldToScreen(x, y)
+	x1, y1 := c.WorldToScreen(x+w, y+h)
+	return &sdl.Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+}
+
+// InView reports whether any of a rect in the world can be seen by the camera
+func (c *Camera) InView(x, y, w, h float64) bool {
+	viewW, viewH := c.ViewSize()
+	return x+w > c.X && x < c.X+viewW && y+h > c.Y && y < c.Y+viewH
+}
+
+// zoom returns the camera's zoom, treating 0 as unzoomed
+func (c *Camera) zoom() float64 {
+	if c.Zoom <= 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// targetCenter returns the world coordinates of the middle of the target
+func (c *Camera) targetCenter() (float64, float64) {
+	x, y := c.Target.GetLevelCoords()
+	w, h := c.Target.Size()
+	return float64(x) + float64(w)/2, float64(y) + float64(h)/2
+}
+
+// clamp keeps the view inside of Bounds, centering it on any axis the bounds are smaller than the view
+func (c *Camera) clamp() {
+	if c.Bounds.W <= 0 || c.Bounds.H <= 0 {
+		return
+	}
+	viewW, viewH := c.ViewSize()
+	c.X = clampAxis(c.X, viewW, float64(c.Bounds.X), float64(c.Bounds.W))
+	c.Y = clampAxis(c.Y, viewH, float64(c.Bounds.Y), float64(c.Bounds.H))
+}
+
+// clampAxis keeps a view of size view starting at pos inside of a span of size size starting at start
+func clampAxis(pos, view, start, size float64) float64 {
+	if view >= size {
+		return start + (size-view)/2
+	}
+	return math.Max(start, math.Min(pos, start+size-view))
+}
+
+// follow returns where the middle of the view should be for the target to be inside the deadzone
+func follow(center, target, deadzone float64) float64 {
+	half := deadzone / 2
+	switch {
+	case target < center-half:
+		return target + half
+	case target > center+half:
+		return target - half
+	}
+	return center
+}
@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Collision is how a tile behaves when something moves into it
+type Collision uint8
+
+const (
+	// CollisionNone tiles can be walked through
+	CollisionNone Collision = iota
+	// CollisionSolid tiles block movement from every side
+	CollisionSolid
+	// CollisionOneWay tiles are platforms, they can only be landed on from above
+	CollisionOneWay
+	// CollisionSlopeUp tiles are a floor rising from the bottom left to the top right of the tile
+	CollisionSlopeUp
+	// CollisionSlopeDown tiles are a floor falling from the top left to the bottom right of the tile
+	CollisionSlopeDown
+	// CollisionTrigger tiles can be walked through, but are reported in the Contact of a move that ends on them
+	CollisionTrigger
+)
+
+var collisionNames = map[Collision]string{
+	CollisionNone:      "none",
+	CollisionSolid:     "solid",
+	CollisionOneWay:    "one_way",
+	CollisionSlopeUp:   "slope_up",
+	CollisionSlopeDown: "slope_down",
+	CollisionTrigger:   "trigger",
+}
+
+// String returns the collision's name, eg: "one_way"
+func (c Collision) String() string {
+	if name, ok := collisionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("collision(%d)", uint8(c))
+}
+
+// ParseCollision reads a collision in the format written by Collision.String
+func ParseCollision(s string) (Collision, error) {
+	for c, name := range collisionNames {
+		if name == s {
+			return c, nil
+		}
+	}
+	return CollisionNone, fmt.Errorf("engine: unknown collision %q", s)
+}
+
+// Collider resolves the movement of a box against something solid, like the tiles of a Level.
+//   It returns where the box ends up after moving by dx, dy from x, y, and what it ran into on the way.
+type Collider interface {
+	Resolve(x, y, w, h, dx, dy float64) (float64, float64, Contact)
+}
+
+// Contact describes what a move ran into
+type Contact struct {
+	// Left, Right, Up and Down are set when the move was stopped going that way
+	Left, Right, Up, Down bool
+	// Triggers are the trigger tiles the box overlaps where the move ended
+	Triggers []Trigger
+}
+
+// Trigger is a trigger tile that was touched, at tile coordinates X, Y
+type Trigger struct {
+	Tile Tile
+	X, Y int
+}
+
+// CollisionAt returns the topmost tile at tile coordinates x, y that collides, and how.
+//   Anything outside of the TileMap is solid, so nothing can leave the level.
+func (level *Level) CollisionAt(x, y int) (Tile, Collision) {
+	if level.TileMap == nil || !level.TileMap.InBounds(x, y) {
+		return Tile{}, CollisionSolid
+	}
+	for z := level.TileMap.Layers() - 1; z >= 0; z-- {
+		tile, ok := level.TileMap.Tileset.Tile(level.TileMap.Get(x, y, z))
+		if ok && tile.Collision != CollisionNone {
+			return tile, tile.Collision
+		}
+	}
+	return Tile{}, CollisionNone
+}
+
+// Resolve moves a box through the level's tiles, satisfying the Collider interface.
+//   The move is done one axis at a time so a box pushed into a wall slides along it.
+func (level *Level) Resolve(x, y, w, h, dx, dy float64) (float64, float64, Contact) {
+	var contact Contact
+	if level.TileMap == nil || level.TileSize <= 0 {
+		return x + dx, y + dy, contact
+	}
+	size := float64(level.TileSize)
+
+	x += dx
+	if dx != 0 {
+		level.eachTile(x, y, w, h, func(tileX, tileY int, tile Tile, c Collision) {
+			if c != CollisionSolid {
+				return
+			}
+			if dx > 0 {
+				x = math.Min(x, float64(tileX)*size-w)
+				contact.Right = true
+			} else {
+				x = math.Max(x, float64(tileX+1)*size)
+				contact.Left = true
+			}
+		})
+	}
+
+	bottom := y + h
+	y += dy
+	if dy != 0 {
+		level.eachTile(x, y, w, h, func(tileX, tileY int, tile Tile, c Collision) {
+			top := float64(tileY) * size
+			switch {
+			case c == CollisionSolid && dy > 0:
+				y = math.Min(y, top-h)
+				contact.Down = true
+			case c == CollisionSolid:
+				y = math.Max(y, top+size)
+				contact.Up = true
+			case c == CollisionOneWay && dy > 0 && bottom <= top:
+				// Platforms only catch boxes that were above them before the move
+				y = math.Min(y, top-h)
+				contact.Down = true
+			}
+		})
+	}
+
+	// Slopes are stood on at the middle of the box's feet, lift the box up onto them if it's sunk in
+	footX, footY := x+w/2, y+h
+	tileX, tileY := int(math.Floor(footX/size)), int(math.Floor(footY/size))
+	if _, c := level.CollisionAt(tileX, tileY); c == CollisionSlopeUp || c == CollisionSlopeDown {
+		local := footX - float64(tileX)*size
+		surface := float64(tileY)*size + local
+		if c == CollisionSlopeUp {
+			surface = float64(tileY+1)*size - local
+		}
+		if footY > surface {
+			y = surface - h
+			contact.Down = true
+		}
+	}
+
+	level.eachTile(x, y, w, h, func(tileX, tileY int, tile Tile, c Collision) {
+		if c == CollisionTrigger {
+			contact.Triggers = append(contact.Triggers, Trigger{Tile: tile, X: tileX, Y: tileY})
+		}
+	})
+	return x, y, contact
+}
+
+// eachTile calls fn with every tile a box overlaps. Touching the edge of a tile isn't overlapping it.
+func (level *Level) eachTile(x, y, w, h float64, fn func(tileX, tileY int, tile Tile, c Collision)) {
+	size := float64(level.TileSize)
+	firstX, firstY := int(math.Floor(x/size)), int(math.Floor(y/size))
+	lastX, lastY := int(math.Ceil((x+w)/size))-1, int(math.Ceil((y+h)/size))-1
+	for tileY := firstY; tileY <= lastY; tileY++ {
+		for tileX := firstX; tileX <= lastX; tileX++ {
+			tile, c := level.CollisionAt(tileX, tileY)
+			fn(tileX, tileY, tile, c)
+		}
+	}
+}
+
+// drawCollisions highlights the tiles in view that collide, for the debug overlay
+func (level *Level) drawCollisions(renderer Renderer, firstX, firstY, lastX, lastY int) {
+	camera := level.Camera
+	size := float64(level.TileSize)
+	for tileY := firstY; tileY <= lastY; tileY++ {
+		for tileX := firstX; tileX <= lastX; tileX++ {
+			if !level.TileMap.InBounds(tileX, tileY) {
+				continue
+			}
+			_, c := level.CollisionAt(tileX, tileY)
+			dst := camera.WorldRect(float64(tileX)*size, float64(tileY)*size, size, size)
+			x1, y1, x2, y2 := dst.X, dst.Y, dst.X+dst.W-1, dst.Y+dst.H-1
+			switch c {
+			case CollisionSolid:
+				renderer.Box(x1, y1, x2, y2, sdl.Color{R: 255, G: 0, B: 0, A: 80})
+			case CollisionOneWay:
+				renderer.Box(x1, y1, x2, y1+2, sdl.Color{R: 255, G: 255, B: 0, A: 160})
+			case CollisionSlopeUp:
+				renderer.Line(x1, y2, x2, y1, sdl.Color{R: 0, G: 255, B: 0, A: 160})
+			case CollisionSlopeDown:
+				renderer.Line(x1, y1, x2, y2, sdl.Color{R: 0, G: 255, B: 0, A: 160})
+			case CollisionTrigger:
+				renderer.Box(x1, y1, x2, y2, sdl.Color{R: 0, G: 0, B: 255, A: 80})
+			}
+		}
+	}
+}
+
+// MoveBox resolves a move through the level's tiles, reporting whether it was stopped on either axis.
+//   This lets a level be the physics.Terrain of a physics world.
+func (level *Level) MoveBox(x, y, w, h, dx, dy float64) (float64, float64, bool, bool) {
+	x, y, contact := level.Resolve(x, y, w, h, dx, dy)
+	return x, y, contact.Left || contact.Right, contact.Up || contact.Down
+}
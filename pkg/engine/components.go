@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Transform is where an entity is in the world
+type Transform struct {
+	// Angle rotates the entity clockwise, in degrees
+	Angle float64
+	// ScaleX and ScaleY stretch the entity's sprite when it's drawn
+	ScaleX, ScaleY float64
+	// X and Y are the world coordinates of the top left of the entity
+	X, Y float64
+}
+
+// Sprite is the part of a texture an entity is drawn with
+type Sprite struct {
+	Flip sdl.RendererFlip
+	// Src is the part of Texture to draw, the whole texture is drawn when it's empty
+	Src     sdl.Rect
+	Texture Texture
+	// W and H are the size the sprite is drawn at before it's scaled by the Transform
+	W, H int32
+}
+
+// Animation plays clips from a sprite sheet on an entity's Sprite
+type Animation struct {
+	*Animator
+}
+
+// Velocity is how fast an entity is moving, in pixels per second
+type Velocity struct {
+	X, Y float64
+}
+
+// BoxCollider is the box an entity collides with, offset from its Transform.
+//   It's stored as a ColliderType component, and moved through a Collider like a Level by MovementSystem
+type BoxCollider struct {
+	OffsetX, OffsetY float64
+	W, H             float64
+	// Contact is what the entity ran into on its last move
+	Contact Contact
+}
+
+// Health is how much damage an entity can take
+type Health struct {
+	Current, Max float64
+}
+
+// Damage takes amount from the entity's health, not going below 0
+func (h *Health) Damage(amount float64) {
+	h.Current = math.Max(h.Current-amount, 0)
+}
+
+// Heal adds amount to the entity's health, not going above Max
+func (h *Health) Heal(amount float64) {
+	h.Current = math.Min(h.Current+amount, h.Max)
+}
+
+// Dead reports whether the entity has run out of health
+func (h *Health) Dead() bool {
+	return h.Current <= 0
+}
+
+// Transform returns an entity's Transform, or nil if it doesn't have one
+func (w *World) Transform(id EntityID) *Transform {
+	c, _ := w.Get(id, TransformType).(*Transform)
+	return c
+}
+
+// Sprite returns an entity's Sprite, or nil if it doesn't have one
+func (w *World) Sprite(id EntityID) *Sprite {
+	c, _ := w.Get(id, SpriteType).(*Sprite)
+	return c
+}
+
+// Animation returns an entity's Animation, or nil if it doesn't have one
+func (w *World) Animation(id EntityID) *Animation {
+	c, _ := w.Get(id, AnimationType).(*Animation)
+	return c
+}
+
+// Velocity returns an entity's Velocity, or nil if it doesn't have one
+func (w *World) Velocity(id EntityID) *Velocity {
+	c, _ := w.Get(id, VelocityType).(*Velocity)
+	return c
+}
+
+// BoxCollider returns an entity's BoxCollider, or nil if it doesn't have one
+func (w *World) BoxCollider(id EntityID) *BoxCollider {
+	c, _ := w.Get(id, ColliderType).(*BoxCollider)
+	return c
+}
+
+// Health returns an entity's Health, or nil if it doesn't have one
+func (w *World) Health(id EntityID) *Health {
+	c, _ := w.Get(id, HealthType).(*Health)
+	return c
+}
+
+// MovementSystem moves entities with a Transform by their Velocity.
+//   Entities with a BoxCollider are moved through Level, when it's set, sliding along walls and stopping on floors.
+type MovementSystem struct {
+	Level Collider
+}
+
+// Update moves every entity with a Transform and Velocity
+func (m MovementSystem) Update(world *World, dt float64) {
+	for _, id := range world.Query(TransformType, VelocityType) {
+		t, v := world.Transform(id), world.Velocity(id)
+		c := world.BoxCollider(id)
+		if m.Level == nil || c == nil {
+			t.X += v.X * dt
+			t.Y += v.Y * dt
+			continue
+		}
+		x, y, contact := m.Level.Resolve(t.X+c.OffsetX, t.Y+c.OffsetY, c.W, c.H, v.X*dt, v.Y*dt)
+		t.X, t.Y = x-c.OffsetX, y-c.OffsetY
+		c.Contact = contact
+		// Stop moving into whatever was hit
+		if contact.Left && v.X < 0 || contact.Right && v.X > 0 {
+			v.X = 0
+		}
+		if contact.Up && v.Y < 0 || contact.Down && v.Y > 0 {
+			v.Y = 0
+		}
+	}
+}
+
+// AnimationSystem advances Animations and points their Sprite at the frame being shown
+type AnimationSystem struct{}
+
+// Update advances every entity with an Animation and Sprite
+func (AnimationSystem) Update(world *World, dt float64) {
+	for _, id := range world.Query(AnimationType, SpriteType) {
+		a, s := world.Animation(id), world.Sprite(id)
+		if a.Animator == nil {
+			continue
+		}
+		a.Update(dt)
+		s.Texture = a.Sheet.Texture
+		s.Src = a.Rect()
+	}
+}
+
+// SpriteSystem draws every entity with a Transform and Sprite that's in view of the camera
+type SpriteSystem struct {
+	// Queue, when set, has sprites submitted to it on LayerEntities sorted by the bottom of their sprite,
+	//   to be drawn when it's flushed along with everything else
+	Queue *RenderQueue
+}
+
+// Update satisfies the System interface, sprites are only drawn
+func (SpriteSystem) Update(world *World, dt float64) {}
+
+// Draw draws the sprites in view, in the order their entities got their sprites unless they're queued
+func (system SpriteSystem) Draw(world *World, renderer Renderer, camera *Camera) {
+	for _, id := range world.Query(SpriteType, TransformType) {
+		cmd, ok := spriteCommand(world, id, camera)
+		if !ok {
+			continue
+		}
+		if system.Queue != nil {
+			system.Queue.Submit(cmd)
+		} else {
+			copyCommand(renderer, cmd)
+		}
+	}
+}
+
+// drawSprite draws an entity's sprite where its transform puts it, if it's in view of the camera
+func drawSprite(world *World, id EntityID, renderer Renderer, camera *Camera) {
+	if cmd, ok := spriteCommand(world, id, camera); ok {
+		copyCommand(renderer, cmd)
+	}
+}
+
+// spriteCommand returns a command drawing an entity's sprite where its transform puts it, false if it's out of view of the camera
+func spriteCommand(world *World, id EntityID, camera *Camera) (DrawCommand, bool) {
+	s, t := world.Sprite(id), world.Transform(id)
+	if s == nil || t == nil || s.Texture == nil {
+		return DrawCommand{}, false
+	}
+	scaleX, scaleY := t.ScaleX, t.ScaleY
+	if scaleX == 0 {
+		scaleX = 1
+	}
+	if scaleY == 0 {
+		scaleY = 1
+	}
+	w, h := float64(s.W)*scaleX, float64(s.H)*scaleY
+	if !camera.InView(t.X, t.Y, w, h) {
+		return DrawCommand{}, false
+	}
+	return DrawCommand{
+		Angle:   t.Angle,
+		Dst:     *camera.WorldRect(t.X, t.Y, w, h),
+		Flip:    s.Flip,
+		Layer:   LayerEntities,
+		SortKey: t.Y + h,
+		Src:     s.Src,
+		Texture: s.Texture,
+	}, true
+}
@@ -0,0 +1,344 @@
+package engine
+
+import "fmt"
+
+// EntityID identifies an entity in a World. 0 is never a valid id.
+//   The low bits are the entity's slot and the high bits its generation, which goes up each time a
+//   despawned entity's slot is reused, so ids held on to after a despawn never find the new entity.
+type EntityID uint32
+
+const (
+	// entityIndexBits is how many bits of an EntityID are its slot, the rest are its generation
+	entityIndexBits = 20
+	entityIndexMask = 1<<entityIndexBits - 1
+	// MaxEntities is how many entities a World can have alive at once
+	MaxEntities = entityIndexMask
+)
+
+// index returns the slot an entity is in
+func (id EntityID) index() uint32 {
+	return uint32(id) & entityIndexMask
+}
+
+// generation returns how many times the entity's slot had been reused when it was spawned, wrapping around
+func (id EntityID) generation() uint32 {
+	return uint32(id) >> entityIndexBits
+}
+
+// ComponentType identifies a kind of component. The built in components have their own types,
+//   games register types for their own components with RegisterComponent
+type ComponentType int
+
+// The built in component types
+const (
+	TransformType ComponentType = iota
+	SpriteType
+	AnimationType
+	VelocityType
+	ColliderType
+	HealthType
+	// LegacyType holds an Entity adopted into a World with Adopt
+	LegacyType
+)
+
+var componentNames = []string{"transform", "sprite", "animation", "velocity", "collider", "health", "legacy"}
+
+// RegisterComponent creates a new component type for a game's own components
+func RegisterComponent(name string) ComponentType {
+	componentNames = append(componentNames, name)
+	return ComponentType(len(componentNames) - 1)
+}
+
+// String returns the name the component type was registered with
+func (t ComponentType) String() string {
+	if t >= 0 && int(t) < len(componentNames) {
+		return componentNames[t]
+	}
+	return fmt.Sprintf("component(%d)", int(t))
+}
+
+// System updates the entities in a World that have the components it cares about
+type System interface {
+	Update(world *World, dt float64)
+}
+
+// DrawSystem is a System that also draws, World.Draw calls them in the order they were added
+type DrawSystem interface {
+	System
+	Draw(world *World, renderer Renderer, camera *Camera)
+}
+
+// World holds entities as ids and the components attached to them, and runs systems over them
+type World struct {
+	next    EntityID
+	free    []EntityID
+	alive   map[EntityID]bool
+	stores  []*componentStore
+	systems []System
+	views   map[EntityID]*worldEntity
+}
+
+// componentStore holds every component of one type, packed together so systems iterate them in order.
+//   index maps an entity to its place in the packed slices.
+type componentStore struct {
+	ids        []EntityID
+	components []interface{}
+	index      map[EntityID]int
+}
+
+// NewWorld creates an empty world
+func NewWorld() *World {
+	return &World{
+		alive: map[EntityID]bool{},
+		views: map[EntityID]*worldEntity{},
+	}
+}
+
+// Spawn creates an entity with no components. The slots of despawned entities are reused with a new generation.
+//   Spawn panics if MaxEntities are already alive.
+func (w *World) Spawn() EntityID {
+	var id EntityID
+	if n := len(w.free); n > 0 {
+		freed := w.free[n-1]
+		w.free = w.free[:n-1]
+		id = EntityID((freed.generation()+1)<<entityIndexBits | freed.index())
+	} else {
+		if w.next == MaxEntities {
+			panic(fmt.Sprintf("engine: a world can't have more than %d entities", MaxEntities))
+		}
+		w.next++
+		id = w.next
+	}
+	w.alive[id] = true
+	return id
+}
+
+// Despawn removes an entity and all of its components, ids from an earlier generation of its slot are ignored
+func (w *World) Despawn(id EntityID) {
+	if !w.alive[id] {
+		return
+	}
+	for t := range w.stores {
+		w.Remove(id, ComponentType(t))
+	}
+	delete(w.alive, id)
+	delete(w.views, id)
+	w.free = append(w.free, id)
+}
+
+// Alive reports whether an entity exists, which it doesn't once its slot has been reused by a later generation
+func (w *World) Alive(id EntityID) bool {
+	return w.alive[id]
+}
+
+// Add attaches a component to an entity, replacing any component of the same type.
+//   Components should be pointers, eg: &Transform{}, so systems can change them in place.
+func (w *World) Add(id EntityID, t ComponentType, component interface{}) {
+	if !w.alive[id] {
+		return
+	}
+	s := w.store(t)
+	if i, ok := s.index[id]; ok {
+		s.components[i] = component
+		return
+	}
+	s.index[id] = len(s.ids)
+	s.ids = append(s.ids, id)
+	s.components = append(s.components, component)
+}
+
+// Remove detaches a component from an entity
+func (w *World) Remove(id EntityID, t ComponentType) {
+	if int(t) >= len(w.stores) {
+		return
+	}
+	s := w.stores[t]
+	i, ok := s.index[id]
+	if !ok {
+		return
+	}
+	// Swap the last component into the gap to keep the store packed
+	last := len(s.ids) - 1
+	s.ids[i], s.components[i] = s.ids[last], s.components[last]
+	s.index[s.ids[i]] = i
+	s.ids, s.components = s.ids[:last], s.components[:last]
+	delete(s.index, id)
+}
+
+// Get returns an entity's component of a type, or nil if it doesn't have one or isn't alive
+func (w *World) Get(id EntityID, t ComponentType) interface{} {
+	if int(t) >= len(w.stores) || !w.alive[id] {
+		return nil
+	}
+	s := w.stores[t]
+	if i, ok := s.index[id]; ok {
+		return s.components[i]
+	}
+	return nil
+}
+
+// Has reports whether an entity has a component of every one of the given types
+func (w *World) Has(id EntityID, types ...ComponentType) bool {
+	for _, t := range types {
+		if int(t) >= len(w.stores) {
+			return false
+		}
+		if _, ok := w.stores[t].index[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns the entities that have a component of every one of the given types.
+//   The smallest store is walked and the rest are checked against it, so rare components make for fast queries.
+func (w *World) Query(types ...ComponentType) []EntityID {
+	if len(types) == 0 {
+		return nil
+	}
+	var smallest *componentStore
+	for _, t := range types {
+		if int(t) >= len(w.stores) {
+			return nil
+		}
+		if s := w.stores[t]; smallest == nil || len(s.ids) < len(smallest.ids) {
+			smallest = s
+		}
+	}
+	var ids []EntityID
+	for _, id := range smallest.ids {
+		if w.Has(id, types...) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// AddSystem adds systems to the world, they're updated in the order they're added
+func (w *World) AddSystem(systems ...System) {
+	w.systems = append(w.systems, systems...)
+}
+
+// Update runs every system
+func (w *World) Update(dt float64) {
+	for _, s := range w.systems {
+		s.Update(w, dt)
+	}
+}
+
+// Draw runs every system that draws
+func (w *World) Draw(renderer Renderer, camera *Camera) {
+	for _, s := range w.systems {
+		if d, ok := s.(DrawSystem); ok {
+			d.Draw(w, renderer, camera)
+		}
+	}
+}
+
+// store returns the store for a component type, creating it if it's the first of its type
+func (w *World) store(t ComponentType) *componentStore {
+	for int(t) >= len(w.stores) {
+		w.stores = append(w.stores, &componentStore{index: map[EntityID]int{}})
+	}
+	return w.stores[t]
+}
+
+// Adopt brings an existing Entity into the world so it can be migrated a piece at a time.
+//   It's given a Transform kept in sync with where the entity is, and LegacySystem updates and draws it.
+func (w *World) Adopt(entity Entity) EntityID {
+	id := w.Spawn()
+	x, y := entity.GetLevelCoords()
+	w.Add(id, TransformType, &Transform{X: float64(x), Y: float64(y), ScaleX: 1, ScaleY: 1})
+	w.Add(id, LegacyType, entity)
+	return id
+}
+
+// Entity wraps an entity in the world so it satisfies the Entity interface,
+//   letting it go anywhere an Entity does, like a Level's Entities. The same id always gets the same Entity
+func (w *World) Entity(id EntityID) Entity {
+	if legacy, ok := w.Get(id, LegacyType).(Entity); ok {
+		return legacy
+	}
+	view, ok := w.views[id]
+	if !ok {
+		view = &worldEntity{id: id, world: w}
+		w.views[id] = view
+	}
+	return view
+}
+
+// LegacySystem updates and draws entities adopted with Adopt, keeping their Transform in sync.
+//   Add it to a world once for all adopted entities.
+type LegacySystem struct{}
+
+// Update calls Update on each adopted entity, then copies where it is to its Transform
+func (LegacySystem) Update(world *World, dt float64) {
+	for _, id := range world.Query(LegacyType, TransformType) {
+		entity := world.Get(id, LegacyType).(Entity)
+		entity.Update(dt)
+		x, y := entity.GetLevelCoords()
+		transform := world.Transform(id)
+		transform.X, transform.Y = float64(x), float64(y)
+	}
+}
+
+// Draw draws each adopted entity
+func (LegacySystem) Draw(world *World, renderer Renderer, camera *Camera) {
+	for _, id := range world.Query(LegacyType) {
+		world.Get(id, LegacyType).(Entity).Draw(renderer, camera)
+	}
+}
+
+// worldEntity satisfies the Entity interface for an entity made of components
+type worldEntity struct {
+	id    EntityID
+	world *World
+}
+
+// Draw draws the entity's sprite, if it has one
+func (e *worldEntity) Draw(renderer Renderer, camera *Camera) {
+	drawSprite(e.world, e.id, renderer, camera)
+}
+
+// Submit queues the entity's sprite to be drawn, if it has one
+func (e *worldEntity) Submit(queue *RenderQueue, camera *Camera) {
+	if cmd, ok := spriteCommand(e.world, e.id, camera); ok {
+		queue.Submit(cmd)
+	}
+}
+
+// GetLevelCoords returns where the entity's Transform puts it
+func (e *worldEntity) GetLevelCoords() (int, int) {
+	if t := e.world.Transform(e.id); t != nil {
+		return int(t.X), int(t.Y)
+	}
+	return 0, 0
+}
+
+// SetX moves the entity's Transform
+func (e *worldEntity) SetX(x float64) {
+	if t := e.world.Transform(e.id); t != nil {
+		t.X = x
+	}
+}
+
+// SetY moves the entity's Transform
+func (e *worldEntity) SetY(y float64) {
+	if t := e.world.Transform(e.id); t != nil {
+		t.Y = y
+	}
+}
+
+// Size returns the size of the entity's BoxCollider, or its Sprite if it has no BoxCollider
+func (e *worldEntity) Size() (int32, int32) {
+	if c := e.world.BoxCollider(e.id); c != nil {
+		return int32(c.W), int32(c.H)
+	}
+	if s := e.world.Sprite(e.id); s != nil {
+		return s.W, s.H
+	}
+	return 0, 0
+}
+
+// Update does nothing, the world's systems update the entity
+func (e *worldEntity) Update(float64) {}
@@ -0,0 +1,49 @@
+package engine
+
+import "testing"
+
+func TestWorldStaleID(t *testing.T) {
+	w := NewWorld()
+	old := w.Spawn()
+	w.Add(old, HealthType, &Health{})
+	w.Despawn(old)
+
+	id := w.Spawn()
+	if id.index() != old.index() {
+		t.Fatalf("spawned into slot %d, want the freed slot %d", id.index(), old.index())
+	}
+	if id == old {
+		t.Fatalf("reused id %d without a new generation", id)
+	}
+	health := &Health{}
+	w.Add(id, HealthType, health)
+
+	if w.Alive(old) {
+		t.Error("the despawned id is alive")
+	}
+	if got := w.Get(old, HealthType); got != nil {
+		t.Errorf("the despawned id got %v", got)
+	}
+	if w.Has(old, HealthType) {
+		t.Error("the despawned id has a health component")
+	}
+	// Adding to or despawning the old id leaves the new entity alone
+	w.Add(old, HealthType, &Health{})
+	w.Despawn(old)
+	if !w.Alive(id) || w.Get(id, HealthType) != health {
+		t.Error("the old id changed the entity that reused its slot")
+	}
+}
+
+func TestWorldGenerations(t *testing.T) {
+	w := NewWorld()
+	seen := map[EntityID]bool{}
+	for i := 0; i < 100; i++ {
+		id := w.Spawn()
+		if id == 0 || seen[id] {
+			t.Fatalf("spawn %d returned id %d again", i, id)
+		}
+		seen[id] = true
+		w.Despawn(id)
+	}
+}
@@ -9,91 +9,108 @@ import (
 
 // Enemy holds all things necessary for the Enemy to make their moves
 type Enemy struct {
-	// Frame tracks what Frame of the enemy animation we're on
-	Frame      int32
-	FrameLimit int32
+	// Anim plays the enemy's idle clip
+	Anim *Animator
+	// Collider stops the enemy moving through walls, usually the level they're in. nil lets them move anywhere
+	Collider Collider
+	// Contact is what the enemy ran into on their last move
+	Contact Contact
 	// Enemy health out of 1.0 representing 100%
 	Health float64
-	//LevelX, LevelY are used to track where on the level map the enemy is
-	LevelX, LevelY int
 	// This primitive logger exists so we can print out any helpful debug information
 	Log string
 	// Name tracks enemy objects
 	Name string
-	// size x and y pertain to what the standard size of the enemy is
+	// SizeX and SizeY are the size the enemy is drawn at and collides as
 	SizeX, SizeY int32
-	// SpriteXPos and SpriteYPos is a Frame reference eg: [0, 1, 2, 3] for 4 Frames of animation
-	// This is used to coordinate where in it's bitmap file it is
-	SpriteXPos, SpriteYPos int32
-	//the texture object for the enemy
-	Texture *sdl.Texture
-	// The x and y coordiates for the enemy on a tileMap
+	// The x and y world coordiates the enemy is drawn at
 	X, Y float64
 }
 
 var debug = os.Getenv("HMDEBUG") == ""
 
-// NewEnemy constructs a basic terminal object
-func NewEnemy(name string, renderer *sdl.Renderer) (*Enemy, error) {
-	img, err := sdl.LoadBMP("sprites/terminal.bmp")
-	if err != nil {
-		return &Enemy{}, err
-	}
+// EnemySprite is the Aseprite sprite sheet enemies are drawn with, it needs an idle clip
+var EnemySprite = "assets/sprites/terminal.json"
 
-	texture, err := renderer.CreateTextureFromSurface(img)
+// NewEnemy constructs a basic terminal object, loading EnemySprite with textures
+func NewEnemy(name string, textures TextureLoader) (*Enemy, error) {
+	anim, err := LoadAseprite(EnemySprite, textures)
 	if err != nil {
 		return &Enemy{}, err
 	}
-	return &Enemy{
-		FrameLimit: 1,
-		Health:     1.0,
-		Name:       name,
-		SizeX:      32,
-		SizeY:      32,
-		Texture:    texture,
-	}, nil
+	enemy := &Enemy{
+		Anim:   anim,
+		Health: 1.0,
+		Name:   name,
+		SizeX:  32,
+		SizeY:  32,
+	}
+	enemy.Anim.Play("idle")
+	return enemy, nil
+}
+
+func init() {
+	RegisterEntity("enemy", func(spawn Spawn, textures TextureLoader) (Entity, error) {
+		enemy, err := NewEnemy(spawn.Name, textures)
+		if err != nil {
+			return nil, err
+		}
+		enemy.SetCoords(float64(spawn.X), float64(spawn.Y))
+		return enemy, nil
+	})
 }
 
 // GetLevelCoords returns the X and Y coordinates on the Level where
 //    the enemy is supposed to be.
 func (enemy *Enemy) GetLevelCoords() (x int, y int) {
-	return enemy.LevelX, enemy.LevelY
+	return int(enemy.X), int(enemy.Y)
 }
 
-// SetCoords helps when rendering the entity map to set where to Draw the enemy on the screen
-// You might also think of this as setting the enemy coordinates relative to the camera overlooking our tileMap array
+// Spawn describes the enemy so it can be saved with a level
+func (enemy *Enemy) Spawn() Spawn {
+	return Spawn{
+		Type: "enemy",
+		Name: enemy.Name,
+		X:    int(enemy.X),
+		Y:    int(enemy.Y),
+	}
+}
+
+// SetCoords sets where in the world the enemy is drawn
 func (enemy *Enemy) SetCoords(x float64, y float64) {
 	enemy.X = x
 	enemy.Y = y
 }
 
-// Draw renders the enemy to the screen
-func (enemy *Enemy) Draw(renderer *sdl.Renderer, levelX int, levelY int) {
-	renderX := enemy.X - float64(levelX)
-	renderY := enemy.Y - float64(levelY)
+// Draw renders the enemy to the screen, as seen through camera
+func (enemy *Enemy) Draw(renderer Renderer, camera *Camera) {
+	dst := camera.WorldRect(enemy.X, enemy.Y, float64(enemy.SizeX), float64(enemy.SizeY))
 	if debug {
-		fmt.Printf("drawing enemy %s to %v,%v\n", enemy.Name, renderX, renderY)
+		fmt.Printf("drawing enemy %s to %v,%v\n", enemy.Name, dst.X, dst.Y)
 	}
 
-	renderer.Copy(
-		enemy.Texture,
-		&sdl.Rect{X: enemy.SpriteXPos * enemy.SizeX, Y: enemy.SpriteYPos * enemy.SizeY, W: 32, H: 32},
-		&sdl.Rect{X: int32(renderX), Y: int32(renderY), W: 32, H: 32},
-	)
+	enemy.Anim.Draw(renderer, dst, 0, sdl.FLIP_NONE)
 }
 
-// Update advances the enemy animation and updates x/y coords for the enemy
-func (enemy *Enemy) Update(levelX int, levelY int) {
-	enemy.Frame++
-	// If we've iterated past our number of Frames, reset to 0
-	if enemy.Frame > enemy.FrameLimit {
-		enemy.Frame = 0
-	}
+// Submit queues the enemy to be drawn, sorted by the bottom of its sprite
+func (enemy *Enemy) Submit(queue *RenderQueue, camera *Camera) {
+	dst := camera.WorldRect(enemy.X, enemy.Y, float64(enemy.SizeX), float64(enemy.SizeY))
+	enemy.Anim.Submit(queue, LayerEntities, enemy.Y+float64(enemy.SizeY), *dst, 0, sdl.FLIP_NONE)
+}
+
+// Update advances the enemy animation by dt seconds
+func (enemy *Enemy) Update(dt float64) {
+	enemy.Anim.Update(dt)
+}
 
-	enemy.SpriteXPos++
-	if enemy.SpriteXPos > enemy.FrameLimit {
-		enemy.SpriteXPos = 0
+// Move moves the enemy dx, dy pixels through the world, sliding along anything its Collider says is in the way
+func (enemy *Enemy) Move(dx float64, dy float64) {
+	if enemy.Collider == nil {
+		enemy.X += dx
+		enemy.Y += dy
+		return
 	}
+	enemy.X, enemy.Y, enemy.Contact = enemy.Collider.Resolve(enemy.X, enemy.Y, float64(enemy.SizeX), float64(enemy.SizeY), dx, dy)
 }
 
 // SetX sets the enemy X coordinate
@@ -2,8 +2,8 @@ package engine
 
 import (
 	"fmt"
-	"io/ioutil"
 
+	"github.com/ryanhartje/gogome/pkg/input"
 	"github.com/veandco/go-sdl2/mix"
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/ttf"
@@ -16,14 +16,42 @@ var (
 
 // Engine holds all of the assets necessary to run a 2D engine
 type Engine struct {
-	Fonts    []*ttf.Font
-	Entities *sdl.Surface
-	Renderer *sdl.Renderer
+	// Assets loads and shares the game's textures, fonts, sounds and maps. It loads textures with Renderer
+	Assets *Assets
+	// Controllers tracks game controllers as they're plugged in, Run keeps it up to date
+	Controllers *input.Controllers
+	Entities    *sdl.Surface
+	Fonts       []*ttf.Font
+	// Input is sampled once per tick by Run, before the game is updated
+	Input *input.Map
+	// MaxFrameSkip caps how many updates Run will do in a row before drawing a frame
+	MaxFrameSkip int
+	Renderer     Renderer
+	// Scenes is the engine's scene stack, run it with e.Run(e.Scenes)
+	Scenes *SceneStack
+	// TickRate is how many times per second Run calls Update
+	TickRate int
+	// Ticks counts how many updates Run has done since the engine was created
+	Ticks uint64
+	// Window is the window Renderer draws to, if the engine created it
+	Window *sdl.Window
+
+	recorder *recorder
+	replay   *Replay
+	running  bool
 }
 
 // NewEngine creates and instanciates our engine
 func NewEngine() *Engine {
-	e := &Engine{}
+	e := &Engine{
+		Controllers:  input.DefaultControllers,
+		Input:        input.Default,
+		MaxFrameSkip: DefaultMaxFrameSkip,
+		TickRate:     DefaultTickRate,
+	}
+	e.Assets = NewAssets(nil)
+	e.Assets.engine = e
+	e.Scenes = NewSceneStack(e)
 	return e
 }
 
@@ -43,7 +71,19 @@ func (e *Engine) Init() {
 
 // Quit cleans up the engine's resources
 func (e *Engine) Quit() {
-	e.Renderer.Destroy()
+	if e.Controllers != nil {
+		e.Controllers.Close()
+	}
+	// Assets have to go before the renderer their textures belong to
+	if e.Assets != nil {
+		e.Assets.Destroy()
+	}
+	if e.Renderer != nil {
+		e.Renderer.Destroy()
+	}
+	if e.Window != nil {
+		e.Window.Destroy()
+	}
 	sdl.Quit()
 }
 
@@ -54,15 +94,9 @@ func AddFont(fontPaths ...string) {
 	}
 }
 
-// QueueWAV uses the initialized mixer and plays a WAV on the first channel
+// QueueWAV uses the initialized mixer to load a WAV from FS, ready to be played
 func QueueWAV(filepath string) (*mix.Chunk, error) {
-	// Load in BG wav
-	data, err := ioutil.ReadFile("sfx/streets.wav")
-	if err != nil {
-		return &mix.Chunk{}, err
-	}
-
-	chunk, err := mix.QuickLoadWAV(data)
+	chunk, err := LoadSound(filepath)
 	if err != nil {
 		return &mix.Chunk{}, err
 	}
@@ -1,11 +1,9 @@
 package engine
 
-import "github.com/veandco/go-sdl2/sdl"
-
 // Entity is an interface that aides the engine in having commmon functionality
 type Entity interface {
-	// Draw is given the renderer, and an X,Y coordinate to draw the entity to the screen
-	Draw(*sdl.Renderer, int, int)
+	// Draw is given the renderer, and the camera to convert the entity's world coordinates to the screen with
+	Draw(Renderer, *Camera)
 	// GetLevelCoords returns the X,Y coordinate pair for the entity as it relates to the level map
 	GetLevelCoords() (int, int)
 	// SetX,SetY take a precise pixel coordinate pair to set the entity's sprite drawing to
@@ -13,6 +11,23 @@ type Entity interface {
 	SetY(float64)
 	// Size returns the number of pixels wide and high the sprite/hitbox should be
 	Size() (int32, int32)
-	// Update takes a level X,Y coordinate pair so it can update itself relative to the camera
-	Update(int, int)
+	// Update takes how many seconds the tick it's called from covers, so animations and movement keep time
+	Update(float64)
+}
+
+// Submitter is implemented by entities that queue themselves to be drawn on a RenderQueue, instead of drawing right away
+type Submitter interface {
+	Submit(queue *RenderQueue, camera *Camera)
+}
+
+// SubmitEntity queues an entity to be drawn on LayerEntities, sorted by the bottom of its sprite so entities lower
+//   down the screen are drawn in front. Entities that aren't Submitters are drawn with Draw when their turn comes.
+func SubmitEntity(queue *RenderQueue, entity Entity, camera *Camera) {
+	if submitter, ok := entity.(Submitter); ok {
+		submitter.Submit(queue, camera)
+		return
+	}
+	_, y := entity.GetLevelCoords()
+	_, h := entity.Size()
+	queue.SubmitFunc(LayerEntities, float64(y)+float64(h), func(renderer Renderer) { entity.Draw(renderer, camera) })
 }
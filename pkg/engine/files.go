@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"io/fs"
+	"runtime"
+
+	"github.com/ryanhartje/gogome/pkg/vfs"
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// FS is the filesystem every file the engine loads is read from, textures, sprite sheets, maps, fonts and sounds alike.
+//   It's the working directory to begin with. Set it to a vfs.FS to load from embedded files and archives,
+//   eg: mount the game's embedded assets, then the directory next to the executable over them.
+var FS fs.FS = vfs.OS()
+
+// fontData holds on to the files fonts opened with OpenFont are read from, SDL_ttf reads them as it renders
+var fontData = map[*ttf.Font][]byte{}
+
+// ReadFile reads a whole file from FS
+func ReadFile(path string) ([]byte, error) {
+	return fs.ReadFile(FS, vfs.Clean(path))
+}
+
+// StatFile returns the file info of a file in FS
+func StatFile(path string) (fs.FileInfo, error) {
+	return fs.Stat(FS, vfs.Clean(path))
+}
+
+// OpenRW reads a file from FS into RWops, so SDL_image, SDL_mixer and SDL_ttf can load it from any mount.
+//   The RWops reads from data, which has to be kept alive for as long as the RWops is in use.
+func OpenRW(path string) (rw *sdl.RWops, data []byte, err error) {
+	data, err = ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	// SDL won't take an empty buffer, but an empty file is still a file
+	if len(data) == 0 {
+		data = make([]byte, 0, 1)
+	}
+	rw, err = sdl.RWFromMem(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rw, data, nil
+}
+
+// LoadSurface loads an image from FS, in any format SDL_image supports
+func LoadSurface(path string) (*sdl.Surface, error) {
+	rw, data, err := OpenRW(path)
+	if err != nil {
+		return nil, err
+	}
+	surface, err := img.LoadRW(rw, true)
+	runtime.KeepAlive(data)
+	return surface, err
+}
+
+// LoadSound loads a WAV, OGG or any other format SDL_mixer supports from FS. The mixer must be open
+func LoadSound(path string) (*mix.Chunk, error) {
+	rw, data, err := OpenRW(path)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err := mix.LoadWAVRW(rw, true)
+	runtime.KeepAlive(data)
+	return chunk, err
+}
+
+// OpenFont opens a TrueType font from FS at a point size. Close it with CloseFont
+func OpenFont(path string, size int) (*ttf.Font, error) {
+	rw, data, err := OpenRW(path)
+	if err != nil {
+		return nil, err
+	}
+	font, err := ttf.OpenFontRW(rw, 1, size)
+	if err != nil {
+		return nil, err
+	}
+	fontData[font] = data
+	return font, nil
+}
+
+// CloseFont closes a font opened with OpenFont, destroying its glyph atlases and letting go of the file it was read from
+func CloseFont(font *ttf.Font) {
+	FreeGlyphs(font)
+	font.Close()
+	delete(fontData, font)
+}
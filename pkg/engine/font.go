@@ -0,0 +1,298 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Font is a typeface text is drawn in. TrueType fonts are drawn from their GlyphAtlas, see Glyphs,
+//   and pixel fonts drawn on sprite sheets with BitmapFont.
+type Font interface {
+	// Ascent is how far above the baseline the top of a line is, Descent how far below it the bottom is
+	Ascent() int32
+	Descent() int32
+	// Glyph returns how a character is drawn
+	Glyph(r rune) (Glyph, error)
+	// Kerning is how much closer or further apart b is drawn after a than their advances put them
+	Kerning(a, b rune) int32
+	// LineSkip is how far apart the tops of lines are
+	LineSkip() int32
+	// Texture returns the texture a page of glyphs is drawn from on renderer
+	Texture(renderer Renderer, page int) (Texture, error)
+}
+
+// BitmapFont is a font cut out of images, eg: a pixel font drawn on a sprite sheet. Glyphs are drawn as they are
+//   on the sheet, tinted by the text's color, so white glyphs can be drawn in any color.
+//   Load one from a grid of characters with LoadGridFont, or from an AngelCode BMFont file with LoadBMFont.
+type BitmapFont struct {
+	// Base is how far below the top of a line the baseline is
+	Base   int32
+	Glyphs map[rune]Glyph
+	// Kernings are how much closer or further apart the second of a pair is drawn after the first
+	Kernings map[[2]rune]int32
+	// LineHeight is how far apart the tops of lines are
+	LineHeight int32
+	// Pages are the textures glyphs are cut from, they belong to whatever loaded them
+	Pages []Texture
+}
+
+// NewGridFont cuts a font out of a texture divided into cellW by cellH cells, one character to each.
+//   chars lists the characters on the texture from the top left, a row at a time. A newline in it moves on
+//   to the next row, and a space skips a cell. Every character is drawn a cell apart, with its baseline at the bottom.
+func NewGridFont(texture Texture, cellW, cellH int32, chars string) (*BitmapFont, error) {
+	if cellW <= 0 || cellH <= 0 {
+		return nil, fmt.Errorf("engine: font cells must be bigger than %dx%d", cellW, cellH)
+	}
+	w, h := texture.Size()
+	columns := w / cellW
+	font := &BitmapFont{
+		Base:       cellH,
+		Glyphs:     map[rune]Glyph{' ': {Advance: cellW}},
+		Kernings:   map[[2]rune]int32{},
+		LineHeight: cellH,
+		Pages:      []Texture{texture},
+	}
+	var column, row int32
+	for _, r := range chars {
+		if r == '\n' {
+			column, row = 0, row+1
+			continue
+		}
+		if column == columns {
+			column, row = 0, row+1
+		}
+		if (row+1)*cellH > h {
+			return nil, fmt.Errorf("engine: %q is past the bottom of the %dx%d font texture", r, w, h)
+		}
+		if r != ' ' {
+			font.Glyphs[r] = Glyph{Advance: cellW, Src: sdl.Rect{X: column * cellW, Y: row * cellH, W: cellW, H: cellH}}
+		}
+		column++
+	}
+	return font, nil
+}
+
+// LoadGridFont loads a texture with textures and cuts a font out of it, see NewGridFont
+func LoadGridFont(path string, textures TextureLoader, cellW, cellH int32, chars string) (*BitmapFont, error) {
+	texture, err := textures.LoadTexture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewGridFont(texture, cellW, cellH, chars)
+}
+
+// LoadBMFont loads a font saved by AngelCode's BMFont, or anything else that writes its text or XML formats.
+//   Its pages are loaded with textures, from paths relative to the font file.
+func LoadBMFont(path string, textures TextureLoader) (*BitmapFont, error) {
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tags []bmTag
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("BMF")):
+		err = fmt.Errorf("binary BMFont files aren't supported, save it as text or XML")
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		tags, err = parseBMFontXML(trimmed)
+	default:
+		tags, err = parseBMFontText(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("engine: %s: %v", path, err)
+	}
+
+	font, files, err := newBMFont(tags)
+	if err != nil {
+		return nil, fmt.Errorf("engine: %s: %v", path, err)
+	}
+	for _, file := range files {
+		texture, err := textures.LoadTexture(filepath.Join(filepath.Dir(path), file))
+		if err != nil {
+			return nil, err
+		}
+		font.Pages = append(font.Pages, texture)
+	}
+	return font, nil
+}
+
+// Ascent is how far above the baseline the top of a line is
+func (f *BitmapFont) Ascent() int32 {
+	return f.Base
+}
+
+// Descent is how far below the baseline the bottom of a line is
+func (f *BitmapFont) Descent() int32 {
+	if f.LineHeight < f.Base {
+		return 0
+	}
+	return f.LineHeight - f.Base
+}
+
+// Glyph returns how a character is drawn. Characters the font doesn't have are drawn as a ?,
+//   or left out if it doesn't have that either
+func (f *BitmapFont) Glyph(r rune) (Glyph, error) {
+	if glyph, ok := f.Glyphs[r]; ok {
+		return glyph, nil
+	}
+	return f.Glyphs['?'], nil
+}
+
+// Kerning is how much closer or further apart b is drawn after a
+func (f *BitmapFont) Kerning(a, b rune) int32 {
+	return f.Kernings[[2]rune{a, b}]
+}
+
+// LineSkip is how far apart the tops of lines are
+func (f *BitmapFont) LineSkip() int32 {
+	return f.LineHeight
+}
+
+// Texture returns a page of the font, it's only drawn by the renderer it was loaded with
+func (f *BitmapFont) Texture(renderer Renderer, page int) (Texture, error) {
+	if page < 0 || page >= len(f.Pages) {
+		return nil, fmt.Errorf("engine: font has no page %d", page)
+	}
+	return f.Pages[page], nil
+}
+
+// bmTag is a line of a BMFont text file or an element of an XML one, they have the same names and attributes
+type bmTag struct {
+	attrs map[string]string
+	name  string
+}
+
+// newBMFont builds a font from the tags of a BMFont file, returning the files of its pages in order
+func newBMFont(tags []bmTag) (*BitmapFont, []string, error) {
+	font := &BitmapFont{Glyphs: map[rune]Glyph{}, Kernings: map[[2]rune]int32{}}
+	files := map[int]string{}
+	var err error
+	number := func(tag bmTag, key string) int32 {
+		value, ok := tag.attrs[key]
+		if !ok || err != nil {
+			return 0
+		}
+		n, parseErr := strconv.ParseInt(value, 10, 32)
+		if parseErr != nil {
+			err = fmt.Errorf("%s %s=%q isn't a number", tag.name, key, value)
+		}
+		return int32(n)
+	}
+
+	for _, tag := range tags {
+		switch tag.name {
+		case "common":
+			font.LineHeight = number(tag, "lineHeight")
+			font.Base = number(tag, "base")
+		case "page":
+			files[int(number(tag, "id"))] = tag.attrs["file"]
+		case "char":
+			font.Glyphs[rune(number(tag, "id"))] = Glyph{
+				Advance: number(tag, "xadvance"),
+				Page:    int(number(tag, "page")),
+				Src: sdl.Rect{
+					X: number(tag, "x"),
+					Y: number(tag, "y"),
+					W: number(tag, "width"),
+					H: number(tag, "height"),
+				},
+				XOffset: number(tag, "xoffset"),
+				YOffset: number(tag, "yoffset"),
+			}
+		case "kerning":
+			pair := [2]rune{rune(number(tag, "first")), rune(number(tag, "second"))}
+			font.Kernings[pair] = number(tag, "amount")
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pages := make([]string, len(files))
+	for id, file := range files {
+		if id < 0 || id >= len(files) || file == "" {
+			return nil, nil, fmt.Errorf("pages should be numbered from 0, each with a file")
+		}
+		pages[id] = file
+	}
+	for r, glyph := range font.Glyphs {
+		if glyph.Page < 0 || glyph.Page >= len(pages) {
+			return nil, nil, fmt.Errorf("char %d is on page %d, but there are %d", r, glyph.Page, len(pages))
+		}
+	}
+	return font, pages, nil
+}
+
+// parseBMFontText reads the lines of a text BMFont file, each a tag followed by key=value pairs.
+//   Values may be quoted, eg: page id=0 file="font 0.png"
+func parseBMFontText(data string) ([]bmTag, error) {
+	var tags []bmTag
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		tag := bmTag{attrs: map[string]string{}}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		tag.name, line = line[:end], strings.TrimSpace(line[end:])
+		for line != "" {
+			eq := strings.IndexByte(line, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("line %d: %q has no value", i+1, line)
+			}
+			key, rest := strings.TrimSpace(line[:eq]), line[eq+1:]
+			var value string
+			if strings.HasPrefix(rest, `"`) {
+				quote := strings.IndexByte(rest[1:], '"')
+				if quote < 0 {
+					return nil, fmt.Errorf("line %d: %s is missing a closing quote", i+1, key)
+				}
+				value, rest = rest[1:quote+1], rest[quote+2:]
+			} else {
+				end := strings.IndexAny(rest, " \t")
+				if end < 0 {
+					end = len(rest)
+				}
+				value, rest = rest[:end], rest[end:]
+			}
+			tag.attrs[key] = value
+			line = strings.TrimSpace(rest)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// parseBMFontXML reads the elements of an XML BMFont file
+func parseBMFontXML(data []byte) ([]bmTag, error) {
+	var tags []bmTag
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return tags, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		tag := bmTag{attrs: map[string]string{}, name: element.Name.Local}
+		for _, attr := range element.Attr {
+			tag.attrs[attr.Name.Local] = attr.Value
+		}
+		tags = append(tags, tag)
+	}
+}
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"image"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+const (
+	// glyphPageSize is how wide and high a glyph atlas's page starts out, it grows as glyphs are added
+	glyphPageSize = 256
+	// glyphPadding is the gap left between glyphs, so scaled text doesn't pick up its neighbours' edges
+	glyphPadding = 1
+)
+
+// glyphAtlases are the glyph atlases of each font, see Glyphs
+var glyphAtlases = map[glyphAtlasKey]*GlyphAtlas{}
+
+// glyphAtlasKey is what a glyph atlas is cached under, a font is already a face at a point size
+type glyphAtlasKey struct {
+	font  *ttf.Font
+	solid bool
+}
+
+// Glyph is how a character of a Font is drawn
+type Glyph struct {
+	// Advance is how far along the line the next character starts
+	Advance int32
+	// Page is which of the font's textures the glyph is on
+	Page int
+	// Src is where the glyph is on its page, it's empty for glyphs with nothing to draw, eg: spaces
+	Src sdl.Rect
+	// XOffset and YOffset are where the glyph is drawn from the pen, and from the top of the line
+	XOffset, YOffset int32
+}
+
+// GlyphAtlas is every character of a TrueType font that's been drawn, rendered once in white onto a single texture.
+//   It's how a ttf.Font is used as a Font, eg: text.Font = engine.Glyphs(font, false).
+//   Text is laid out from the cached glyphs and colored with the texture's color and alpha mods,
+//   so changing what text says or its color never renders it again.
+type GlyphAtlas struct {
+	Font *ttf.Font
+	// Solid glyphs are rendered without anti-aliasing, for pixel fonts. Glyphs are blended by default
+	Solid bool
+
+	glyphs  map[rune]Glyph
+	kerning map[[2]rune]int32
+	// page is the glyphs in memory, and where the next one goes along the shelf it's on
+	page               *image.NRGBA
+	penX, penY, shelfH int
+	// texture is page on the renderer, it's created again when glyphs have been added since
+	dirty    bool
+	renderer Renderer
+	texture  Texture
+}
+
+// Glyphs returns the glyph atlas of a font, creating it the first time it's asked for.
+//   Atlases are destroyed along with their font by CloseFont, or by FreeGlyphs for fonts opened some other way.
+func Glyphs(font *ttf.Font, solid bool) *GlyphAtlas {
+	key := glyphAtlasKey{font: font, solid: solid}
+	if atlas, ok := glyphAtlases[key]; ok {
+		return atlas
+	}
+	atlas := &GlyphAtlas{
+		Font:    font,
+		Solid:   solid,
+		glyphs:  map[rune]Glyph{},
+		kerning: map[[2]rune]int32{},
+	}
+	glyphAtlases[key] = atlas
+	return atlas
+}
+
+// FreeGlyphs destroys a font's glyph atlases, do this before closing it
+func FreeGlyphs(font *ttf.Font) {
+	for _, solid := range []bool{false, true} {
+		key := glyphAtlasKey{font: font, solid: solid}
+		if atlas, ok := glyphAtlases[key]; ok {
+			atlas.Destroy()
+			delete(glyphAtlases, key)
+		}
+	}
+}
+
+// Glyph returns a character's glyph, rendering it onto the atlas the first time it's asked for
+func (g *GlyphAtlas) Glyph(r rune) (Glyph, error) {
+	if glyph, ok := g.glyphs[r]; ok {
+		return glyph, nil
+	}
+
+	glyph := Glyph{}
+	if metrics, err := g.Font.GlyphMetrics(r); err == nil {
+		glyph.Advance = int32(metrics.Advance)
+	}
+	white := sdl.Color{R: 255, G: 255, B: 255, A: 255}
+	var surface *sdl.Surface
+	var err error
+	if g.Solid {
+		surface, err = g.Font.RenderUTF8Solid(string(r), white)
+	} else {
+		surface, err = g.Font.RenderUTF8Blended(string(r), white)
+	}
+	// SDL_ttf won't render characters with no width, they still take up space on the line
+	if err != nil {
+		g.glyphs[r] = glyph
+		return glyph, nil
+	}
+	defer surface.Free()
+	if glyph.Advance == 0 {
+		glyph.Advance = surface.W
+	}
+
+	converted, err := surface.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+	if err != nil {
+		return Glyph{}, err
+	}
+	defer converted.Free()
+	glyph.Src = g.place(int(converted.W), int(converted.H))
+	pixels := converted.Pixels()
+	rowLen := int(converted.W) * 4
+	for y := 0; y < int(converted.H); y++ {
+		row := pixels[y*int(converted.Pitch):]
+		at := g.page.PixOffset(int(glyph.Src.X), int(glyph.Src.Y)+y)
+		copy(g.page.Pix[at:at+rowLen], row[:rowLen])
+	}
+	g.dirty = true
+	g.glyphs[r] = glyph
+	return glyph, nil
+}
+
+// Ascent is how far above the baseline the top of a line is
+func (g *GlyphAtlas) Ascent() int32 {
+	return int32(g.Font.Ascent())
+}
+
+// Descent is how far below the baseline the bottom of a line is
+func (g *GlyphAtlas) Descent() int32 {
+	return -int32(g.Font.Descent())
+}
+
+// LineSkip is how far apart the tops of lines are
+func (g *GlyphAtlas) LineSkip() int32 {
+	return int32(g.Font.LineSkip())
+}
+
+// Kerning returns how much closer or further apart b is drawn after a than their advances put them.
+//   go-sdl2 doesn't bind the kerning functions of SDL_ttf, so it's worked out from how wide the pair measures
+//   against the two on their own.
+func (g *GlyphAtlas) Kerning(a, b rune) int32 {
+	if !g.Font.GetKerning() {
+		return 0
+	}
+	pair := [2]rune{a, b}
+	if kern, ok := g.kerning[pair]; ok {
+		return kern
+	}
+	var kern int32
+	both, _, err1 := g.Font.SizeUTF8(string(pair[:]))
+	first, _, err2 := g.Font.SizeUTF8(string(a))
+	second, _, err3 := g.Font.SizeUTF8(string(b))
+	if err1 == nil && err2 == nil && err3 == nil {
+		kern = int32(both - first - second)
+	}
+	g.kerning[pair] = kern
+	return kern
+}
+
+// Texture returns the atlas on renderer, uploading it again if glyphs have been added since it was last drawn.
+//   Every glyph is on the one page.
+func (g *GlyphAtlas) Texture(renderer Renderer, page int) (Texture, error) {
+	if g.texture != nil && !g.dirty && g.renderer == renderer {
+		return g.texture, nil
+	}
+	if g.page == nil {
+		g.page = image.NewNRGBA(image.Rect(0, 0, glyphPageSize, glyphPageSize))
+	}
+	texture, err := TextureFromImage(renderer, g.page)
+	if err != nil {
+		return nil, err
+	}
+	if g.texture != nil {
+		g.texture.Destroy()
+	}
+	g.texture, g.renderer, g.dirty = texture, renderer, false
+	return texture, nil
+}
+
+// Destroy frees the atlas's texture and forgets its glyphs
+func (g *GlyphAtlas) Destroy() {
+	if g.texture != nil {
+		g.texture.Destroy()
+	}
+	g.texture, g.renderer, g.page = nil, nil, nil
+	g.penX, g.penY, g.shelfH = 0, 0, 0
+	g.glyphs = map[rune]Glyph{}
+	g.kerning = map[[2]rune]int32{}
+}
+
+// place finds room for a w by h glyph on the page, along the current shelf or on a new one below it.
+//   The page is made wider or taller to fit, keeping the glyphs already on it where they are.
+func (g *GlyphAtlas) place(w, h int) sdl.Rect {
+	if g.page == nil {
+		g.page = image.NewNRGBA(image.Rect(0, 0, glyphPageSize, glyphPageSize))
+	}
+	size := g.page.Rect.Size()
+	if g.penX+w+glyphPadding > size.X {
+		g.penX, g.penY, g.shelfH = 0, g.penY+g.shelfH, 0
+	}
+	for w+glyphPadding > size.X {
+		size.X *= 2
+	}
+	for g.penY+h+glyphPadding > size.Y {
+		size.Y *= 2
+	}
+	if size != g.page.Rect.Size() {
+		grown := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		old := g.page.Rect.Size()
+		for y := 0; y < old.Y; y++ {
+			copy(grown.Pix[y*grown.Stride:], g.page.Pix[y*g.page.Stride:y*g.page.Stride+old.X*4])
+		}
+		g.page = grown
+	}
+
+	at := sdl.Rect{X: int32(g.penX), Y: int32(g.penY), W: int32(w), H: int32(h)}
+	g.penX += w + glyphPadding
+	if h+glyphPadding > g.shelfH {
+		g.shelfH = h + glyphPadding
+	}
+	return at
+}
@@ -1,65 +1,77 @@
 package engine
 
 import (
-	"github.com/veandco/go-sdl2/gfx"
+	"math"
+
+	"github.com/ryanhartje/gogome/pkg/input"
+	"github.com/ryanhartje/gogome/pkg/spatial"
 	"github.com/veandco/go-sdl2/mix"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 var grid = false
 
+// EntityCellSize is the size of the cells a level's entities are indexed by
+const EntityCellSize = 128
+
 // Level provides us a way to scroll and update the level
 type Level struct {
 	// BGFile is the filepath to the background
 	BGFile string
+	// Camera is what the level is seen through. It scrolls with the movement actions unless it has a Target to follow
+	Camera *Camera
 	// Give level a debug passthrough
-	Debug            bool
-	CameraX, CameraY int
-	// EntityMap entities and draws them on the map when they're in focus
-	EntityMap map[int]map[int]Entity
+	Debug bool
+	// Entities indexes the level's entities by where they are, so the ones in view can be found quickly.
+	//   Use AddEntity, MoveEntity and RemoveEntity to keep it up to date
+	Entities *spatial.Grid
+	// Input is the action map used to scroll the level, input.Default is used when it's nil
+	Input *input.Map
 	// Lighting takes a function that allows the user to play with lighting mechanics
 	Lighting func(*Level)
 	// represents how many pixels we scroll per cycle. default 16
 	ScrollSpeed int
 	Sounds      map[string][]*mix.Chunk
-	Texture     *sdl.Texture
-	// TileMap is a matrix representing the map
-	// A z index is arbitrarily establish by the slice ordering
-	// the 0th object is drawn first, with each subsequent object drawn on top of the previous one
-	// TileMap[x][y]
-	TileMap  map[int]map[int][]Tile
+	Texture     Texture
+	// Textures holds any tilesets tiles are cut from besides the background, by path
+	Textures map[string]Texture
+	// TileMap is a grid of tiles indexed by tile coordinates, so pixel x, y is at x/TileSize, y/TileSize
+	// Each cell is a stack of layers, the 0th layer is drawn first, with each subsequent layer drawn on top of the previous one
+	TileMap  *TileMap
 	TileSize int
-	// Current level's coordinates
-	X, Y int
 	// Size coords to stop scrolling approriately
 	XSize int
 	YSize int
+
+	// queue is what Draw draws the level with
+	queue *RenderQueue
 }
 
 // Tile represents a tile in a tilemap. This might be a 16x16 sprite or a 16x128 tile.
 type Tile struct {
-	Name string
-	X0   int32
-	X1   int32
-	Y0   int32
-	Y1   int32
+	// Angle and Flip rotate (clockwise, in degrees) and flip the tile when it's drawn
+	Angle float64
+	// Collision is how the tile stops things moving into it, see Level.Resolve
+	Collision Collision
+	Flip      sdl.RendererFlip
+	Name      string
+	// Texture is the tileset the tile is cut from, the level's Texture is used when it's nil
+	Texture Texture
+	X0      int32
+	X1      int32
+	Y0      int32
+	Y1      int32
 }
 
 // NewLevel takes in the filepath of a level's background, and a renderer
-func NewLevel(filepath string, renderer *sdl.Renderer) (*Level, error) {
-	img, err := sdl.LoadBMP(filepath)
-
-	checkErr(err)
-	defer img.Free()
-
-	bgTexture, err := renderer.CreateTextureFromSurface(img)
+func NewLevel(filepath string, renderer Renderer) (*Level, error) {
+	bgTexture, err := renderer.LoadTexture(filepath)
 	if err != nil {
 		return &Level{}, err
 	}
 	return &Level{
 		BGFile:      filepath,
-		CameraX:     640,
-		CameraY:     480,
+		Camera:      NewCamera(640, 480),
 		Texture:     bgTexture,
 		TileSize:    32,
 		ScrollSpeed: 8,
@@ -68,141 +80,214 @@ func NewLevel(filepath string, renderer *sdl.Renderer) (*Level, error) {
 }
 
 // NewRandomizedLevel takes in the filepath of a level's background, and a renderer
-func NewRandomizedLevel(filepath string, renderer *sdl.Renderer) (*Level, error) {
-	img, err := sdl.LoadBMP(filepath)
-
-	checkErr(err)
-	defer img.Free()
-
-	bgTexture, err := renderer.CreateTextureFromSurface(img)
+func NewRandomizedLevel(filepath string, renderer Renderer) (*Level, error) {
+	bgTexture, err := renderer.LoadTexture(filepath)
 	if err != nil {
 		return &Level{}, err
 	}
 
 	level := &Level{
 		BGFile:      filepath,
-		CameraX:     640,
-		CameraY:     480,
+		Camera:      NewCamera(640, 480),
 		Texture:     bgTexture,
 		TileSize:    32,
 		ScrollSpeed: 8,
 		Sounds:      make(map[string][]*mix.Chunk),
 	}
 
-	grass := Tile{Name: "grass", X0: 0, X1: 16, Y0: 0, Y1: 16}
-	grass2 := Tile{Name: "grass2", X0: 272, X1: 288, Y0: 464, Y1: 480}
-	//bush := Tile{Name: "bush", X0: 32, X1: 48, Y0: 224, Y1: 240}
-	mapping := map[int]map[int][]Tile{}
-	level.EntityMap = map[int]map[int]Entity{}
+	tiles := NewTileMap((winW*10+level.TileSize-1)/level.TileSize, (winH*10+level.TileSize-1)/level.TileSize)
+	// A new tileset has room for plenty more than these
+	grass, _ := tiles.Tileset.Add(Tile{Name: "grass", X0: 0, X1: 16, Y0: 0, Y1: 16})
+	grass2, _ := tiles.Tileset.Add(Tile{Collision: CollisionSolid, Name: "grass2", X0: 272, X1: 288, Y0: 464, Y1: 480})
+	//bush, _ := tiles.Tileset.Add(Tile{Name: "bush", X0: 32, X1: 48, Y0: 224, Y1: 240})
 
-	// Bootstrap TileMap for the background, and entity map to render entities on top of
-	// iterate by the x and y values of the sprite's width and height, so that you don't
-	// draw over other tiles.
-	for x := 0; x < (winW * 10); x += level.TileSize {
-		mapping[x] = make(map[int][]Tile)
-		level.EntityMap[x] = make(map[int]Entity)
-		for y := 0; y < (winH * 10); y += level.TileSize {
+	// Bootstrap TileMap for the background
+	for x := 0; x < tiles.Width; x++ {
+		for y := 0; y < tiles.Height; y++ {
 			// populate map with Tiles
-			mapping[x][y] = []Tile{}
-			mapping[x][y] = append(mapping[x][y], grass)
+			tiles.Set(x, y, 0, grass)
 			if x == 0 || y == 0 {
-				mapping[x][y] = append(mapping[x][y], grass2)
+				tiles.Set(x, y, 1, grass2)
 			}
-
-			level.EntityMap[x][y] = nil
 		}
 	}
-	level.TileMap = mapping
+	level.TileMap = tiles
+	level.XSize = tiles.Width * level.TileSize
+	level.YSize = tiles.Height * level.TileSize
+	level.Camera.Bounds = level.Bounds()
 
 	return level, nil
 }
 
-// Draw takes the camera viewport and renders it to the screen
-func (level *Level) Draw(renderer *sdl.Renderer) {
-	// Render level to window tile by tile
-	for x := 0; x < level.CameraX; x += level.ScrollSpeed {
-		for y := 0; y < level.CameraY; y += level.ScrollSpeed {
-			// Here is the information we need to know where to render all tiles
-			var xOffset int
-			var yOffset int
+// Bounds returns the area of the world the level covers
+func (level *Level) Bounds() sdl.Rect {
+	return sdl.Rect{W: int32(level.XSize), H: int32(level.YSize)}
+}
 
-			tileX := level.X + x
-			tileY := level.Y + y
+// Destroy frees the level's background and tileset textures
+func (level *Level) Destroy() {
+	if level.Texture != nil {
+		level.Texture.Destroy()
+	}
+	for _, texture := range level.Textures {
+		texture.Destroy()
+	}
+}
 
-			if x == 0 && level.X%level.TileSize != 0 {
-				xOffset = level.X % level.TileSize
-				tileX = level.X - xOffset
-			}
-			if y == 0 && level.Y%level.TileSize != 0 {
-				yOffset = level.Y % level.TileSize
-				tileY = level.Y - yOffset
-			}
+// replaceTiles swaps in the tiles and textures of a freshly loaded copy of the level,
+//   keeping its camera and entities as they are
+func (level *Level) replaceTiles(fresh *Level) {
+	level.Destroy()
+	level.Texture = fresh.Texture
+	level.Textures = fresh.Textures
+	level.TileMap = fresh.TileMap
+	level.TileSize = fresh.TileSize
+	level.XSize = fresh.XSize
+	level.YSize = fresh.YSize
+	level.Camera.Bounds = level.Bounds()
+}
 
-			tiles := level.TileMap[tileX][tileY]
-			// Now that we've processed offsets and our tile for this iteration, range throuhg tiles and draw them
-			for _, tile := range tiles {
-				width := tile.X1 - tile.X0
-				height := tile.Y1 - tile.Y0
-
-				// Render the background of the level
-				renderer.Copy(
-					level.Texture,
-					&sdl.Rect{X: tile.X0 + int32(xOffset/2), Y: tile.Y0 + int32(yOffset/2), W: int32(width), H: int32(height)},
-					&sdl.Rect{X: int32(x), Y: int32(y), W: int32(level.TileSize), H: int32(level.TileSize)},
-				)
-			}
+// AddEntity adds an entity to the level where it currently is
+func (level *Level) AddEntity(entity Entity) {
+	if level.Entities == nil {
+		level.Entities = spatial.NewGrid(EntityCellSize)
+	}
+	level.Entities.Insert(entity, entityRect(entity))
+}
 
-		}
-		if level.Lighting != nil {
-			level.Lighting(level)
-		}
+// MoveEntity updates where the level has an entity after it's moved. Entities that aren't in the level are ignored
+func (level *Level) MoveEntity(entity Entity) {
+	if level.Entities != nil {
+		level.Entities.Move(entity, entityRect(entity))
 	}
+}
 
-	// Render a grid to the screen if debug is on
-	if level.Debug {
-		// Render a grid. Draw lines from 0 to the width/height of the screen along the X and Y axis, incrementing by our tilesize
-		for i := 0; i < winW; i += level.TileSize {
-			gfx.LineRGBA(renderer, int32(0), int32(i), int32(winW), int32(i), 100, 0, 0, 100)
-			gfx.LineRGBA(renderer, int32(i), int32(0), int32(i), int32(winH), 100, 0, 0, 100)
-		}
-		renderer.SetDrawColor(255, 255, 255, 255)
+// RemoveEntity takes an entity out of the level
+func (level *Level) RemoveEntity(entity Entity) {
+	if level.Entities != nil {
+		level.Entities.Remove(entity)
 	}
 }
 
-// Update watches keybindings and scrolls as necessary
-func (level *Level) Update() {
-	keys := sdl.GetKeyboardState()
+// EntitiesIn returns the level's entities that overlap a rect of the world
+func (level *Level) EntitiesIn(x, y, w, h float64) []Entity {
+	if level.Entities == nil {
+		return nil
+	}
+	items := level.Entities.Query(spatial.Rect{X: x, Y: y, W: w, H: h})
+	entities := make([]Entity, len(items))
+	for i, item := range items {
+		entities[i] = item.(Entity)
+	}
+	return entities
+}
 
-	// UP
-	if keys[sdl.SCANCODE_W] == 1 {
-		if level.Y > 0 {
-			level.Y -= level.ScrollSpeed
-		} else {
-			level.Y = 0
-		}
+// VisibleEntities returns the level's entities the camera can see
+func (level *Level) VisibleEntities() []Entity {
+	w, h := level.Camera.ViewSize()
+	return level.EntitiesIn(level.Camera.X, level.Camera.Y, w, h)
+}
+
+// entityRect returns the part of the world an entity covers
+func entityRect(entity Entity) spatial.Rect {
+	x, y := entity.GetLevelCoords()
+	w, h := entity.Size()
+	return spatial.Rect{X: float64(x), Y: float64(y), W: float64(w), H: float64(h)}
+}
+
+// Draw renders the part of the level in view of the camera to the screen
+func (level *Level) Draw(renderer Renderer) {
+	if level.queue == nil {
+		level.queue = NewRenderQueue()
 	}
-	// DOWN
-	if keys[sdl.SCANCODE_S] == 1 {
-		if level.Y < level.YSize {
-			level.Y += level.ScrollSpeed
-		} else {
-			level.Y = level.YSize
-		}
+	level.Submit(level.queue)
+	level.queue.Flush(renderer)
+}
+
+// Submit queues the part of the level in view of the camera to be drawn, each layer of the TileMap on its own
+//   layer from LayerTiles up. Lighting is drawn over the tiles but under entities, and debug info over everything.
+func (level *Level) Submit(queue *RenderQueue) {
+	if level.TileSize <= 0 {
+		return
 	}
-	// LEFT
-	if keys[sdl.SCANCODE_A] == 1 {
-		if level.X > 0 {
-			level.X -= level.ScrollSpeed
-		} else {
-			level.X = 0
+	camera := level.Camera
+
+	// Work out which tiles are in view so we only visit each of them once
+	viewW, viewH := camera.ViewSize()
+	size := float64(level.TileSize)
+	firstX := int(math.Floor(camera.X / size))
+	firstY := int(math.Floor(camera.Y / size))
+	lastX := int(math.Floor((camera.X + viewW) / size))
+	lastY := int(math.Floor((camera.Y + viewH) / size))
+
+	if level.TileMap != nil {
+		// Queue the level tile by tile, a layer at a time so upper layers are drawn over lower ones
+		for z := 0; z < level.TileMap.Layers(); z++ {
+			for tileY := firstY; tileY <= lastY; tileY++ {
+				for tileX := firstX; tileX <= lastX; tileX++ {
+					tile, ok := level.TileMap.Tileset.Tile(level.TileMap.Get(tileX, tileY, z))
+					if !ok {
+						continue
+					}
+					cmd := level.tileCommand(tile, *camera.WorldRect(float64(tileX)*size, float64(tileY)*size, size, size))
+					cmd.Layer = LayerTiles + z
+					queue.Submit(cmd)
+				}
+			}
 		}
 	}
-	// RIGHT
-	if keys[sdl.SCANCODE_D] == 1 {
-		if level.X < level.XSize {
-			level.X += level.ScrollSpeed
-		} else {
-			level.X = level.XSize
+	if level.Lighting != nil {
+		queue.SubmitFunc(LayerEntities-1, 0, func(renderer Renderer) { level.Lighting(level) })
+	}
+
+	// Render a grid to the screen if debug is on, with the tiles that collide highlighted
+	if level.Debug {
+		queue.SubmitFunc(LayerOverlay, 0, func(renderer Renderer) {
+			if level.TileMap != nil {
+				level.drawCollisions(renderer, firstX, firstY, lastX, lastY)
+			}
+			// Render a grid along the tile edges in view, so it scrolls and zooms with the level
+			color := sdl.Color{R: 100, G: 0, B: 0, A: 100}
+			for tileX := firstX; tileX <= lastX; tileX++ {
+				screenX, _ := camera.WorldToScreen(float64(tileX)*size, 0)
+				renderer.Line(screenX, 0, screenX, int32(camera.H), color)
+			}
+			for tileY := firstY; tileY <= lastY; tileY++ {
+				_, screenY := camera.WorldToScreen(0, float64(tileY)*size)
+				renderer.Line(0, screenY, int32(camera.W), screenY, color)
+			}
+			renderer.SetDrawColor(255, 255, 255, 255)
+		})
+	}
+}
+
+// tileCommand returns a command that draws a single tile to dst on the screen
+func (level *Level) tileCommand(tile Tile, dst sdl.Rect) DrawCommand {
+	texture := tile.Texture
+	if texture == nil {
+		texture = level.Texture
+	}
+	return DrawCommand{
+		Angle:   tile.Angle,
+		Dst:     dst,
+		Flip:    tile.Flip,
+		Src:     sdl.Rect{X: tile.X0, Y: tile.Y0, W: tile.X1 - tile.X0, H: tile.Y1 - tile.Y0},
+		Texture: texture,
+	}
+}
+
+// Update moves the camera. Without a target to follow, it watches the movement actions and scrolls as necessary
+func (level *Level) Update(dt float64) {
+	camera := level.Camera
+	if camera.Target == nil {
+		actions := level.Input
+		if actions == nil {
+			actions = input.Default
 		}
+
+		scroll := float64(level.ScrollSpeed)
+		camera.X += (actions.Value(input.MoveRight) - actions.Value(input.MoveLeft)) * scroll
+		camera.Y += (actions.Value(input.MoveDown) - actions.Value(input.MoveUp)) * scroll
 	}
+	camera.Update(dt)
 }
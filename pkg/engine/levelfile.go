@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// LevelFormatVersion is the version of the level format written by Level.Save
+const LevelFormatVersion = 1
+
+// Spawnable entities can describe how to spawn themselves, so they're kept when a level is saved.
+//   Entities that aren't Spawnable, like the Player, are left out of saved levels.
+type Spawnable interface {
+	Spawn() Spawn
+}
+
+// levelFile is the on disk format for a level.
+//   CameraW and CameraH are the size of the camera's viewport.
+type levelFile struct {
+	Version     int    `json:"version"`
+	BGFile      string `json:"bg_file,omitempty"`
+	CameraW     int    `json:"camera_w"`
+	CameraH     int    `json:"camera_h"`
+	ScrollSpeed int    `json:"scroll_speed"`
+	TileSize    int    `json:"tile_size"`
+	XSize       int    `json:"x_size"`
+	YSize       int    `json:"y_size"`
+	// Textures are the paths of the tilesets tiles are cut from, other than the background
+	Textures []string     `json:"textures,omitempty"`
+	Tiles    []tileRecord `json:"tiles"`
+	// Layers are grids of indexes into Tiles, plus one so 0 can mean there's no tile.
+	//   These are the TileMap's tile ids, so a layer is saved and loaded as is
+	Layers   []layerRecord `json:"layers"`
+	Entities []spawnRecord `json:"entities"`
+}
+
+// tileRecord is a Tile as it's saved
+type tileRecord struct {
+	Name  string           `json:"name"`
+	X0    int32            `json:"x0"`
+	X1    int32            `json:"x1"`
+	Y0    int32            `json:"y0"`
+	Y1    int32            `json:"y1"`
+	Angle float64          `json:"angle,omitempty"`
+	Flip  sdl.RendererFlip `json:"flip,omitempty"`
+	// Collision is the name of the tile's Collision, eg: "solid"
+	Collision string `json:"collision,omitempty"`
+	// Texture indexes Textures plus one, 0 is the level's background
+	Texture int `json:"texture,omitempty"`
+}
+
+// layerRecord is one z level of the TileMap, stored row by row
+type layerRecord struct {
+	Width  int   `json:"width"`
+	Height int   `json:"height"`
+	Tiles  []int `json:"tiles"`
+}
+
+// spawnRecord is a Spawn as it's saved
+type spawnRecord struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name,omitempty"`
+	X          int               `json:"x"`
+	Y          int               `json:"y"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Save writes the level as JSON that LoadLevel can read back.
+//   Tiles cut from textures other than the background must have their path in level.Textures.
+func (level *Level) Save(w io.Writer) error {
+	if level.TileSize <= 0 {
+		return fmt.Errorf("engine: can't save a level with a tile size of %d", level.TileSize)
+	}
+	if level.Camera == nil {
+		return fmt.Errorf("engine: can't save a level without a camera")
+	}
+	file := levelFile{
+		Version:     LevelFormatVersion,
+		BGFile:      level.BGFile,
+		CameraW:     level.Camera.W,
+		CameraH:     level.Camera.H,
+		ScrollSpeed: level.ScrollSpeed,
+		TileSize:    level.TileSize,
+		XSize:       level.XSize,
+		YSize:       level.YSize,
+		Tiles:       []tileRecord{},
+		Layers:      []layerRecord{},
+		Entities:    []spawnRecord{},
+	}
+
+	// Number each tileset texture by the order its path sorts in, so saves are stable
+	for path := range level.Textures {
+		file.Textures = append(file.Textures, path)
+	}
+	sort.Strings(file.Textures)
+	textureIndex := map[Texture]int{}
+	for i, path := range file.Textures {
+		textureIndex[level.Textures[path]] = i + 1
+	}
+
+	// The tileset is saved as is, so tile ids in the layers don't need remapping
+	if level.TileMap != nil {
+		for id := 1; id <= level.TileMap.Tileset.Len(); id++ {
+			tile, _ := level.TileMap.Tileset.Tile(TileID(id))
+			record := tileRecord{
+				Name:  tile.Name,
+				X0:    tile.X0,
+				X1:    tile.X1,
+				Y0:    tile.Y0,
+				Y1:    tile.Y1,
+				Angle: tile.Angle,
+				Flip:  tile.Flip,
+			}
+			if tile.Collision != CollisionNone {
+				record.Collision = tile.Collision.String()
+			}
+			if tile.Texture != nil && tile.Texture != level.Texture {
+				index, ok := textureIndex[tile.Texture]
+				if !ok {
+					return fmt.Errorf("engine: tile %q uses a texture that isn't in level.Textures", tile.Name)
+				}
+				record.Texture = index
+			}
+			file.Tiles = append(file.Tiles, record)
+		}
+
+		width, height := level.TileMap.Width, level.TileMap.Height
+		for z := 0; z < level.TileMap.Layers(); z++ {
+			layer := layerRecord{Width: width, Height: height, Tiles: make([]int, width*height)}
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					layer.Tiles[y*width+x] = int(level.TileMap.Get(x, y, z))
+				}
+			}
+			file.Layers = append(file.Layers, layer)
+		}
+	}
+
+	// Entities are saved in order of where they are, so saves are stable
+	var spawns []Spawn
+	if level.Entities != nil {
+		for _, item := range level.Entities.Items() {
+			if spawnable, ok := item.(Spawnable); ok {
+				spawns = append(spawns, spawnable.Spawn())
+			}
+		}
+	}
+	sort.Slice(spawns, func(i, j int) bool {
+		a, b := spawns[i], spawns[j]
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	for _, spawn := range spawns {
+		file.Entities = append(file.Entities, spawnRecord{
+			Type:       spawn.Type,
+			Name:       spawn.Name,
+			X:          spawn.X,
+			Y:          spawn.Y,
+			Properties: spawn.Properties,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// LoadLevel reads a level written by Level.Save, loading its textures with renderer and
+//   spawning its entities with the factories registered with RegisterEntity
+func LoadLevel(r io.Reader, renderer Renderer) (*Level, error) {
+	return readLevel(r, renderer, renderer)
+}
+
+// readLevel reads a level written by Level.Save, spawning its entities with entities loading their textures.
+//   The level's own textures are loaded with renderer, since Level.Destroy frees them.
+//   A nil entities skips spawning the entities, for when only the tiles are wanted.
+func readLevel(r io.Reader, renderer Renderer, entities TextureLoader) (*Level, error) {
+	var file levelFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	if file.Version != LevelFormatVersion {
+		return nil, fmt.Errorf("engine: unsupported level version %d", file.Version)
+	}
+	if file.TileSize <= 0 {
+		return nil, fmt.Errorf("engine: level has a tile size of %d", file.TileSize)
+	}
+
+	level := &Level{
+		BGFile:      file.BGFile,
+		Camera:      NewCamera(file.CameraW, file.CameraH),
+		ScrollSpeed: file.ScrollSpeed,
+		Sounds:      make(map[string][]*mix.Chunk),
+		Textures:    map[string]Texture{},
+		TileSize:    file.TileSize,
+		XSize:       file.XSize,
+		YSize:       file.YSize,
+	}
+	level.Camera.Bounds = level.Bounds()
+	// Free the textures already loaded if anything after them fails
+	failed := true
+	defer func() {
+		if failed {
+			level.Destroy()
+		}
+	}()
+	if file.BGFile != "" {
+		texture, err := renderer.LoadTexture(file.BGFile)
+		if err != nil {
+			return nil, err
+		}
+		level.Texture = texture
+	}
+	textures := make([]Texture, len(file.Textures))
+	for i, path := range file.Textures {
+		texture, err := renderer.LoadTexture(path)
+		if err != nil {
+			return nil, err
+		}
+		textures[i] = texture
+		level.Textures[path] = texture
+	}
+
+	width, height := 0, 0
+	for _, layer := range file.Layers {
+		if len(layer.Tiles) != layer.Width*layer.Height {
+			return nil, fmt.Errorf("engine: layer has %d tiles, expected %dx%d", len(layer.Tiles), layer.Width, layer.Height)
+		}
+		if layer.Width > width {
+			width = layer.Width
+		}
+		if layer.Height > height {
+			height = layer.Height
+		}
+	}
+	level.TileMap = NewTileMap(width, height)
+
+	// Identical tiles share an id once they're in the tileset, so map each saved id to its new one
+	ids := make([]TileID, len(file.Tiles)+1)
+	for i, record := range file.Tiles {
+		tile := Tile{
+			Angle: record.Angle,
+			Flip:  record.Flip,
+			Name:  record.Name,
+			X0:    record.X0,
+			X1:    record.X1,
+			Y0:    record.Y0,
+			Y1:    record.Y1,
+		}
+		if record.Collision != "" {
+			collision, err := ParseCollision(record.Collision)
+			if err != nil {
+				return nil, fmt.Errorf("engine: tile %q: %v", record.Name, err)
+			}
+			tile.Collision = collision
+		}
+		if record.Texture > 0 {
+			if record.Texture > len(textures) {
+				return nil, fmt.Errorf("engine: tile %q uses texture %d, but there are only %d", record.Name, record.Texture, len(textures))
+			}
+			tile.Texture = textures[record.Texture-1]
+		}
+		id, err := level.TileMap.Tileset.Add(tile)
+		if err != nil {
+			return nil, err
+		}
+		ids[i+1] = id
+	}
+
+	for z, layer := range file.Layers {
+		for i, id := range layer.Tiles {
+			if id == 0 {
+				continue
+			}
+			if id < 0 || id >= len(ids) {
+				return nil, fmt.Errorf("engine: layer uses tile %d, but there are only %d", id, len(file.Tiles))
+			}
+			level.TileMap.Set(i%layer.Width, i/layer.Width, z, ids[id])
+		}
+	}
+
+	if entities == nil {
+		failed = false
+		return level, nil
+	}
+	for _, record := range file.Entities {
+		spawn := Spawn{
+			Type:       record.Type,
+			Name:       record.Name,
+			X:          record.X,
+			Y:          record.Y,
+			Properties: record.Properties,
+		}
+		entity, err := SpawnEntity(spawn, entities)
+		if err != nil {
+			return nil, err
+		}
+		level.PlaceEntity(spawn.X, spawn.Y, entity)
+	}
+
+	failed = false
+	return level, nil
+}
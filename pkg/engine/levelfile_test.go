@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// marker is an entity that only knows where it is, so it can be saved and loaded without any assets
+type marker struct {
+	name string
+	x, y float64
+}
+
+func init() {
+	RegisterEntity("test_marker", func(spawn Spawn, textures TextureLoader) (Entity, error) {
+		return &marker{name: spawn.Name}, nil
+	})
+}
+
+func (m *marker) Draw(Renderer, *Camera)     {}
+func (m *marker) GetLevelCoords() (int, int) { return int(m.x), int(m.y) }
+func (m *marker) SetX(x float64)             { m.x = x }
+func (m *marker) SetY(y float64)             { m.y = y }
+func (m *marker) Size() (int32, int32)       { return 16, 16 }
+func (m *marker) Update(float64)             {}
+func (m *marker) Spawn() Spawn {
+	return Spawn{Type: "test_marker", Name: m.name, X: int(m.x), Y: int(m.y)}
+}
+
+func TestLevelSaveLoad(t *testing.T) {
+	level := &Level{
+		Camera:      NewCamera(640, 480),
+		ScrollSpeed: 8,
+		TileMap:     NewTileMap(40, 3),
+		TileSize:    32,
+		XSize:       40 * 32,
+		YSize:       3 * 32,
+	}
+	grass := Tile{Name: "grass", X1: 16, Y1: 16}
+	wall := Tile{Collision: CollisionSolid, Name: "wall", X0: 16, X1: 32, Y1: 16, Angle: 90}
+	for x := 0; x < level.TileMap.Width; x++ {
+		for y := 0; y < level.TileMap.Height; y++ {
+			level.TileMap.SetTile(x, y, 0, grass)
+		}
+	}
+	level.TileMap.SetTile(ChunkSize+2, 1, 1, wall)
+	// Off the tile grid, and off the level, entities are kept where they are
+	level.PlaceEntity(100, 50, &marker{name: "a"})
+	level.PlaceEntity(-40, -8, &marker{name: "b"})
+
+	var saved bytes.Buffer
+	if err := level.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(saved.String(), `"camera_w": 640`) || !strings.Contains(saved.String(), `"camera_h": 480`) {
+		t.Errorf("camera size isn't saved as camera_w and camera_h:\n%s", saved.String())
+	}
+	loaded, err := LoadLevel(bytes.NewReader(saved.Bytes()), NewSoftwareRenderer(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Camera.W != 640 || loaded.Camera.H != 480 {
+		t.Errorf("camera is %dx%d, want 640x480", loaded.Camera.W, loaded.Camera.H)
+	}
+	if loaded.TileSize != level.TileSize || loaded.ScrollSpeed != level.ScrollSpeed || loaded.XSize != level.XSize || loaded.YSize != level.YSize {
+		t.Errorf("loaded %+v, want the sizes of %+v", loaded, level)
+	}
+	for z := 0; z < 2; z++ {
+		for y := 0; y < level.TileMap.Height; y++ {
+			for x := 0; x < level.TileMap.Width; x++ {
+				want, _ := level.TileMap.Tileset.Tile(level.TileMap.Get(x, y, z))
+				got, _ := loaded.TileMap.Tileset.Tile(loaded.TileMap.Get(x, y, z))
+				if got != want {
+					t.Fatalf("tile %d,%d layer %d is %+v, want %+v", x, y, z, got, want)
+				}
+			}
+		}
+	}
+
+	var spawns []Spawn
+	for _, item := range loaded.Entities.Items() {
+		spawns = append(spawns, item.(Spawnable).Spawn())
+	}
+	sort.Slice(spawns, func(i, j int) bool { return spawns[i].Name < spawns[j].Name })
+	want := []Spawn{{Type: "test_marker", Name: "a", X: 100, Y: 50}, {Type: "test_marker", Name: "b", X: -40, Y: -8}}
+	if !reflect.DeepEqual(spawns, want) {
+		t.Errorf("loaded entities %+v, want %+v", spawns, want)
+	}
+
+	// Saving what was loaded gives the same file back
+	var resaved bytes.Buffer
+	if err := loaded.Save(&resaved); err != nil {
+		t.Fatal(err)
+	}
+	if resaved.String() != saved.String() {
+		t.Errorf("saving a loaded level changed it:\n%s\nwant:\n%s", resaved.String(), saved.String())
+	}
+}
+
+func TestLevelSaveWithoutCamera(t *testing.T) {
+	if err := (&Level{TileSize: 32}).Save(&bytes.Buffer{}); err == nil {
+		t.Error("saved a level without a camera")
+	}
+}
+
+func TestLoadLevelFailureFreesTextures(t *testing.T) {
+	withFS(t, fstest.MapFS{
+		"bg.png":    {Data: testPNG(t, 16, 16)},
+		"tiles.png": {Data: testPNG(t, 16, 16)},
+	})
+	// Everything loads until an entity that isn't registered is spawned
+	file := `{"version": 1, "bg_file": "bg.png", "camera_w": 640, "camera_h": 480, "tile_size": 16, "textures": ["tiles.png"],
+		"tiles": [], "layers": [], "entities": [{"type": "not_registered", "name": "", "x": 0, "y": 0}]}`
+	renderer := &loadCounter{SoftwareRenderer: NewSoftwareRenderer(1, 1)}
+	if _, err := LoadLevel(strings.NewReader(file), renderer); err == nil {
+		t.Fatal("loaded a level with an entity that isn't registered")
+	}
+	if len(renderer.loaded) != 2 {
+		t.Fatalf("loaded %d textures, want the background and tileset", len(renderer.loaded))
+	}
+	for i, texture := range renderer.loaded {
+		if !texture.destroyed {
+			t.Errorf("texture %d wasn't destroyed", i)
+		}
+	}
+}
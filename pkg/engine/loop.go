@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"errors"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// DefaultTickRate is how many times per second Update is called when the engine doesn't specify its own rate
+	DefaultTickRate = 60
+	// DefaultMaxFrameSkip caps how many updates we'll run back to back before drawing a frame
+	DefaultMaxFrameSkip = 5
+)
+
+// ErrQuit is returned by Run when the loop stopped because SDL told us the window was closed
+var ErrQuit = errors.New("engine: quit requested")
+
+// Game is anything the engine can drive with Run.
+//   Update is called at a fixed rate, dt being the length of a tick in seconds. It mustn't draw,
+//   the frame is cleared after the tick and anything drawn would be wiped before it's presented.
+//   Draw is called as often as we can manage, and alpha is how far (0 to 1) we are between
+//   the last tick and the next one, so positions can be interpolated for smooth rendering.
+type Game interface {
+	Update(dt float64)
+	Draw(renderer Renderer, alpha float64)
+}
+
+// tickRate returns how many times per second the engine updates, DefaultTickRate when TickRate isn't set
+func (e *Engine) tickRate() int {
+	if e.TickRate <= 0 {
+		return DefaultTickRate
+	}
+	return e.TickRate
+}
+
+// EventHandler can optionally be implemented by a Game that wants to see sdl events as they're polled
+type EventHandler interface {
+	HandleEvent(event sdl.Event)
+}
+
+// Run drives game with a fixed timestep update and variable rate render loop until Stop is called
+//   or the window is closed, in which case ErrQuit is returned.
+//   Elapsed time is accumulated each frame and spent in TickRate sized steps. If we fall behind, at most
+//   MaxFrameSkip updates are run before a frame is drawn and any time left over is dropped, so a slow
+//   machine slows the game down instead of spiraling.
+func (e *Engine) Run(game Game) error {
+	e.TickRate = e.tickRate()
+	if e.MaxFrameSkip <= 0 {
+		e.MaxFrameSkip = DefaultMaxFrameSkip
+	}
+	step := time.Second / time.Duration(e.TickRate)
+	handler, _ := game.(EventHandler)
+
+	e.running = true
+	defer func() { e.running = false }()
+
+	var accumulator time.Duration
+	previous := time.Now()
+	for e.running {
+		now := time.Now()
+		accumulator += now.Sub(previous)
+		previous = now
+
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			if _, ok := event.(*sdl.QuitEvent); ok {
+				return ErrQuit
+			}
+			if e.Controllers != nil {
+				e.Controllers.HandleEvent(event)
+			}
+			// Live input is ignored while a replay is playing
+			if e.Input != nil && e.replay == nil {
+				e.Input.HandleEvent(event)
+			}
+			if handler != nil {
+				handler.HandleEvent(event)
+			}
+		}
+
+		updates := 0
+		for accumulator >= step && updates < e.MaxFrameSkip && e.running {
+			e.tick(game, step)
+			accumulator -= step
+			updates++
+		}
+		// We've hit our frame skip cap, drop the time we couldn't catch up on
+		if accumulator >= step {
+			accumulator %= step
+		}
+		if !e.running {
+			break
+		}
+
+		if e.Assets != nil && e.Assets.HotReload {
+			e.Assets.Poll()
+		}
+
+		if e.Renderer != nil {
+			e.Renderer.Clear()
+			game.Draw(e.Renderer, float64(accumulator)/float64(step))
+			e.Renderer.Present()
+		}
+
+		// Don't spin the CPU when we're ahead of the next tick
+		if updates == 0 {
+			sdl.Delay(1)
+		}
+	}
+	return nil
+}
+
+// Stop breaks out of Run once the current tick or frame is finished
+func (e *Engine) Stop() {
+	e.running = false
+}
+
+// Running reports whether Run is currently driving a game
+func (e *Engine) Running() bool {
+	return e.running
+}
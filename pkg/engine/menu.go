@@ -1,72 +1,204 @@
 package engine
 
 import (
-	"time"
-
-	"github.com/veandco/go-sdl2/img"
+	"github.com/ryanhartje/gogome/pkg/input"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 // Menu is an abstract type for presenting the user with a menu
 // It gives the developer an ability to break out of the main loop by providing a looping function of its own,
-// as well as a keymapping to help compose the menu and what menu keys will do
+// as well as a keymapping to help compose the menu and what menu actions will do
 type Menu struct {
 	BGImagePath      string
 	BGSizeX, BGSizeY int
 	// Trigger for breaking out of a menu loop
-	Break      bool
-	Components []Text
+	Break bool
 	// Cycle provides a hook for animation cycles, or otherwise
-	Cycle      int
-	Debug      bool
-	KeyMapping map[sdl.Keycode]func(*Menu)
+	Cycle int
+	Debug bool
+	// Input is the action map the menu reads, input.Default is used when it's nil
+	Input *input.Map
+	// KeyMapping maps action names, eg: input.Confirm, to what they do when pressed
+	KeyMapping map[string]func(*Menu)
+	// Widgets are what the menu is made of, each laid out over the whole window and drawn in order, see package ui
+	Widgets    []Widget
 	WinH, WinW int // Winow height and width for draw surface
+
+	// background is the texture of BGImagePath, loaded once by Load or the first Draw
+	background Texture
+}
+
+// Widget is a part of a user interface, like a button or a panel of them. Package ui has the widgets menus are made of
+type Widget interface {
+	Draw(renderer Renderer)
+	HandleEvent(event sdl.Event)
+	// Layout places the widget in area, eg: the whole window
+	Layout(area sdl.Rect)
+	Update(dt float64)
+}
+
+// capturer is a widget that's taking all input for now, like a text box being typed in,
+//   so the menu's key mapping is left alone while it is
+type capturer interface {
+	Capturing() bool
+}
+
+// freer is a widget holding on to something it can let go of while its menu isn't shown, like laid out text
+type freer interface {
+	Free()
+}
+
+// Load loads the menu's background, MenuScene does this as it's entered.
+//   A menu that isn't loaded loads its background with the renderer the first time it's drawn.
+func (menu *Menu) Load(textures TextureLoader) error {
+	menu.background = nil
+	if menu.BGImagePath == "" {
+		return nil
+	}
+	texture, err := textures.LoadTexture(menu.BGImagePath)
+	if err != nil {
+		return err
+	}
+	menu.background = texture
+	return nil
+}
+
+// Free lets go of anything the menu's widgets hold on to, like laid out text, until the menu is drawn again.
+//   The background belongs to whatever loaded it, so the menu just lets go of it.
+func (menu *Menu) Free() {
+	menu.background = nil
+	for _, widget := range menu.Widgets {
+		if f, ok := widget.(freer); ok {
+			f.Free()
+		}
+	}
 }
 
 // Draw renders the menu to the screen
-func (menu *Menu) Draw(renderer *sdl.Renderer, x, y int) {
-	image, err := img.Load(menu.BGImagePath)
-	checkErr(err)
-
-	texture, err := renderer.CreateTextureFromSurface(image)
-	checkErr(err)
-
-	renderer.Copy(
-		texture,
-		&sdl.Rect{X: 0, Y: 0, W: int32(menu.BGSizeX), H: int32(menu.BGSizeY)},
-		&sdl.Rect{X: 0, Y: 0, W: int32(menu.WinW), H: int32(menu.WinH)},
-	)
-	for _, i := range menu.Components {
-		i.Draw(renderer, x, y)
+func (menu *Menu) Draw(renderer Renderer, x, y int) {
+	// Menus without a background, like a pause menu, are drawn over whatever is beneath them
+	if menu.BGImagePath != "" {
+		if menu.background == nil {
+			checkErr(menu.Load(renderer))
+		}
+		renderer.Copy(
+			menu.background,
+			&sdl.Rect{X: 0, Y: 0, W: int32(menu.BGSizeX), H: int32(menu.BGSizeY)},
+			&sdl.Rect{X: 0, Y: 0, W: int32(menu.WinW), H: int32(menu.WinH)},
+		)
+	}
+	menu.layOut()
+	for _, widget := range menu.Widgets {
+		widget.Draw(renderer)
+	}
+}
+
+// Update lays out the menu's widgets and updates them
+func (menu *Menu) Update(dt float64) {
+	menu.layOut()
+	for _, widget := range menu.Widgets {
+		widget.Update(dt)
+	}
+}
+
+// HandleEvent passes an event on to the menu's widgets, eg: for mouse clicks and typing
+func (menu *Menu) HandleEvent(event sdl.Event) {
+	for _, widget := range menu.Widgets {
+		widget.HandleEvent(event)
+	}
+}
+
+// Capturing reports whether one of the menu's widgets is taking all input, like a text box being typed in
+func (menu *Menu) Capturing() bool {
+	for _, widget := range menu.Widgets {
+		if c, ok := widget.(capturer); ok && c.Capturing() {
+			return true
+		}
 	}
+	return false
 }
 
-// Update should bind a controller and update the menu according to user input
-func (menu *Menu) Update(x, y int) {
-	for _, component := range menu.Components {
-		component.Update(x, y)
+// layOut lays the widgets out over the window
+func (menu *Menu) layOut() {
+	area := sdl.Rect{W: int32(menu.WinW), H: int32(menu.WinH)}
+	for _, widget := range menu.Widgets {
+		widget.Layout(area)
 	}
 }
 
-// Loop iterates through a tick until the user exits the menu
-func (menu *Menu) Loop(renderer *sdl.Renderer) {
-	// setup a consistent tick rate
-	tick := time.NewTicker(time.Second / 30) // 30 FPS
-	for menu.Break == false {
-		select {
-		case <-tick.C:
-			for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-				switch t := event.(type) {
-				case *sdl.KeyboardEvent:
-					if menu.KeyMapping[t.Keysym.Sym] != nil {
-						menu.KeyMapping[t.Keysym.Sym](menu)
-					}
-				}
+// Loop runs the menu on the engine's clock until the user exits the menu
+//   ErrQuit is returned if the window was closed while the menu was up
+func (menu *Menu) Loop(e *Engine) error {
+	menu.Break = false
+	scene := NewMenuScene(menu)
+	e.Assets.WithOwner(scene, func() { scene.Enter(e) })
+	defer func() {
+		scene.Exit(e)
+		e.Assets.ReleaseOwner(scene)
+	}()
+	return e.Run(scene)
+}
+
+// MenuScene lets a Menu be used as a Scene.
+//   Breaking out of the menu pops it off the engine's scene stack, or stops the engine
+//   if the menu is being run on its own with Loop.
+type MenuScene struct {
+	Menu   *Menu
+	engine *Engine
+}
+
+// NewMenuScene wraps menu in a Scene
+func NewMenuScene(menu *Menu) *MenuScene {
+	return &MenuScene{Menu: menu}
+}
+
+// Enter resets the menu so it can be shown again, and loads its background through the engine's Assets
+func (m *MenuScene) Enter(e *Engine) {
+	m.engine = e
+	m.Menu.Break = false
+	if e.Assets != nil {
+		checkErr(m.Menu.Load(e.Assets))
+	}
+}
+
+// Exit frees what the menu's widgets hold on to, its background is released along with the scene's other assets
+func (m *MenuScene) Exit(e *Engine) {
+	m.Menu.Free()
+}
+
+// HandleEvent passes events on to the menu's widgets, the key mapping is read through actions in Update
+func (m *MenuScene) HandleEvent(event sdl.Event) {
+	m.Menu.HandleEvent(event)
+}
+
+// Update calls the menu's key mapping for any actions pressed this tick, unless a widget is taking all input,
+//   advances the menu's widgets and leaves the menu once it has been broken out of
+func (m *MenuScene) Update(dt float64) {
+	actions := m.Menu.Input
+	if actions == nil {
+		actions = input.Default
+	}
+	if !m.Menu.Capturing() {
+		for action, fn := range m.Menu.KeyMapping {
+			if actions.Pressed(action) {
+				fn(m.Menu)
 			}
+		}
+	}
 
-			menu.Draw(renderer, 0, 0)
-			menu.Update(0, 0)
-			renderer.Present()
+	m.Menu.Update(dt)
+	m.Menu.Cycle++
+	if m.Menu.Break {
+		m.Menu.Break = false
+		if m.engine.Scenes.Top() == m {
+			m.engine.Scenes.Pop(nil)
+		} else {
+			m.engine.Stop()
 		}
 	}
 }
+
+// Draw renders the menu
+func (m *MenuScene) Draw(renderer Renderer, alpha float64) {
+	m.Menu.Draw(renderer, 0, 0)
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// NineSlice draws a bordered image, like a panel or a button, at any size. Its corners are drawn as they are,
+//   its edges are stretched along their length and its middle both ways, so the border isn't distorted.
+type NineSlice struct {
+	// Left, Top, Right and Bottom are how thick the image's borders are
+	Left, Top, Right, Bottom int32
+	// Src is the part of Texture that's sliced, all of it when it's empty
+	Src     sdl.Rect
+	Texture Texture
+}
+
+// NewNineSlice slices a whole texture with the same border all the way around.
+//   It fails if the border is more than half as wide or high as the texture.
+func NewNineSlice(texture Texture, border int32) (*NineSlice, error) {
+	w, h := texture.Size()
+	return newNineSlice(texture, sdl.Rect{}, border, w, h, "texture")
+}
+
+// LoadNineSlice loads a texture with textures and slices all of it with the same border all the way around
+func LoadNineSlice(path string, textures TextureLoader, border int32) (*NineSlice, error) {
+	texture, err := textures.LoadTexture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewNineSlice(texture, border)
+}
+
+// SpriteNineSlice slices a frame of a sprite sheet, eg: a bordered sprite packed on an atlas with others
+func SpriteNineSlice(sheet *SpriteSheet, frame int, border int32) (*NineSlice, error) {
+	src, ok := sheet.Frame(frame)
+	if !ok {
+		return nil, fmt.Errorf("engine: sprite sheet has no frame %d", frame)
+	}
+	return newNineSlice(sheet.Texture, src, border, src.W, src.H, "frame")
+}
+
+// newNineSlice slices src of texture, checking the border fits in the w by h image being sliced, a texture or a frame
+func newNineSlice(texture Texture, src sdl.Rect, border, w, h int32, what string) (*NineSlice, error) {
+	if border*2 > w || border*2 > h {
+		return nil, fmt.Errorf("engine: a %d pixel border doesn't fit in a %dx%d %s", border, w, h, what)
+	}
+	return &NineSlice{Left: border, Top: border, Right: border, Bottom: border, Src: src, Texture: texture}, nil
+}
+
+// Size returns the smallest the image can be drawn without squashing its borders
+func (n *NineSlice) Size() (int32, int32) {
+	return n.Left + n.Right, n.Top + n.Bottom
+}
+
+// Draw draws the image stretched over dst. Borders are squashed to fit if dst is smaller than they are
+func (n *NineSlice) Draw(renderer Renderer, dst sdl.Rect) error {
+	if n.Texture == nil || dst.W <= 0 || dst.H <= 0 {
+		return nil
+	}
+	src := n.Src
+	if src.W <= 0 || src.H <= 0 {
+		w, h := n.Texture.Size()
+		src = sdl.Rect{W: w, H: h}
+	}
+	left, right := fitBorders(n.Left, n.Right, dst.W)
+	top, bottom := fitBorders(n.Top, n.Bottom, dst.H)
+
+	// Where the slices start and end, across then down
+	srcX := [4]int32{src.X, src.X + n.Left, src.X + src.W - n.Right, src.X + src.W}
+	srcY := [4]int32{src.Y, src.Y + n.Top, src.Y + src.H - n.Bottom, src.Y + src.H}
+	dstX := [4]int32{dst.X, dst.X + left, dst.X + dst.W - right, dst.X + dst.W}
+	dstY := [4]int32{dst.Y, dst.Y + top, dst.Y + dst.H - bottom, dst.Y + dst.H}
+	var firstErr error
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			s := sdl.Rect{X: srcX[col], Y: srcY[row], W: srcX[col+1] - srcX[col], H: srcY[row+1] - srcY[row]}
+			d := sdl.Rect{X: dstX[col], Y: dstY[row], W: dstX[col+1] - dstX[col], H: dstY[row+1] - dstY[row]}
+			if s.W <= 0 || s.H <= 0 || d.W <= 0 || d.H <= 0 {
+				continue
+			}
+			if err := renderer.Copy(n.Texture, &s, &d); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// fitBorders shrinks two borders in proportion so they fit in size together
+func fitBorders(a, b, size int32) (int32, int32) {
+	if a+b <= size || a+b == 0 {
+		return a, b
+	}
+	fitA := a * size / (a + b)
+	return fitA, size - fitA
+}
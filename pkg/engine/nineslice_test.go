@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestNineSliceBorder(t *testing.T) {
+	withFS(t, fstest.MapFS{"panel.png": {Data: testPNG(t, 10, 8)}})
+	texture := NewImageTexture(image.NewRGBA(image.Rect(0, 0, 10, 8)))
+	sheet := &SpriteSheet{Texture: texture, Frames: []sdl.Rect{{W: 10, H: 8}, {X: 2, W: 6, H: 8}}}
+
+	tests := []struct {
+		border int32
+		frame  int
+		ok     bool
+	}{
+		{0, 0, true},
+		{4, 0, true},
+		{5, 0, false},
+		{3, 1, true},
+		{4, 1, false},
+	}
+	for _, test := range tests {
+		if test.frame == 0 {
+			if _, err := NewNineSlice(texture, test.border); (err == nil) != test.ok {
+				t.Errorf("NewNineSlice of 10x8 with a %d border: err = %v, want ok %v", test.border, err, test.ok)
+			}
+			if _, err := LoadNineSlice("panel.png", NewSoftwareRenderer(1, 1), test.border); (err == nil) != test.ok {
+				t.Errorf("LoadNineSlice of 10x8 with a %d border: err = %v, want ok %v", test.border, err, test.ok)
+			}
+		}
+		if _, err := SpriteNineSlice(sheet, test.frame, test.border); (err == nil) != test.ok {
+			t.Errorf("SpriteNineSlice of frame %d with a %d border: err = %v, want ok %v", test.frame, test.border, err, test.ok)
+		}
+	}
+
+	if _, err := SpriteNineSlice(sheet, 2, 1); err == nil {
+		t.Error("SpriteNineSlice of a missing frame didn't fail")
+	}
+	if _, err := LoadNineSlice("missing.png", NewSoftwareRenderer(1, 1), 1); err == nil {
+		t.Error("LoadNineSlice of a missing image didn't fail")
+	}
+}
+
+func TestNineSliceDraw(t *testing.T) {
+	texture := NewImageTexture(image.NewRGBA(image.Rect(0, 0, 32, 32)))
+	slice := &NineSlice{Left: 2, Top: 3, Right: 4, Bottom: 5, Src: sdl.Rect{X: 8, Y: 4, W: 16, H: 20}, Texture: texture}
+
+	tests := []struct {
+		name string
+		dst  sdl.Rect
+		want [][2]sdl.Rect
+	}{
+		{"stretched", sdl.Rect{X: 10, Y: 20, W: 30, H: 40}, [][2]sdl.Rect{
+			{{X: 8, Y: 4, W: 2, H: 3}, {X: 10, Y: 20, W: 2, H: 3}},
+			{{X: 10, Y: 4, W: 10, H: 3}, {X: 12, Y: 20, W: 24, H: 3}},
+			{{X: 20, Y: 4, W: 4, H: 3}, {X: 36, Y: 20, W: 4, H: 3}},
+			{{X: 8, Y: 7, W: 2, H: 12}, {X: 10, Y: 23, W: 2, H: 32}},
+			{{X: 10, Y: 7, W: 10, H: 12}, {X: 12, Y: 23, W: 24, H: 32}},
+			{{X: 20, Y: 7, W: 4, H: 12}, {X: 36, Y: 23, W: 4, H: 32}},
+			{{X: 8, Y: 19, W: 2, H: 5}, {X: 10, Y: 55, W: 2, H: 5}},
+			{{X: 10, Y: 19, W: 10, H: 5}, {X: 12, Y: 55, W: 24, H: 5}},
+			{{X: 20, Y: 19, W: 4, H: 5}, {X: 36, Y: 55, W: 4, H: 5}},
+		}},
+		// Too narrow for the left and right borders, they're squashed in proportion and the middle column is left out
+		{"squashed", sdl.Rect{W: 3, H: 10}, [][2]sdl.Rect{
+			{{X: 8, Y: 4, W: 2, H: 3}, {W: 1, H: 3}},
+			{{X: 20, Y: 4, W: 4, H: 3}, {X: 1, W: 2, H: 3}},
+			{{X: 8, Y: 7, W: 2, H: 12}, {Y: 3, W: 1, H: 2}},
+			{{X: 20, Y: 7, W: 4, H: 12}, {X: 1, Y: 3, W: 2, H: 2}},
+			{{X: 8, Y: 19, W: 2, H: 5}, {Y: 5, W: 1, H: 5}},
+			{{X: 20, Y: 19, W: 4, H: 5}, {X: 1, Y: 5, W: 2, H: 5}},
+		}},
+		{"empty", sdl.Rect{X: 5, Y: 5}, nil},
+	}
+	for _, test := range tests {
+		r := NewSoftwareRenderer(64, 64)
+		if err := slice.Draw(r, test.dst); err != nil {
+			t.Errorf("%s: Draw failed: %v", test.name, err)
+		}
+		var got [][2]sdl.Rect
+		for _, call := range r.Calls {
+			if call.Op != "Copy" || call.Texture != texture {
+				t.Errorf("%s: drew %s of %v, want copies of the slice's texture", test.name, call.Op, call.Texture)
+			}
+			got = append(got, [2]sdl.Rect{call.Src, call.Dst})
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: drew src, dst %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// Without a Src the whole texture is sliced
+	whole, err := NewNineSlice(texture, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewSoftwareRenderer(64, 64)
+	whole.Draw(r, sdl.Rect{W: 64, H: 64})
+	if len(r.Calls) != 9 {
+		t.Fatalf("drew %d slices of the whole texture, want 9", len(r.Calls))
+	}
+	if got, want := r.Calls[8].Src, (sdl.Rect{X: 24, Y: 24, W: 8, H: 8}); got != want {
+		t.Errorf("bottom right corner cut from %v, want %v", got, want)
+	}
+	if got, want := r.Calls[4].Dst, (sdl.Rect{X: 8, Y: 8, W: 48, H: 48}); got != want {
+		t.Errorf("middle drawn at %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import "github.com/ryanhartje/gogome/pkg/physics"
+
+// Physics steps a physics world and moves the entities that have opted into it along with their bodies
+type Physics struct {
+	World *physics.World
+
+	entities map[*physics.Body]Entity
+}
+
+// NewPhysics creates a physics world with the given gravity, in pixels per second per second.
+//   Use a gravity of 0, 0 for top down games.
+func NewPhysics(gravity physics.Vec) *Physics {
+	return &Physics{
+		World:    physics.NewWorld(gravity),
+		entities: map[*physics.Body]Entity{},
+	}
+}
+
+// Add opts an entity into physics. The body starts wherever the entity is, and from then on
+//   the entity is moved to wherever the body is after each update.
+func (p *Physics) Add(entity Entity, body *physics.Body) {
+	x, y := entity.GetLevelCoords()
+	body.Position = physics.Vec{X: float64(x), Y: float64(y)}
+	if body.Data == nil {
+		body.Data = entity
+	}
+	p.entities[body] = entity
+	p.World.Add(body)
+}
+
+// Remove takes an entity's body out of the physics world
+func (p *Physics) Remove(body *physics.Body) {
+	delete(p.entities, body)
+	p.World.Remove(body)
+}
+
+// Update advances the physics world by dt seconds and moves each entity to its body
+func (p *Physics) Update(dt float64) {
+	p.World.Update(dt)
+	for body, entity := range p.entities {
+		entity.SetX(body.Position.X)
+		entity.SetY(body.Position.Y)
+	}
+}
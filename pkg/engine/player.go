@@ -2,8 +2,10 @@ package engine
 
 import (
 	"fmt"
+	"math"
 
-	"github.com/veandco/go-sdl2/img"
+	"github.com/ryanhartje/gogome/pkg/input"
+	"github.com/ryanhartje/gogome/pkg/physics"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
@@ -11,169 +13,186 @@ var speed = 4
 
 // Player holds all things relevant to make the Player model self sufficient.
 type Player struct {
-	Debug bool
-	// Effects are a slice of funcs called at Update() in order to programatically mutate the entity
+	// Anim plays the player's walk_<direction> and stand_<direction> clips for each of up, down, left and right,
+	//   and fall for effects to use
+	Anim *Animator
+	// Body, when set, moves the player through physics instead of Move. See Physics.Add
+	Body *physics.Body
+	// Bounds is the area of the world the player is kept inside of, a zero sized rect leaves them free to roam
+	Bounds sdl.Rect
+	// Collider stops the player walking through walls, usually the level they're in. nil lets them walk anywhere
+	Collider Collider
+	// Contact is what the player ran into on their last move
+	Contact Contact
+	Debug   bool
+	// Effects are a slice of funcs called at Update() in order to programatically mutate the entity.
+	//   They mustn't draw, the frame is cleared before it's drawn. Use Overlays for that.
 	Effects []func(*Player)
-	// Frame tracks what Frame of the player animation we're on
-	Frame          int32
-	FrameLimit     int32
-	LevelX, LevelY int
-	// store the Renderer pointer so we can render through a method
-	Renderer *sdl.Renderer
-	// size x and y pertain to what the standard size of the player is
+	// Input is the action map the player is controlled by, input.Default is used when it's nil.
+	//   Give each player their own map for local multiplayer.
+	Input *input.Map
+	// JumpSpeed is how fast, in pixels per second, a player with a Body leaves the ground when they move up.
+	//   When it's 0, the player is top down and moves up and down like they do left and right.
+	JumpSpeed float64
+	// Overlays are called at Draw(), after the sprite, to draw over the player as seen through camera.
+	//   Draw runs at the frame rate rather than the tick rate, so they mustn't use Rand or replays will drift.
+	Overlays []func(player *Player, renderer Renderer, camera *Camera)
+	// SizeX and SizeY are the size the player is drawn at and collides as, twice the size of their sprite
 	SizeX, SizeY int32
-	// The player sprites are chunked into 16x32 Frames
-	// SpriteXPos and SpriteYPos is a Frame reference eg: [0, 1, 2, 3] for 4 Frames of animation
-	SpriteXPos, SpriteYPos int32
-	// Texture holds the bitmap used to render the Player
-	Texture *sdl.Texture
-	// x and y are the x and y coordinates for the Player
+	// WalkSpeed is how fast, in pixels per second, a player with a Body walks. Sprinting doubles it
+	WalkSpeed float64
+	// x and y are the world coordinates of the Player
 	X, Y float64
+
+	// facing is the direction the player last moved in
+	facing string
 }
 
-// NewPlayer is a Player factory that sets it's defaults and returns it
-func NewPlayer(Renderer *sdl.Renderer, spritepath string) (*Player, error) {
-	Texture, err := img.LoadTexture(Renderer, spritepath)
-	checkErr(err)
-
-	return &Player{
-		Frame:      0,
-		FrameLimit: 3,
-		Renderer:   Renderer,
-		SizeX:      16,
-		SizeY:      32,
-		SpriteXPos: 1,
-		SpriteYPos: 1,
-		Texture:    Texture,
+// NewPlayer is a Player factory that sets it's defaults and returns it.
+//   spritepath is the player's sprite sheet, exported from Aseprite as JSON with a tag for each of Anim's clips,
+//   its texture is loaded with textures, eg: the engine's Assets
+func NewPlayer(textures TextureLoader, spritepath string) (*Player, error) {
+	anim, err := LoadAseprite(spritepath, textures)
+	if err != nil {
+		return &Player{}, err
+	}
+
+	player := &Player{
+		Anim:      anim,
+		SizeX:     32,
+		SizeY:     64,
+		WalkSpeed: 64,
 		// Place the user in the middle of the screen, assuming 800x600 minus half the Sprite size
-		X: 396.0,
-		Y: 272.0,
-	}, nil
+		X:      396.0,
+		Y:      272.0,
+		facing: "right",
+	}
+	player.Anim.Play("stand_right")
+	return player, nil
 }
 
-// Draw render's the Player Sprite to the screen
-func (player *Player) Draw(renderer *sdl.Renderer, levelX int, levelY int) {
-	renderer.Copy(
-		player.Texture,
-		&sdl.Rect{X: player.SpriteXPos * 16, Y: player.SpriteYPos * 32, W: 16, H: 32},
-		&sdl.Rect{X: int32(player.X), Y: int32(player.Y), W: 32, H: 64},
-	)
+// Draw render's the Player Sprite to the screen, as seen through camera, then their Overlays
+func (player *Player) Draw(renderer Renderer, camera *Camera) {
+	player.Anim.Draw(renderer, camera.WorldRect(player.X, player.Y, float64(player.SizeX), float64(player.SizeY)), 0, sdl.FLIP_NONE)
+	for _, overlay := range player.Overlays {
+		overlay(player, renderer, camera)
+	}
+}
+
+// Submit queues the Player Sprite to be drawn, sorted by where their feet are, with their Overlays drawn over it
+func (player *Player) Submit(queue *RenderQueue, camera *Camera) {
+	feet := player.Y + float64(player.SizeY)
+	player.Anim.Submit(queue, LayerEntities, feet, *camera.WorldRect(player.X, player.Y, float64(player.SizeX), float64(player.SizeY)), 0, sdl.FLIP_NONE)
+	for _, overlay := range player.Overlays {
+		overlay := overlay
+		queue.SubmitFunc(LayerEntities, feet, func(renderer Renderer) {
+			overlay(player, renderer, camera)
+		})
+	}
 }
 
 // GetLevelCoords satisfies the entity interface
 func (player *Player) GetLevelCoords() (int, int) {
-	return player.LevelX, player.LevelY
+	return int(player.X), int(player.Y)
 }
 
-// Update checks for keystrokes and calls the appropriate method based on the user input
-func (player *Player) Update(levelX int, levelY int) {
-	for _, effect := range player.Effects {
-		effect(player)
+// Update checks the movement actions and calls the appropriate method based on the user input,
+//   then advances the player's animation by dt seconds
+func (player *Player) Update(dt float64) {
+	actions := player.Input
+	if actions == nil {
+		actions = input.Default
 	}
-
-	keys := sdl.GetKeyboardState()
-	moving := false
-	// UP
-	if keys[sdl.SCANCODE_W] == 1 {
-		player.Move(0, -1)
-		moving = true
+	// Movement is analog, so a stick pushed halfway moves the player at half speed.
+	//   Keys and buttons are always all the way on.
+	x := actions.Value(input.MoveRight) - actions.Value(input.MoveLeft)
+	y := actions.Value(input.MoveDown) - actions.Value(input.MoveUp)
+	moving := x != 0 || y != 0
+	if player.Body != nil {
+		player.steer(x, y)
 	}
-	// DOWN
-	if keys[sdl.SCANCODE_S] == 1 {
-		player.Move(0, 1)
-		moving = true
-	}
-	// LEFT
-	if keys[sdl.SCANCODE_A] == 1 {
-		player.Move(-1, 0)
-		moving = true
+	if x != 0 {
+		player.Move(x, 0)
 	}
-	// RIGHT
-	if keys[sdl.SCANCODE_D] == 1 {
-		player.Move(1, 0)
-		moving = true
+	if y != 0 {
+		player.Move(0, y)
 	}
 
-	if keys[sdl.SCANCODE_LSHIFT] == 1 {
+	if actions.Held(input.Sprint) {
 		speed = 8
 	} else {
 		speed = 4
 	}
 
-	player.LevelX = levelX
-	player.LevelY = levelY
 	if moving {
 		if player.Debug {
-			fmt.Printf("player level coords: %d,%d\n", player.LevelX, player.LevelY)
+			fmt.Printf("player level coords: %v,%v\n", player.X, player.Y)
 		}
 	}
 
-	// If we've stopped moving, reset our animation to our still Frame
-	// TODO - refactor this for standing animations
+	// If we've stopped moving, stand still facing the way we were going
 	if !moving {
-		player.Frame = 0
+		player.Anim.Play("stand_" + player.facing)
 	}
 
+	// Effects run after movement, so they can override the animation movement chose
+	for _, effect := range player.Effects {
+		effect(player)
+	}
+	player.Anim.Update(dt)
 }
 
-// Move adjusts the players coordinates so that its rendered correctly on the next frame
-func (player *Player) Move(x float64, y float64) {
-	nextXStep := x * float64(speed)
-	leftBound := player.X+nextXStep < float64(speed)
-	rightBound := player.X+nextXStep > float64(winW-16)
-	// Don't let player move beyond bounds, but DO update their animation
-	if !leftBound && !rightBound {
-		player.X += x * float64(speed)
-	} else {
-		if leftBound {
-			player.X = float64(speed)
-		}
-		if rightBound {
-			player.X = float64(winW) - float64(speed)
-		}
+// steer sets the velocity of the player's body from how far the movement actions are pushed
+func (player *Player) steer(x float64, y float64) {
+	body := player.Body
+	walk := player.WalkSpeed * float64(speed) / 4
+	if player.JumpSpeed == 0 {
+		body.Velocity = physics.Vec{X: x * walk, Y: y * walk}
+		return
 	}
-	if player.Y >= 0 && player.Y <= float64(winH)-32 {
-		player.Y += y * float64(speed)
-	} else {
-		if player.Y <= 0 {
-			player.Y = 0
-		}
-		if player.Y >= float64(winH)-32 {
-			player.Y = float64(winH) - 32
-		}
+	// In the air, let go of the controls and the player keeps their momentum, so knockback carries them
+	if x != 0 || body.OnGround {
+		body.Velocity.X = x * walk
 	}
-	player.Frame++
-	// If we've iterated past our number of Frames, reset to 0
-	if player.Frame > player.FrameLimit {
-		player.Frame = 0
+	if y < 0 && body.OnGround {
+		body.Velocity.Y = -player.JumpSpeed
 	}
+}
 
-	// If x is the coordinate that we're changing, set the Frame animation.
-	//   Our original player Sprite has it's right and left facing Sprites in the
-	//   1st and 3rd Y positions, so use those respectively
-	//   Repeat this logic for movements along the Y axis, setting their Y positions in the
-	//   Sprite sheet.
-	if x != 0 {
-		if x > 0 {
-			player.SpriteYPos = 1
-		} else {
-			player.SpriteYPos = 3
-		}
-		player.SpriteXPos++
-		if player.SpriteXPos > player.FrameLimit {
-			player.SpriteXPos = 0
-		}
+// Move adjusts the players coordinates so that its rendered correctly on the next frame.
+//   A player with a Body is moved by physics, so Move only animates them.
+func (player *Player) Move(x float64, y float64) {
+	if player.Body == nil {
+		player.step(x, y)
 	}
-	if y != 0 {
-		if y > 0 {
-			player.SpriteYPos = 0
-		} else {
-			player.SpriteYPos = 2
-		}
-		player.SpriteXPos++
-		if player.SpriteXPos > player.FrameLimit {
-			player.SpriteXPos = 0
-		}
+
+	// Face whichever way we're moving and walk that way, moving along the Y axis takes precedence
+	switch {
+	case y > 0:
+		player.facing = "down"
+	case y < 0:
+		player.facing = "up"
+	case x > 0:
+		player.facing = "right"
+	case x < 0:
+		player.facing = "left"
+	}
+	player.Anim.Play("walk_" + player.facing)
+}
+
+// step moves the player x, y steps, sliding along walls and staying inside their bounds
+func (player *Player) step(x float64, y float64) {
+	w, h := float64(player.SizeX), float64(player.SizeY)
+	if player.Collider != nil {
+		player.X, player.Y, player.Contact = player.Collider.Resolve(player.X, player.Y, w, h, x*float64(speed), y*float64(speed))
+	} else {
+		player.X += x * float64(speed)
+		player.Y += y * float64(speed)
+	}
+	// Don't let player move beyond bounds
+	if player.Bounds.W > 0 && player.Bounds.H > 0 {
+		player.X = math.Max(float64(player.Bounds.X), math.Min(player.X, float64(player.Bounds.X+player.Bounds.W)-w))
+		player.Y = math.Max(float64(player.Bounds.Y), math.Min(player.Y, float64(player.Bounds.Y+player.Bounds.H)-h))
 	}
 }
 
@@ -187,7 +206,7 @@ func (player *Player) SetY(y float64) {
 	player.Y = y
 }
 
-// Size returns x and y values of the sprite's size
+// Size returns the size the player is drawn at and collides as
 func (player *Player) Size() (int32, int32) {
 	return player.SizeX, player.SizeY
 }
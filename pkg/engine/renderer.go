@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Texture is an image a Renderer can draw. Textures should only be drawn by the Renderer that created them
+type Texture interface {
+	// Size returns the width and height of the texture in pixels
+	Size() (int32, int32)
+	SetAlphaMod(alpha uint8) error
+	SetColorMod(r, g, b uint8) error
+	Destroy() error
+}
+
+// Renderer abstracts everything the engine draws with, so the same game code can draw
+//   to an SDL window or to memory when testing with SoftwareRenderer.
+//   Its methods mirror sdl.Renderer, and the gfx primitives we use.
+type Renderer interface {
+	Clear() error
+	Copy(texture Texture, src, dst *sdl.Rect) error
+	CopyEx(texture Texture, src, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) error
+	Destroy() error
+	DrawRect(rect *sdl.Rect) error
+	FillRect(rect *sdl.Rect) error
+	GetOutputSize() (int32, int32, error)
+	Present()
+	SetDrawBlendMode(bm sdl.BlendMode) error
+	SetDrawColor(r, g, b, a uint8) error
+	SetViewport(rect *sdl.Rect) error
+
+	// Box, Line and RoundedBox draw the gfx primitives of the same names
+	Box(x1, y1, x2, y2 int32, color sdl.Color)
+	Line(x1, y1, x2, y2 int32, color sdl.Color)
+	RoundedBox(x1, y1, x2, y2, rad int32, color sdl.Color)
+
+	// CreateTextureFromSurface and LoadTexture create textures this renderer can draw
+	CreateTextureFromSurface(surface *sdl.Surface) (Texture, error)
+	LoadTexture(path string) (Texture, error)
+}
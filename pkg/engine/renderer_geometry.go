@@ -0,0 +1,47 @@
+//go:build sdl_geometry
+// +build sdl_geometry
+
+package engine
+
+// #cgo windows LDFLAGS: -lSDL2
+// #cgo linux freebsd darwin openbsd pkg-config: sdl2
+// #include <SDL.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// RenderGeometry draws textured triangles with SDL_RenderGeometry, texture may be nil for plain colored triangles.
+//   It's only built with the sdl_geometry tag, since go-sdl2 doesn't bind it and it needs SDL 2.0.18 or later.
+func (r *SDLRenderer) RenderGeometry(texture Texture, vertices []Vertex, indices []int32) error {
+	if len(vertices) == 0 {
+		return nil
+	}
+	var tex *C.SDL_Texture
+	if texture != nil {
+		t, ok := texture.(*SDLTexture)
+		if !ok {
+			return errForeignTexture
+		}
+		tex = (*C.SDL_Texture)(unsafe.Pointer(t.Texture))
+	}
+	var idx *C.int
+	if len(indices) > 0 {
+		idx = (*C.int)(unsafe.Pointer(&indices[0]))
+	}
+	// Vertex is laid out like an SDL_Vertex, so the slice can be handed over as is
+	if C.SDL_RenderGeometry(
+		(*C.SDL_Renderer)(unsafe.Pointer(r.Renderer)),
+		tex,
+		(*C.SDL_Vertex)(unsafe.Pointer(&vertices[0])),
+		C.int(len(vertices)),
+		idx,
+		C.int(len(indices)),
+	) != 0 {
+		return sdl.GetError()
+	}
+	return nil
+}
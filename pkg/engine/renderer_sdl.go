@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/veandco/go-sdl2/gfx"
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// errForeignTexture is returned when a texture created by another kind of renderer is drawn
+var errForeignTexture = errors.New("engine: texture wasn't created by this renderer")
+
+// SDLRenderer draws to an SDL window. The underlying sdl.Renderer is embedded for anything
+//   the Renderer interface doesn't cover.
+type SDLRenderer struct {
+	*sdl.Renderer
+}
+
+// SDLTexture is a texture created by an SDLRenderer
+type SDLTexture struct {
+	*sdl.Texture
+	W, H int32
+}
+
+// NewSDLRenderer wraps an sdl.Renderer so the engine can draw with it
+func NewSDLRenderer(renderer *sdl.Renderer) *SDLRenderer {
+	return &SDLRenderer{Renderer: renderer}
+}
+
+// NewSDLTexture wraps an sdl.Texture so the engine can draw it
+func NewSDLTexture(texture *sdl.Texture) (*SDLTexture, error) {
+	_, _, w, h, err := texture.Query()
+	if err != nil {
+		return nil, err
+	}
+	return &SDLTexture{Texture: texture, W: w, H: h}, nil
+}
+
+// Size returns the width and height of the texture
+func (t *SDLTexture) Size() (int32, int32) {
+	return t.W, t.H
+}
+
+// replace takes on a freshly loaded texture in place, keeping its alpha and blend mode
+func (t *SDLTexture) replace(fresh Texture) bool {
+	n, ok := fresh.(*SDLTexture)
+	if !ok {
+		return false
+	}
+	if alpha, err := t.Texture.GetAlphaMod(); err == nil {
+		n.Texture.SetAlphaMod(alpha)
+	}
+	if bm, err := t.Texture.GetBlendMode(); err == nil {
+		n.Texture.SetBlendMode(bm)
+	}
+	t.Texture.Destroy()
+	*t = *n
+	return true
+}
+
+// Copy draws all or part of a texture to the screen
+func (r *SDLRenderer) Copy(texture Texture, src, dst *sdl.Rect) error {
+	t, ok := texture.(*SDLTexture)
+	if !ok {
+		return errForeignTexture
+	}
+	return r.Renderer.Copy(t.Texture, src, dst)
+}
+
+// CopyEx draws all or part of a texture to the screen, rotated and flipped
+func (r *SDLRenderer) CopyEx(texture Texture, src, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) error {
+	t, ok := texture.(*SDLTexture)
+	if !ok {
+		return errForeignTexture
+	}
+	return r.Renderer.CopyEx(t.Texture, src, dst, angle, center, flip)
+}
+
+// Box draws a filled box
+func (r *SDLRenderer) Box(x1, y1, x2, y2 int32, color sdl.Color) {
+	gfx.BoxColor(r.Renderer, x1, y1, x2, y2, color)
+}
+
+// Line draws a line
+func (r *SDLRenderer) Line(x1, y1, x2, y2 int32, color sdl.Color) {
+	gfx.LineColor(r.Renderer, x1, y1, x2, y2, color)
+}
+
+// RoundedBox draws a filled box with rounded corners
+func (r *SDLRenderer) RoundedBox(x1, y1, x2, y2, rad int32, color sdl.Color) {
+	gfx.RoundedBoxColor(r.Renderer, x1, y1, x2, y2, rad, color)
+}
+
+// CreateTextureFromSurface uploads a surface to the GPU
+func (r *SDLRenderer) CreateTextureFromSurface(surface *sdl.Surface) (Texture, error) {
+	texture, err := r.Renderer.CreateTextureFromSurface(surface)
+	if err != nil {
+		return nil, err
+	}
+	return NewSDLTexture(texture)
+}
+
+// LoadTexture loads an image file from FS, in any format SDL_image supports, into a texture
+func (r *SDLRenderer) LoadTexture(path string) (Texture, error) {
+	rw, data, err := OpenRW(path)
+	if err != nil {
+		return nil, err
+	}
+	texture, err := img.LoadTextureRW(r.Renderer, rw, true)
+	runtime.KeepAlive(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewSDLTexture(texture)
+}
@@ -0,0 +1,456 @@
+package engine
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	// Register the decoders LoadTexture can use without SDL_image
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SoftwareRenderer draws into an in memory image instead of a window, and records every call made to it.
+//   It needs no display, so it's handy for testing what a frame looks like, eg: comparing Image()
+//   against a golden PNG.
+//   Rotation passed to CopyEx is recorded but not rasterized.
+type SoftwareRenderer struct {
+	// Calls are the draw calls made since the last Clear
+	Calls []DrawCall
+	// Frames counts how many times Present has been called
+	Frames int
+
+	blend       sdl.BlendMode
+	color       sdl.Color
+	framebuffer *image.RGBA
+	viewport    *sdl.Rect
+}
+
+// DrawCall is a record of a single call to a SoftwareRenderer
+type DrawCall struct {
+	// Op is the name of the method called, eg: "Copy" or "Line"
+	Op      string
+	Angle   float64
+	Color   sdl.Color
+	Dst     sdl.Rect
+	Flip    sdl.RendererFlip
+	Src     sdl.Rect
+	Texture Texture
+}
+
+// ImageTexture is a texture created by a SoftwareRenderer
+type ImageTexture struct {
+	Image *image.RGBA
+
+	alpha     uint8
+	colorMod  [3]uint8
+	destroyed bool
+}
+
+// NewSoftwareRenderer creates a renderer that draws into a w by h image
+func NewSoftwareRenderer(w, h int) *SoftwareRenderer {
+	return &SoftwareRenderer{
+		blend:       sdl.BLENDMODE_NONE,
+		color:       sdl.Color{R: 0, G: 0, B: 0, A: 255},
+		framebuffer: image.NewRGBA(image.Rect(0, 0, w, h)),
+	}
+}
+
+// NewImageTexture copies any image into a texture a SoftwareRenderer can draw
+func NewImageTexture(src image.Image) *ImageTexture {
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	return &ImageTexture{
+		Image:    rgba,
+		alpha:    255,
+		colorMod: [3]uint8{255, 255, 255},
+	}
+}
+
+// Size returns the width and height of the texture
+func (t *ImageTexture) Size() (int32, int32) {
+	return int32(t.Image.Rect.Dx()), int32(t.Image.Rect.Dy())
+}
+
+// SetAlphaMod sets how opaque the texture is drawn
+func (t *ImageTexture) SetAlphaMod(alpha uint8) error {
+	t.alpha = alpha
+	return nil
+}
+
+// SetColorMod sets a color the texture is multiplied by when drawn
+func (t *ImageTexture) SetColorMod(r, g, b uint8) error {
+	t.colorMod = [3]uint8{r, g, b}
+	return nil
+}
+
+// Destroy marks the texture as destroyed, drawing it afterwards is a no-op
+func (t *ImageTexture) Destroy() error {
+	t.destroyed = true
+	return nil
+}
+
+// replace takes on the pixels of a freshly loaded texture in place
+func (t *ImageTexture) replace(fresh Texture) bool {
+	n, ok := fresh.(*ImageTexture)
+	if !ok {
+		return false
+	}
+	t.Image = n.Image
+	t.destroyed = false
+	return true
+}
+
+// Image returns the framebuffer the renderer has been drawing to
+func (r *SoftwareRenderer) Image() *image.RGBA {
+	return r.framebuffer
+}
+
+// record notes a call made to the renderer
+func (r *SoftwareRenderer) record(call DrawCall) {
+	r.Calls = append(r.Calls, call)
+}
+
+// Clear fills the framebuffer with the draw color and forgets the calls made so far
+func (r *SoftwareRenderer) Clear() error {
+	r.Calls = nil
+	draw.Draw(r.framebuffer, r.framebuffer.Bounds(), image.NewUniform(toRGBA(r.color)), image.Point{}, draw.Src)
+	return nil
+}
+
+// Copy draws all or part of a texture, scaled with nearest neighbor sampling
+func (r *SoftwareRenderer) Copy(texture Texture, src, dst *sdl.Rect) error {
+	return r.copy("Copy", texture, src, dst, 0, sdl.FLIP_NONE)
+}
+
+// CopyEx draws all or part of a texture, flipped
+func (r *SoftwareRenderer) CopyEx(texture Texture, src, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) error {
+	return r.copy("CopyEx", texture, src, dst, angle, flip)
+}
+
+// copy does the work for Copy and CopyEx
+func (r *SoftwareRenderer) copy(op string, texture Texture, src, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	t, ok := texture.(*ImageTexture)
+	if !ok {
+		return errForeignTexture
+	}
+	if src == nil {
+		w, h := t.Size()
+		src = &sdl.Rect{W: w, H: h}
+	}
+	if dst == nil {
+		view := r.view()
+		dst = &sdl.Rect{W: view.W, H: view.H}
+	}
+	r.record(DrawCall{Op: op, Angle: angle, Dst: *dst, Flip: flip, Src: *src, Texture: texture})
+	if t.destroyed || src.W <= 0 || src.H <= 0 || dst.W <= 0 || dst.H <= 0 {
+		return nil
+	}
+
+	view := r.view()
+	for y := int32(0); y < dst.H; y++ {
+		for x := int32(0); x < dst.W; x++ {
+			sx := x * src.W / dst.W
+			sy := y * src.H / dst.H
+			if flip&sdl.FLIP_HORIZONTAL != 0 {
+				sx = src.W - 1 - sx
+			}
+			if flip&sdl.FLIP_VERTICAL != 0 {
+				sy = src.H - 1 - sy
+			}
+			c := t.Image.RGBAAt(int(src.X+sx), int(src.Y+sy))
+			c.R = uint8(uint16(c.R) * uint16(t.colorMod[0]) / 255)
+			c.G = uint8(uint16(c.G) * uint16(t.colorMod[1]) / 255)
+			c.B = uint8(uint16(c.B) * uint16(t.colorMod[2]) / 255)
+			// Pixels are premultiplied, so the alpha mod scales every channel
+			c.R = uint8(uint16(c.R) * uint16(t.alpha) / 255)
+			c.G = uint8(uint16(c.G) * uint16(t.alpha) / 255)
+			c.B = uint8(uint16(c.B) * uint16(t.alpha) / 255)
+			c.A = uint8(uint16(c.A) * uint16(t.alpha) / 255)
+			r.plot(view, dst.X+x, dst.Y+y, c, sdl.BLENDMODE_BLEND)
+		}
+	}
+	return nil
+}
+
+// Destroy satisfies the Renderer interface
+func (r *SoftwareRenderer) Destroy() error {
+	return nil
+}
+
+// DrawRect outlines a rectangle in the draw color
+func (r *SoftwareRenderer) DrawRect(rect *sdl.Rect) error {
+	if rect == nil {
+		view := r.view()
+		rect = &sdl.Rect{W: view.W, H: view.H}
+	}
+	r.record(DrawCall{Op: "DrawRect", Color: r.color, Dst: *rect})
+	view := r.view()
+	c := toRGBA(r.color)
+	for x := rect.X; x < rect.X+rect.W; x++ {
+		r.plot(view, x, rect.Y, c, r.blend)
+		r.plot(view, x, rect.Y+rect.H-1, c, r.blend)
+	}
+	for y := rect.Y + 1; y < rect.Y+rect.H-1; y++ {
+		r.plot(view, rect.X, y, c, r.blend)
+		r.plot(view, rect.X+rect.W-1, y, c, r.blend)
+	}
+	return nil
+}
+
+// FillRect fills a rectangle with the draw color
+func (r *SoftwareRenderer) FillRect(rect *sdl.Rect) error {
+	if rect == nil {
+		view := r.view()
+		rect = &sdl.Rect{W: view.W, H: view.H}
+	}
+	r.record(DrawCall{Op: "FillRect", Color: r.color, Dst: *rect})
+	r.fill(rect.X, rect.Y, rect.X+rect.W-1, rect.Y+rect.H-1, 0, toRGBA(r.color), r.blend)
+	return nil
+}
+
+// GetOutputSize returns the size of the framebuffer
+func (r *SoftwareRenderer) GetOutputSize() (int32, int32, error) {
+	bounds := r.framebuffer.Bounds()
+	return int32(bounds.Dx()), int32(bounds.Dy()), nil
+}
+
+// Present counts the frame, the framebuffer is left as is so it can be inspected
+func (r *SoftwareRenderer) Present() {
+	r.Frames++
+}
+
+// SetDrawBlendMode sets how FillRect and DrawRect blend with what's already drawn
+func (r *SoftwareRenderer) SetDrawBlendMode(bm sdl.BlendMode) error {
+	r.blend = bm
+	return nil
+}
+
+// SetDrawColor sets the color used by Clear, FillRect and DrawRect
+func (r *SoftwareRenderer) SetDrawColor(red, green, blue, alpha uint8) error {
+	r.color = sdl.Color{R: red, G: green, B: blue, A: alpha}
+	return nil
+}
+
+// SetViewport sets the area drawing happens in, coordinates are relative to its top left. nil resets it
+func (r *SoftwareRenderer) SetViewport(rect *sdl.Rect) error {
+	if rect == nil {
+		r.viewport = nil
+		return nil
+	}
+	viewport := *rect
+	r.viewport = &viewport
+	return nil
+}
+
+// Box draws a filled box, blended like the gfx primitives are
+func (r *SoftwareRenderer) Box(x1, y1, x2, y2 int32, color sdl.Color) {
+	r.record(DrawCall{Op: "Box", Color: color, Dst: pointsRect(x1, y1, x2, y2)})
+	r.fill(x1, y1, x2, y2, 0, toRGBA(color), sdl.BLENDMODE_BLEND)
+}
+
+// Line draws a line using Bresenham's algorithm
+func (r *SoftwareRenderer) Line(x1, y1, x2, y2 int32, color sdl.Color) {
+	r.record(DrawCall{Op: "Line", Color: color, Dst: pointsRect(x1, y1, x2, y2)})
+	view := r.view()
+	c := toRGBA(color)
+	dx := abs32(x2 - x1)
+	dy := -abs32(y2 - y1)
+	sx, sy := int32(1), int32(1)
+	if x1 > x2 {
+		sx = -1
+	}
+	if y1 > y2 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		r.plot(view, x1, y1, c, sdl.BLENDMODE_BLEND)
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x1 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y1 += sy
+		}
+	}
+}
+
+// RoundedBox draws a filled box with rounded corners
+func (r *SoftwareRenderer) RoundedBox(x1, y1, x2, y2, rad int32, color sdl.Color) {
+	r.record(DrawCall{Op: "RoundedBox", Color: color, Dst: pointsRect(x1, y1, x2, y2)})
+	r.fill(x1, y1, x2, y2, rad, toRGBA(color), sdl.BLENDMODE_BLEND)
+}
+
+// CreateTextureFromSurface copies a surface's pixels into a texture
+func (r *SoftwareRenderer) CreateTextureFromSurface(surface *sdl.Surface) (Texture, error) {
+	converted, err := surface.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer converted.Free()
+
+	rgba := image.NewRGBA(image.Rect(0, 0, int(converted.W), int(converted.H)))
+	pixels := converted.Pixels()
+	for y := 0; y < int(converted.H); y++ {
+		row := pixels[y*int(converted.Pitch):]
+		copy(rgba.Pix[y*rgba.Stride:(y+1)*rgba.Stride], row[:rgba.Stride])
+	}
+	// SDL surfaces aren't premultiplied, image.RGBA is
+	for i := 0; i < len(rgba.Pix); i += 4 {
+		a := uint16(rgba.Pix[i+3])
+		rgba.Pix[i] = uint8(uint16(rgba.Pix[i]) * a / 255)
+		rgba.Pix[i+1] = uint8(uint16(rgba.Pix[i+1]) * a / 255)
+		rgba.Pix[i+2] = uint8(uint16(rgba.Pix[i+2]) * a / 255)
+	}
+	return NewImageTexture(rgba), nil
+}
+
+// LoadTexture decodes an image file from FS into a texture. PNG, JPEG and GIF are decoded in Go,
+//   anything else (eg: BMP) falls back to SDL_image
+func (r *SoftwareRenderer) LoadTexture(path string) (Texture, error) {
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err == nil {
+		return NewImageTexture(decoded), nil
+	}
+	if err != image.ErrFormat {
+		return nil, err
+	}
+
+	surface, err := LoadSurface(path)
+	if err != nil {
+		return nil, err
+	}
+	defer surface.Free()
+	return r.CreateTextureFromSurface(surface)
+}
+
+// view returns the area currently being drawn to
+func (r *SoftwareRenderer) view() sdl.Rect {
+	if r.viewport != nil {
+		return *r.viewport
+	}
+	bounds := r.framebuffer.Bounds()
+	return sdl.Rect{W: int32(bounds.Dx()), H: int32(bounds.Dy())}
+}
+
+// fill fills the box between two corners, skipping pixels outside of rounded corners when rad > 0
+func (r *SoftwareRenderer) fill(x1, y1, x2, y2, rad int32, c color.RGBA, bm sdl.BlendMode) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	view := r.view()
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			if rad > 0 && !insideRounded(x, y, x1, y1, x2, y2, rad) {
+				continue
+			}
+			r.plot(view, x, y, c, bm)
+		}
+	}
+}
+
+// plot blends a single pixel, given in viewport coordinates, into the framebuffer.
+//   Like image.RGBA, every color here is alpha premultiplied.
+func (r *SoftwareRenderer) plot(view sdl.Rect, x, y int32, c color.RGBA, bm sdl.BlendMode) {
+	if x < 0 || y < 0 || x >= view.W || y >= view.H {
+		return
+	}
+	px, py := int(view.X+x), int(view.Y+y)
+	if !(image.Point{X: px, Y: py}).In(r.framebuffer.Rect) {
+		return
+	}
+
+	dst := r.framebuffer.RGBAAt(px, py)
+	switch bm {
+	case sdl.BLENDMODE_BLEND:
+		inverse := 255 - uint16(c.A)
+		dst.R = c.R + uint8(uint16(dst.R)*inverse/255)
+		dst.G = c.G + uint8(uint16(dst.G)*inverse/255)
+		dst.B = c.B + uint8(uint16(dst.B)*inverse/255)
+		dst.A = c.A + uint8(uint16(dst.A)*inverse/255)
+	case sdl.BLENDMODE_ADD:
+		dst.R = uint8(min16(uint16(dst.R)+uint16(c.R), 255))
+		dst.G = uint8(min16(uint16(dst.G)+uint16(c.G), 255))
+		dst.B = uint8(min16(uint16(dst.B)+uint16(c.B), 255))
+	case sdl.BLENDMODE_MOD:
+		dst.R = uint8(uint16(dst.R) * uint16(c.R) / 255)
+		dst.G = uint8(uint16(dst.G) * uint16(c.G) / 255)
+		dst.B = uint8(uint16(dst.B) * uint16(c.B) / 255)
+	default:
+		dst = c
+	}
+	r.framebuffer.SetRGBA(px, py, dst)
+}
+
+// insideRounded reports whether a pixel is inside a box with corners rounded to rad
+func insideRounded(x, y, x1, y1, x2, y2, rad int32) bool {
+	cx, cy := x, y
+	switch {
+	case x < x1+rad:
+		cx = x1 + rad
+	case x > x2-rad:
+		cx = x2 - rad
+	}
+	switch {
+	case y < y1+rad:
+		cy = y1 + rad
+	case y > y2-rad:
+		cy = y2 - rad
+	}
+	dx, dy := x-cx, y-cy
+	return dx*dx+dy*dy <= rad*rad
+}
+
+// pointsRect turns two corners into a rect
+func pointsRect(x1, y1, x2, y2 int32) sdl.Rect {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	return sdl.Rect{X: x1, Y: y1, W: x2 - x1 + 1, H: y2 - y1 + 1}
+}
+
+// toRGBA converts an sdl color to the premultiplied color image.RGBA stores
+func toRGBA(c sdl.Color) color.RGBA {
+	a := uint16(c.A)
+	return color.RGBA{
+		R: uint8(uint16(c.R) * a / 255),
+		G: uint8(uint16(c.G) * a / 255),
+		B: uint8(uint16(c.B) * a / 255),
+		A: c.A,
+	}
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func min16(a, b uint16) uint16 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+var (
+	black = color.RGBA{A: 255}
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// testTexture is a texture of pixels given a row at a time
+func testTexture(rows ...[]color.RGBA) *ImageTexture {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return NewImageTexture(img)
+}
+
+// expectPixels fails the test for every pixel of the renderer's image that isn't what's expected, a row at a time
+func expectPixels(t *testing.T, r *SoftwareRenderer, want ...[]color.RGBA) {
+	t.Helper()
+	img := r.Image()
+	for y, row := range want {
+		for x, c := range row {
+			if got := img.RGBAAt(x, y); got != c {
+				t.Errorf("pixel %d,%d is %v, want %v", x, y, got, c)
+			}
+		}
+	}
+}
+
+func TestSoftwareRendererFillRect(t *testing.T) {
+	r := NewSoftwareRenderer(4, 3)
+	r.SetDrawColor(0, 0, 0, 255)
+	r.Clear()
+	r.SetDrawColor(255, 0, 0, 255)
+	r.FillRect(&sdl.Rect{X: 1, Y: 1, W: 2, H: 1})
+
+	expectPixels(t, r,
+		[]color.RGBA{black, black, black, black},
+		[]color.RGBA{black, red, red, black},
+		[]color.RGBA{black, black, black, black},
+	)
+	if len(r.Calls) != 1 || r.Calls[0].Op != "FillRect" || r.Calls[0].Dst != (sdl.Rect{X: 1, Y: 1, W: 2, H: 1}) {
+		t.Errorf("calls are %+v, want one FillRect", r.Calls)
+	}
+}
+
+func TestSoftwareRendererFillRectViewport(t *testing.T) {
+	r := NewSoftwareRenderer(3, 3)
+	r.SetDrawColor(0, 0, 0, 255)
+	r.Clear()
+	r.SetViewport(&sdl.Rect{X: 1, Y: 1, W: 2, H: 2})
+	r.SetDrawColor(0, 255, 0, 255)
+	// Only the part of the rect inside the viewport is drawn
+	r.FillRect(&sdl.Rect{X: 1, Y: 1, W: 5, H: 5})
+
+	expectPixels(t, r,
+		[]color.RGBA{black, black, black},
+		[]color.RGBA{black, black, black},
+		[]color.RGBA{black, black, green},
+	)
+}
+
+func TestSoftwareRendererCopy(t *testing.T) {
+	r := NewSoftwareRenderer(4, 4)
+	texture := testTexture(
+		[]color.RGBA{red, green},
+		[]color.RGBA{blue, white},
+	)
+	// Scaled up twice with nearest neighbor sampling
+	if err := r.Copy(texture, nil, &sdl.Rect{W: 4, H: 4}); err != nil {
+		t.Fatal(err)
+	}
+	expectPixels(t, r,
+		[]color.RGBA{red, red, green, green},
+		[]color.RGBA{red, red, green, green},
+		[]color.RGBA{blue, blue, white, white},
+		[]color.RGBA{blue, blue, white, white},
+	)
+
+	// Part of the texture, copied as it is
+	r.Copy(texture, &sdl.Rect{X: 1, Y: 1, W: 1, H: 1}, &sdl.Rect{X: 0, Y: 0, W: 1, H: 1})
+	expectPixels(t, r, []color.RGBA{white, red})
+}
+
+func TestSoftwareRendererCopyForeignTexture(t *testing.T) {
+	r := NewSoftwareRenderer(1, 1)
+	if err := r.Copy(&SDLTexture{}, nil, nil); err != errForeignTexture {
+		t.Errorf("copying a texture from another renderer returned %v, want errForeignTexture", err)
+	}
+}
+
+func TestSoftwareRendererAlphaBlend(t *testing.T) {
+	r := NewSoftwareRenderer(2, 1)
+	r.SetDrawColor(255, 255, 255, 255)
+	r.Clear()
+
+	// Half transparent black over white, blended
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	r.SetDrawColor(0, 0, 0, 128)
+	r.FillRect(&sdl.Rect{W: 1, H: 1})
+	// and without blending, replacing what's there
+	r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	r.FillRect(&sdl.Rect{X: 1, W: 1, H: 1})
+
+	expectPixels(t, r, []color.RGBA{{R: 127, G: 127, B: 127, A: 255}, {A: 128}})
+}
+
+func TestSoftwareRendererTextureMods(t *testing.T) {
+	r := NewSoftwareRenderer(2, 1)
+	r.SetDrawColor(0, 0, 0, 255)
+	r.Clear()
+
+	texture := testTexture([]color.RGBA{white})
+	texture.SetColorMod(255, 0, 0)
+	r.Copy(texture, nil, &sdl.Rect{W: 1, H: 1})
+	texture.SetColorMod(255, 255, 255)
+	texture.SetAlphaMod(128)
+	r.Copy(texture, nil, &sdl.Rect{X: 1, W: 1, H: 1})
+
+	expectPixels(t, r, []color.RGBA{red, {R: 128, G: 128, B: 128, A: 255}})
+}
+
+func TestSoftwareRendererFlip(t *testing.T) {
+	texture := testTexture(
+		[]color.RGBA{red, green},
+		[]color.RGBA{blue, white},
+	)
+	tests := []struct {
+		flip sdl.RendererFlip
+		want [][]color.RGBA
+	}{
+		{sdl.FLIP_NONE, [][]color.RGBA{{red, green}, {blue, white}}},
+		{sdl.FLIP_HORIZONTAL, [][]color.RGBA{{green, red}, {white, blue}}},
+		{sdl.FLIP_VERTICAL, [][]color.RGBA{{blue, white}, {red, green}}},
+		{sdl.FLIP_HORIZONTAL | sdl.FLIP_VERTICAL, [][]color.RGBA{{white, blue}, {green, red}}},
+	}
+	for _, test := range tests {
+		r := NewSoftwareRenderer(2, 2)
+		r.CopyEx(texture, nil, &sdl.Rect{W: 2, H: 2}, 0, nil, test.flip)
+		expectPixels(t, r, test.want...)
+		if call := r.Calls[0]; call.Op != "CopyEx" || call.Flip != test.flip {
+			t.Errorf("recorded %+v, want a CopyEx flipped %v", call, test.flip)
+		}
+	}
+}
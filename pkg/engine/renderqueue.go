@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"math"
+	"sort"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Layers things are drawn on, lower layers are drawn first
+const (
+	// LayerTiles is the layer a level's bottom layer of tiles is drawn on, each layer of its TileMap is drawn one above the last
+	LayerTiles = 0
+	// LayerEntities is the layer entities are drawn on, sorted by the bottom of their sprite so those lower down the screen are in front
+	LayerEntities = 100
+	// LayerOverlay is drawn over everything in the world, eg: debug info
+	LayerOverlay = 200
+)
+
+// DrawCommand is something to draw, submitted to a RenderQueue
+type DrawCommand struct {
+	Angle float64
+	// Draw, when set, is called to draw the command instead of copying Texture, for anything that isn't a sprite
+	Draw func(renderer Renderer)
+	Dst  sdl.Rect
+	Flip sdl.RendererFlip
+	// Layer is drawn above every lower layer, see LayerTiles and LayerEntities
+	Layer int
+	// SortKey orders commands within a layer, lowest first, eg: the Y of an entity's feet for top down depth sorting.
+	//   Commands with the same layer and key are drawn in the order they were submitted
+	SortKey float64
+	// Src is the part of Texture to draw, all of it when it's empty
+	Src     sdl.Rect
+	Texture Texture
+}
+
+// Vertex is a point of a triangle drawn with GeometryRenderer. It's laid out like an SDL_Vertex.
+//   U and V are texture coordinates from 0 to 1
+type Vertex struct {
+	X, Y  float32
+	Color sdl.Color
+	U, V  float32
+}
+
+// GeometryRenderer is implemented by renderers that can draw many textured triangles in one call, like SDL_RenderGeometry.
+//   SDLRenderer implements it when built with the sdl_geometry tag, which needs SDL 2.0.18 or later.
+type GeometryRenderer interface {
+	RenderGeometry(texture Texture, vertices []Vertex, indices []int32) error
+}
+
+// RenderQueue collects draw commands over a frame, then draws them in order of layer and sort key all at once.
+//   Neighbouring commands that copy from the same texture are drawn as a batch, with a single RenderGeometry
+//   call if the renderer is a GeometryRenderer. Like SDL_RenderGeometry, batches ignore texture color and alpha mods.
+type RenderQueue struct {
+	// Batches and Commands are how many batches and commands the last Flush drew
+	Batches  int
+	Commands int
+
+	commands []DrawCommand
+	indices  []int32
+	vertices []Vertex
+}
+
+// NewRenderQueue creates an empty render queue
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{}
+}
+
+// Submit adds a command to be drawn at the next Flush
+func (q *RenderQueue) Submit(cmd DrawCommand) {
+	q.commands = append(q.commands, cmd)
+}
+
+// SubmitFunc adds a draw func to be called in order at the next Flush, eg: for debug lines
+func (q *RenderQueue) SubmitFunc(layer int, key float64, draw func(renderer Renderer)) {
+	q.Submit(DrawCommand{Draw: draw, Layer: layer, SortKey: key})
+}
+
+// Len returns how many commands are waiting to be drawn
+func (q *RenderQueue) Len() int {
+	return len(q.commands)
+}
+
+// Flush sorts the queued commands, draws them and empties the queue.
+//   Every command is drawn even if some fail, the first error is returned.
+func (q *RenderQueue) Flush(renderer Renderer) error {
+	commands := q.commands
+	sort.SliceStable(commands, func(i, j int) bool {
+		if commands[i].Layer != commands[j].Layer {
+			return commands[i].Layer < commands[j].Layer
+		}
+		return commands[i].SortKey < commands[j].SortKey
+	})
+
+	geometry, _ := renderer.(GeometryRenderer)
+	var firstErr error
+	q.Batches, q.Commands = 0, len(commands)
+	for start := 0; start < len(commands); {
+		end := start + 1
+		if commands[start].Draw == nil {
+			for end < len(commands) && commands[end].Draw == nil && commands[end].Texture == commands[start].Texture {
+				end++
+			}
+		}
+		if err := q.draw(renderer, geometry, commands[start:end]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		q.Batches++
+		start = end
+	}
+
+	// Keep the backing arrays around for the next frame, but don't hold on to what was drawn
+	for i := range commands {
+		commands[i] = DrawCommand{}
+	}
+	q.commands = commands[:0]
+	return firstErr
+}
+
+// draw draws a batch of commands that share a texture, or a single draw func
+func (q *RenderQueue) draw(renderer Renderer, geometry GeometryRenderer, batch []DrawCommand) error {
+	first := batch[0]
+	if first.Draw != nil {
+		first.Draw(renderer)
+		return nil
+	}
+	if first.Texture == nil {
+		return nil
+	}
+	if geometry != nil && len(batch) > 1 {
+		return q.drawGeometry(geometry, batch)
+	}
+
+	var firstErr error
+	for _, cmd := range batch {
+		if err := copyCommand(renderer, cmd); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// copyCommand draws a command's texture with Copy, or CopyEx if it's turned or flipped
+func copyCommand(renderer Renderer, cmd DrawCommand) error {
+	var src *sdl.Rect
+	if cmd.Src.W > 0 && cmd.Src.H > 0 {
+		src = &cmd.Src
+	}
+	if cmd.Angle != 0 || cmd.Flip != sdl.FLIP_NONE {
+		return renderer.CopyEx(cmd.Texture, src, &cmd.Dst, cmd.Angle, nil, cmd.Flip)
+	}
+	return renderer.Copy(cmd.Texture, src, &cmd.Dst)
+}
+
+// drawGeometry draws a batch as two triangles per command in a single RenderGeometry call
+func (q *RenderQueue) drawGeometry(geometry GeometryRenderer, batch []DrawCommand) error {
+	texture := batch[0].Texture
+	texW, texH := texture.Size()
+	if texW <= 0 || texH <= 0 {
+		return nil
+	}
+	white := sdl.Color{R: 255, G: 255, B: 255, A: 255}
+
+	q.vertices, q.indices = q.vertices[:0], q.indices[:0]
+	for _, cmd := range batch {
+		src := cmd.Src
+		if src.W <= 0 || src.H <= 0 {
+			src = sdl.Rect{W: texW, H: texH}
+		}
+		u0, v0 := float32(src.X)/float32(texW), float32(src.Y)/float32(texH)
+		u1, v1 := float32(src.X+src.W)/float32(texW), float32(src.Y+src.H)/float32(texH)
+		if cmd.Flip&sdl.FLIP_HORIZONTAL != 0 {
+			u0, u1 = u1, u0
+		}
+		if cmd.Flip&sdl.FLIP_VERTICAL != 0 {
+			v0, v1 = v1, v0
+		}
+
+		// Corners clockwise from the top left, turned about the middle of Dst like CopyEx turns them
+		cx, cy := float64(cmd.Dst.X)+float64(cmd.Dst.W)/2, float64(cmd.Dst.Y)+float64(cmd.Dst.H)/2
+		hw, hh := float64(cmd.Dst.W)/2, float64(cmd.Dst.H)/2
+		sin, cos := math.Sincos(cmd.Angle * math.Pi / 180)
+		corners := [4][2]float64{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}}
+		uvs := [4][2]float32{{u0, v0}, {u1, v0}, {u1, v1}, {u0, v1}}
+		base := int32(len(q.vertices))
+		for i, c := range corners {
+			q.vertices = append(q.vertices, Vertex{
+				X:     float32(cx + c[0]*cos - c[1]*sin),
+				Y:     float32(cy + c[0]*sin + c[1]*cos),
+				Color: white,
+				U:     uvs[i][0],
+				V:     uvs[i][1],
+			})
+		}
+		q.indices = append(q.indices, base, base+1, base+2, base, base+2, base+3)
+	}
+	return geometry.RenderGeometry(texture, q.vertices, q.indices)
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/ryanhartje/gogome/pkg/input"
+)
+
+// ReplayVersion is the version of the replay format written by Record
+const ReplayVersion = 1
+
+// Rand is the engine's random number generator. Use it instead of math/rand in game code,
+//   so recording a replay can capture its seed and playing it back gets the same numbers.
+var Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// ReplayHeader is written at the start of every replay
+type ReplayHeader struct {
+	Version  int   `json:"version"`
+	Seed     int64 `json:"seed"`
+	TickRate int   `json:"tick_rate"`
+}
+
+// Replay is a recording of the input actions for every tick of a play session
+type Replay struct {
+	ReplayHeader
+	Ticks []input.Snapshot
+
+	position int
+}
+
+// recorder writes a replay as the engine ticks
+type recorder struct {
+	encoder *json.Encoder
+}
+
+// Record starts recording the engine's input to w. The replay is written as JSON lines, a header
+//   and then a snapshot of the input actions per tick, so a crash still leaves a usable replay.
+//   Rand is reseeded so the replay can reproduce it. Call Record before Run.
+func (e *Engine) Record(w io.Writer) error {
+	seed := time.Now().UnixNano()
+	header := ReplayHeader{
+		Version:  ReplayVersion,
+		Seed:     seed,
+		TickRate: e.tickRate(),
+	}
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+	Rand.Seed(seed)
+	e.recorder = &recorder{encoder: encoder}
+	return nil
+}
+
+// StopRecording stops writing input to the replay started with Record
+func (e *Engine) StopRecording() {
+	e.recorder = nil
+}
+
+// LoadReplay reads a replay written by Record
+func LoadReplay(r io.Reader) (*Replay, error) {
+	decoder := json.NewDecoder(r)
+	replay := &Replay{}
+	if err := decoder.Decode(&replay.ReplayHeader); err != nil {
+		return nil, err
+	}
+	if replay.Version != ReplayVersion {
+		return nil, fmt.Errorf("engine: unsupported replay version %d", replay.Version)
+	}
+	for {
+		var tick input.Snapshot
+		err := decoder.Decode(&tick)
+		if err == io.EOF {
+			break
+		}
+		// A replay cut short by a crash is still good up to its last whole tick
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		replay.Ticks = append(replay.Ticks, tick)
+	}
+	return replay, nil
+}
+
+// Done reports whether every tick of the replay has been played
+func (r *Replay) Done() bool {
+	return r.position >= len(r.Ticks)
+}
+
+// Play drives the engine's input from a replay instead of the keyboard, mouse and controllers.
+//   Rand is reseeded and the tick rate set to match the recording, or DefaultTickRate if neither the
+//   recording nor the engine has one. Once the replay runs out the engine goes back to live input.
+func (e *Engine) Play(replay *Replay) {
+	replay.position = 0
+	Rand.Seed(replay.Seed)
+	e.TickRate = e.tickRate()
+	if replay.TickRate > 0 {
+		e.TickRate = replay.TickRate
+	}
+	e.replay = replay
+}
+
+// Simulate plays a replay through game as fast as possible, without drawing or waiting on the clock.
+//   It's meant for running replays as regression tests, check the game's state once it returns.
+func (e *Engine) Simulate(game Game, replay *Replay) {
+	e.Play(replay)
+	step := time.Second / time.Duration(e.tickRate())
+	for !replay.Done() {
+		e.tick(game, step)
+	}
+	e.replay = nil
+}
+
+// tick samples input, from the replay if one is playing, records it if we're recording,
+//   then updates the game by one step. A replay is played through even without Input to apply it to,
+//   so it always runs out.
+func (e *Engine) tick(game Game, step time.Duration) {
+	if e.replay != nil && !e.replay.Done() {
+		if e.Input != nil {
+			e.Input.Apply(e.replay.Ticks[e.replay.position])
+		}
+		e.replay.position++
+	} else {
+		e.replay = nil
+		if e.Input != nil {
+			e.Input.Update()
+		}
+	}
+	if e.recorder != nil && e.Input != nil {
+		// A failed write shouldn't take the game down with it, stop recording instead
+		if err := e.recorder.encoder.Encode(e.Input.Snapshot()); err != nil {
+			e.recorder = nil
+		}
+	}
+	game.Update(step.Seconds())
+	e.Ticks++
+}
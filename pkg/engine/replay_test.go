@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ryanhartje/gogome/pkg/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// walker is a game that walks right while move_right is held, and wanders a random amount every tick
+type walker struct {
+	input  *input.Map
+	x      float64
+	wander int
+}
+
+func (w *walker) Update(dt float64) {
+	if w.input != nil && w.input.Held(input.MoveRight) {
+		w.x += 100 * dt
+	}
+	w.wander += Rand.Intn(10)
+}
+
+func (w *walker) Draw(renderer Renderer, alpha float64) {}
+
+// press presses a key on a map for a tick, as if SDL sent it the event
+func press(m *input.Map, scancode sdl.Scancode) {
+	m.HandleEvent(&sdl.KeyboardEvent{State: sdl.PRESSED, Keysym: sdl.Keysym{Scancode: scancode}})
+}
+
+func TestReplaySimulate(t *testing.T) {
+	const ticks = 48
+	e := &Engine{Input: input.NewDefaultMap(), TickRate: 16}
+	var recording bytes.Buffer
+	if err := e.Record(&recording); err != nil {
+		t.Fatal(err)
+	}
+	recorded := &walker{input: e.Input}
+	step := time.Second / time.Duration(e.TickRate)
+	for i := 0; i < ticks; i++ {
+		if i%3 == 0 {
+			press(e.Input, sdl.SCANCODE_RIGHT)
+		}
+		e.tick(recorded, step)
+	}
+	e.StopRecording()
+	if recorded.x == 0 {
+		t.Fatal("walker didn't move while recording")
+	}
+
+	replay, err := LoadReplay(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replay.Ticks) != ticks {
+		t.Fatalf("replay has %d ticks, want %d", len(replay.Ticks), ticks)
+	}
+	played := &Engine{Input: input.NewDefaultMap()}
+	replayed := &walker{input: played.Input}
+	played.Simulate(replayed, replay)
+
+	if played.Ticks != ticks {
+		t.Errorf("simulated %d ticks, want %d", played.Ticks, ticks)
+	}
+	if played.TickRate != e.TickRate {
+		t.Errorf("simulated at %d ticks a second, want the recorded %d", played.TickRate, e.TickRate)
+	}
+	if replayed.x != recorded.x || replayed.wander != recorded.wander {
+		t.Errorf("replay ended at x %v wander %d, want x %v wander %d", replayed.x, replayed.wander, recorded.x, recorded.wander)
+	}
+}
+
+func TestReplaySimulateWithoutInput(t *testing.T) {
+	replay := &Replay{ReplayHeader: ReplayHeader{Version: ReplayVersion, TickRate: 10}, Ticks: make([]input.Snapshot, 5)}
+	e := &Engine{}
+	done := make(chan struct{})
+	go func() {
+		e.Simulate(&walker{}, replay)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Simulate didn't return without an input map")
+	}
+	if e.Ticks != 5 {
+		t.Errorf("simulated %d ticks, want 5", e.Ticks)
+	}
+}
+
+func TestReplayZeroTickRate(t *testing.T) {
+	// Recording on an engine that was never Run writes the default rate, not 0
+	var recording bytes.Buffer
+	if err := (&Engine{}).Record(&recording); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := LoadReplay(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.TickRate != DefaultTickRate {
+		t.Errorf("recorded a tick rate of %d, want %d", replay.TickRate, DefaultTickRate)
+	}
+
+	// and a replay without one plays at the default rate
+	replay = &Replay{ReplayHeader: ReplayHeader{Version: ReplayVersion}, Ticks: make([]input.Snapshot, 3)}
+	e := &Engine{}
+	e.Simulate(&walker{}, replay)
+	if e.Ticks != 3 {
+		t.Errorf("simulated %d ticks, want 3", e.Ticks)
+	}
+	if e.TickRate != DefaultTickRate {
+		t.Errorf("simulated at %d ticks a second, want %d", e.TickRate, DefaultTickRate)
+	}
+}
@@ -0,0 +1,555 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Align is how lines of text line up with each other, and with the width they're wrapped to
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// tabWidth is how many spaces wide a tab is
+const tabWidth = 4
+
+// Colors are the colors markup can name, eg: [color=red]. Any other color is given as #rrggbb or #rrggbbaa
+var Colors = map[string]sdl.Color{
+	"black":  {R: 0, G: 0, B: 0, A: 255},
+	"blue":   {R: 80, G: 140, B: 255, A: 255},
+	"cyan":   {R: 0, G: 255, B: 255, A: 255},
+	"gray":   {R: 128, G: 128, B: 128, A: 255},
+	"green":  {R: 80, G: 220, B: 80, A: 255},
+	"orange": {R: 255, G: 160, B: 0, A: 255},
+	"purple": {R: 180, G: 90, B: 255, A: 255},
+	"red":    {R: 255, G: 60, B: 60, A: 255},
+	"white":  {R: 255, G: 255, B: 255, A: 255},
+	"yellow": {R: 255, G: 230, B: 0, A: 255},
+}
+
+// Icon is a sprite drawn in line with text by markup, eg: [icon=key]. It sits on the line like a character would
+type Icon struct {
+	Frame int
+	Sheet *SpriteSheet
+}
+
+// RichText is text laid out over as many lines as it needs, wrapped to a width and styled with markup:
+//
+//   [color=red]...[/color] or [color=#ff8000]...[/color]  draws the text between in a color
+//   [b]...[/b] and [i]...[/i]                            draws it with Fonts["bold"] and Fonts["italic"]
+//   [font=name]...[/font]                                draws it with Fonts[name]
+//   [icon=name]                                          draws Icons[name]
+//   [[                                                   is a [ that doesn't start a tag
+//
+//   A closing tag ends the last tag of its kind still open, so [b][font=x]...[/b]...[/font] ends the bold
+//   and carries on in x. Closing tags with nothing to close are dropped.
+//   Tags that aren't recognised, or name a font or icon that isn't there, are drawn as they're written.
+//   The text is laid out again when Text, Width or any other setting it depends on changes,
+//   except Fonts and Icons, call Free after changing those.
+type RichText struct {
+	Align Align
+	// Color is what the text is drawn in where markup doesn't say otherwise, its alpha fades all of the text
+	Color sdl.Color
+	// Font is the text's font where markup doesn't say otherwise
+	Font Font
+	// Fonts are the fonts markup can switch to by name
+	Fonts map[string]Font
+	// Icons are the sprites markup can draw by name
+	Icons map[string]Icon
+	// LineSpacing is how much space is left between lines, on top of the height of the line
+	LineSpacing int32
+	Text        string
+	// Width is how wide lines are wrapped to and aligned in. Lines are only broken at newlines when it's 0,
+	//   and aligned to the widest of them
+	Width int32
+	X, Y  float64
+
+	layout  *TextLayout
+	laidOut richKey
+}
+
+// richKey is everything that's laid out, to tell when it needs to be done again
+type richKey struct {
+	align       Align
+	font        Font
+	lineSpacing int32
+	text        string
+	width       int32
+}
+
+// TextLayout is where each character and icon of a RichText is drawn, relative to the top left of the text
+type TextLayout struct {
+	// Lines are the bounds of each line
+	Lines []sdl.Rect
+	// W and H are how wide the widest line is, and how high all of them are
+	W, H int32
+
+	items []layoutItem
+	// lineStarts are where in items each line starts
+	lineStarts []int
+}
+
+// layoutItem is a glyph or an icon placed by a layout
+type layoutItem struct {
+	color sdl.Color
+	dst   sdl.Rect
+	// font and page are what a glyph is drawn from, icons have no font
+	font Font
+	icon Icon
+	page int
+	// plain items are drawn in the text's color, the rest in a color set by markup
+	plain bool
+	src   sdl.Rect
+}
+
+// NewRichText lays out markup in font, wrapped to width
+func NewRichText(font Font, text string, width int32, x, y float64) *RichText {
+	return &RichText{
+		Color: sdl.Color{R: 255, G: 255, B: 255, A: 255},
+		Font:  font,
+		Text:  text,
+		Width: width,
+		X:     x,
+		Y:     y,
+	}
+}
+
+// Draw draws the text at X, Y
+func (t *RichText) Draw(renderer Renderer, x, y int) {
+	if t.Font == nil {
+		return
+	}
+	t.Layout().Draw(renderer, int32(t.X), int32(t.Y), t.Color)
+}
+
+// Layout returns the text laid out, laying it out again if it's changed since it last was
+func (t *RichText) Layout() *TextLayout {
+	key := richKey{
+		align:       t.Align,
+		font:        t.Font,
+		lineSpacing: t.LineSpacing,
+		text:        t.Text,
+		width:       t.Width,
+	}
+	if t.layout == nil || t.laidOut != key {
+		t.layout = t.layOut(t.Width)
+		t.laidOut = key
+	}
+	return t.layout
+}
+
+// Size returns how wide and high the text is drawn
+func (t *RichText) Size() (int32, int32) {
+	layout := t.Layout()
+	return layout.W, layout.H
+}
+
+// Measure returns how wide and high the text would be if it was wrapped to width,
+//   eg: to size a box around it before drawing. It doesn't change how the text is drawn.
+func (t *RichText) Measure(width int32) (int32, int32) {
+	layout := t.layOut(width)
+	return layout.W, layout.H
+}
+
+// Free lets go of the text's layout, it's laid out again the next time it's drawn
+func (t *RichText) Free() {
+	t.layout = nil
+}
+
+// Draw draws the layout with its top left at x, y. Plain text is drawn in color, and everything is faded by its alpha
+func (l *TextLayout) Draw(renderer Renderer, x, y int32, color sdl.Color) {
+	l.DrawPart(renderer, x, y, color, 0, len(l.items))
+}
+
+// Len is how many characters and icons are drawn, spaces aren't counted
+func (l *TextLayout) Len() int {
+	return len(l.items)
+}
+
+// LineStart returns how many characters and icons are drawn before line, Len if it's past the last line
+func (l *TextLayout) LineStart(line int) int {
+	if line >= len(l.lineStarts) {
+		return len(l.items)
+	}
+	return l.lineStarts[line]
+}
+
+// DrawPart draws the characters and icons from first up to end, eg: to reveal text a character at a time,
+//   or draw the lines of a page. They're drawn where they'd be if the whole layout was drawn at x, y
+func (l *TextLayout) DrawPart(renderer Renderer, x, y int32, color sdl.Color, first, end int) {
+	if first < 0 {
+		first = 0
+	}
+	if end > len(l.items) {
+		end = len(l.items)
+	}
+	if first >= end {
+		return
+	}
+	var font Font
+	var page int
+	var texture Texture
+	var mod sdl.Color
+	for _, item := range l.items[first:end] {
+		dst := item.dst
+		dst.X += x
+		dst.Y += y
+		if item.icon.Sheet != nil {
+			item.icon.Sheet.Draw(renderer, item.icon.Frame, &dst, 0, sdl.FLIP_NONE)
+			continue
+		}
+
+		c := color
+		if !item.plain {
+			c = item.color
+			c.A = uint8(uint16(c.A) * uint16(color.A) / 255)
+		}
+		if item.font != font || item.page != page {
+			var err error
+			texture, err = item.font.Texture(renderer, item.page)
+			checkErr(err)
+			font, page = item.font, item.page
+			// Other text may have tinted the page since, so set the mods again
+			mod = c
+			mod.A = ^c.A
+		}
+		if c != mod {
+			texture.SetColorMod(c.R, c.G, c.B)
+			texture.SetAlphaMod(c.A)
+			mod = c
+		}
+		renderer.Copy(texture, &item.src, &dst)
+	}
+}
+
+// layOut lays the text out wrapped to width
+func (t *RichText) layOut(width int32) *TextLayout {
+	if t.Font == nil {
+		return &TextLayout{}
+	}
+	m := markup{
+		colors:   []sdl.Color{t.Color},
+		fontTags: []string{""},
+		fonts:    []Font{t.Font},
+		lines:    lineBreaker{width: width},
+		plains:   []bool{true},
+		text:     t,
+	}
+	m.parse(t.Text)
+	m.lines.flushWord()
+	m.lines.endLine()
+	return m.lines.place(t.Font, t.Align, t.LineSpacing)
+}
+
+// markup reads markup into pieces of text, keeping track of the colors and fonts tags have switched to
+type markup struct {
+	colors []sdl.Color
+	// fontTags are the names of the tags that switched to each of fonts, so closing tags end the right one
+	fontTags []string
+	fonts    []Font
+	lines    lineBreaker
+	plains   []bool
+	// prev is the last character added to the word, so the next can be kerned against it
+	prev rune
+	text *RichText
+}
+
+// parse adds each character and icon of text to the lines, following its tags
+func (m *markup) parse(text string) {
+	for i := 0; i < len(text); {
+		if text[i] == '[' {
+			if strings.HasPrefix(text[i:], "[[") {
+				m.add('[')
+				i += 2
+				continue
+			}
+			if end := strings.IndexByte(text[i:], ']'); end > 0 && m.tag(text[i+1:i+end]) {
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		m.add(r)
+		i += size
+	}
+}
+
+// tag follows a tag, it returns false if the tag isn't one markup knows
+func (m *markup) tag(tag string) bool {
+	name, value := tag, ""
+	if eq := strings.IndexByte(tag, '='); eq >= 0 {
+		name, value = tag[:eq], tag[eq+1:]
+	}
+	font := func(font Font, ok bool) bool {
+		if !ok || font == nil {
+			return false
+		}
+		m.fonts = append(m.fonts, font)
+		m.fontTags = append(m.fontTags, name)
+		m.prev = 0
+		return true
+	}
+
+	switch name {
+	case "b":
+		f, ok := m.text.Fonts["bold"]
+		return font(f, ok && value == "")
+	case "i":
+		f, ok := m.text.Fonts["italic"]
+		return font(f, ok && value == "")
+	case "font":
+		f, ok := m.text.Fonts[value]
+		return font(f, ok)
+	case "/b", "/i", "/font":
+		if value != "" {
+			return false
+		}
+		// Fonts switched to inside the closed tag stay switched to
+		for i := len(m.fonts) - 1; i > 0; i-- {
+			if m.fontTags[i] == name[1:] {
+				m.fonts = append(m.fonts[:i], m.fonts[i+1:]...)
+				m.fontTags = append(m.fontTags[:i], m.fontTags[i+1:]...)
+				m.prev = 0
+				break
+			}
+		}
+		return true
+	case "color":
+		c, ok := parseColor(value)
+		if !ok {
+			return false
+		}
+		m.colors = append(m.colors, c)
+		m.plains = append(m.plains, false)
+		return true
+	case "/color":
+		if value != "" {
+			return false
+		}
+		if len(m.colors) > 1 {
+			m.colors = m.colors[:len(m.colors)-1]
+			m.plains = m.plains[:len(m.plains)-1]
+		}
+		return true
+	case "icon":
+		icon, ok := m.text.Icons[value]
+		if !ok || icon.Sheet == nil {
+			return false
+		}
+		frame, ok := icon.Sheet.Frame(icon.Frame)
+		if !ok {
+			return false
+		}
+		m.lines.addWord(piece{
+			advance: frame.W,
+			ascent:  frame.H,
+			item:    layoutItem{dst: sdl.Rect{Y: -frame.H, W: frame.W, H: frame.H}, icon: icon},
+		})
+		m.prev = 0
+		return true
+	}
+	return false
+}
+
+// add adds a character in the current font and color
+func (m *markup) add(r rune) {
+	font := m.fonts[len(m.fonts)-1]
+	switch r {
+	case '\r':
+		return
+	case '\n':
+		m.lines.newline()
+		m.prev = 0
+		return
+	}
+
+	count := 1
+	if r == '\t' {
+		r, count = ' ', tabWidth
+	}
+	glyph, err := font.Glyph(r)
+	checkErr(err)
+	p := piece{
+		advance: glyph.Advance,
+		ascent:  font.Ascent(),
+		descent: font.Descent(),
+		item: layoutItem{
+			color: m.colors[len(m.colors)-1],
+			dst:   sdl.Rect{X: glyph.XOffset, Y: glyph.YOffset - font.Ascent(), W: glyph.Src.W, H: glyph.Src.H},
+			font:  font,
+			page:  glyph.Page,
+			plain: m.plains[len(m.plains)-1],
+			src:   glyph.Src,
+		},
+	}
+	if r == ' ' {
+		for i := 0; i < count; i++ {
+			m.lines.addSpace(p)
+		}
+		m.prev = 0
+		return
+	}
+	if m.prev != 0 {
+		m.lines.kern(font.Kerning(m.prev, r))
+	}
+	m.lines.addWord(p)
+	m.prev = r
+}
+
+// piece is a character or icon being laid out. Its item's Y is relative to the baseline of the line it ends up on
+type piece struct {
+	advance         int32
+	ascent, descent int32
+	item            layoutItem
+}
+
+// lineBreaker breaks pieces into lines no wider than width, between words where it can
+type lineBreaker struct {
+	line, spaces, word    []piece
+	lines                 [][]piece
+	lineW, spacesW, wordW int32
+	width                 int32
+}
+
+// addWord adds a piece to the word being built
+func (b *lineBreaker) addWord(p piece) {
+	b.word = append(b.word, p)
+	b.wordW += p.advance
+}
+
+// kern moves the next piece of the word closer to, or further from, the last
+func (b *lineBreaker) kern(kern int32) {
+	if len(b.word) > 0 {
+		b.word[len(b.word)-1].advance += kern
+		b.wordW += kern
+	}
+}
+
+// addSpace ends the word being built. Spaces are only kept if a word follows them on the same line
+func (b *lineBreaker) addSpace(p piece) {
+	b.flushWord()
+	p.item.src, p.item.font = sdl.Rect{}, nil
+	b.spaces = append(b.spaces, p)
+	b.spacesW += p.advance
+}
+
+// newline ends the word being built and the line it's on
+func (b *lineBreaker) newline() {
+	b.flushWord()
+	b.endLine()
+}
+
+// flushWord puts the word being built on the line after any spaces before it, or on the next line if it doesn't fit.
+//   A word too wide for a line of its own is broken between characters.
+func (b *lineBreaker) flushWord() {
+	if len(b.word) == 0 {
+		return
+	}
+	if b.width > 0 && len(b.line) > 0 && b.lineW+b.spacesW+b.wordW > b.width {
+		b.endLine()
+	} else {
+		b.line = append(b.line, b.spaces...)
+		b.lineW += b.spacesW
+	}
+	b.spaces, b.spacesW = b.spaces[:0], 0
+
+	for _, p := range b.word {
+		if b.width > 0 && len(b.line) > 0 && b.lineW+p.advance > b.width {
+			b.endLine()
+		}
+		b.line = append(b.line, p)
+		b.lineW += p.advance
+	}
+	b.word, b.wordW = b.word[:0], 0
+}
+
+// endLine starts a new line, dropping any spaces left at the end of the last
+func (b *lineBreaker) endLine() {
+	b.lines = append(b.lines, b.line)
+	b.line, b.lineW = nil, 0
+	b.spaces, b.spacesW = b.spaces[:0], 0
+}
+
+// place lines up the lines under each other, aligned to the breaker's width, or the widest line without one.
+//   Lines are as high as the tallest thing on them, lines with nothing on them are as high as font.
+func (b *lineBreaker) place(font Font, align Align, spacing int32) *TextLayout {
+	widths := make([]int32, len(b.lines))
+	layout := &TextLayout{}
+	for i, line := range b.lines {
+		for _, p := range line {
+			widths[i] += p.advance
+		}
+		if widths[i] > layout.W {
+			layout.W = widths[i]
+		}
+	}
+	area := b.width
+	if area <= 0 {
+		area = layout.W
+	}
+
+	var y int32
+	for i, line := range b.lines {
+		ascent, descent := font.Ascent(), font.Descent()
+		if len(line) > 0 {
+			ascent, descent = 0, 0
+		}
+		for _, p := range line {
+			if p.ascent > ascent {
+				ascent = p.ascent
+			}
+			if p.descent > descent {
+				descent = p.descent
+			}
+		}
+
+		x := int32(0)
+		switch align {
+		case AlignCenter:
+			x = (area - widths[i]) / 2
+		case AlignRight:
+			x = area - widths[i]
+		}
+		layout.Lines = append(layout.Lines, sdl.Rect{X: x, Y: y, W: widths[i], H: ascent + descent})
+		layout.lineStarts = append(layout.lineStarts, len(layout.items))
+		for _, p := range line {
+			if p.item.icon.Sheet != nil || (p.item.font != nil && p.item.src.W > 0 && p.item.src.H > 0) {
+				item := p.item
+				item.dst.X += x
+				item.dst.Y += y + ascent
+				layout.items = append(layout.items, item)
+			}
+			x += p.advance
+		}
+		y += ascent + descent + spacing
+	}
+	if len(b.lines) > 0 {
+		layout.H = y - spacing
+	}
+	return layout
+}
+
+// parseColor reads a color named in Colors, or written as #rrggbb or #rrggbbaa
+func parseColor(value string) (sdl.Color, bool) {
+	if c, ok := Colors[strings.ToLower(value)]; ok {
+		return c, true
+	}
+	if !strings.HasPrefix(value, "#") || (len(value) != 7 && len(value) != 9) {
+		return sdl.Color{}, false
+	}
+	n, err := strconv.ParseUint(value[1:], 16, 32)
+	if err != nil {
+		return sdl.Color{}, false
+	}
+	if len(value) == 7 {
+		n = n<<8 | 0xff
+	}
+	return sdl.Color{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, true
+}
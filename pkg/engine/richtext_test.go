@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// testChars are the characters testFont has, each drawn 4 pixels wide from its own cell
+const testChars = "abcdefghijklmnopqrstuvwxyz?"
+
+// testFont is a font whose characters are 4 pixels apart on lines 10 pixels high, 8 of them above the baseline.
+//   Each font has its own page, so draw calls show which font a character was drawn in
+func testFont() *BitmapFont {
+	font := &BitmapFont{
+		Base:       8,
+		Glyphs:     map[rune]Glyph{' ': {Advance: 4}},
+		Kernings:   map[[2]rune]int32{},
+		LineHeight: 10,
+		Pages:      []Texture{NewImageTexture(image.NewRGBA(image.Rect(0, 0, 4*len(testChars), 8)))},
+	}
+	for i, r := range testChars {
+		font.Glyphs[r] = Glyph{Advance: 4, Src: sdl.Rect{X: int32(i) * 4, W: 4, H: 8}}
+	}
+	return font
+}
+
+// lineWidths returns the x and width of each line of a layout
+func lineWidths(layout *TextLayout) [][2]int32 {
+	var lines [][2]int32
+	for _, line := range layout.Lines {
+		lines = append(lines, [2]int32{line.X, line.W})
+	}
+	return lines
+}
+
+func TestRichTextWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int32
+		want  [][2]int32
+	}{
+		{"fits", "ab cd", 20, [][2]int32{{0, 20}}},
+		{"between words", "ab cd efgh", 20, [][2]int32{{0, 20}, {0, 16}}},
+		{"trailing spaces dropped", "abcd    efgh", 20, [][2]int32{{0, 16}, {0, 16}}},
+		{"long word broken", "ab ijklmnop", 20, [][2]int32{{0, 8}, {0, 20}, {0, 12}}},
+		{"newlines", "ab\n\ncd", 20, [][2]int32{{0, 8}, {0, 0}, {0, 8}}},
+		{"tab", "a\tb", 0, [][2]int32{{0, 24}}},
+		{"no width", "ab cd efgh ijklmnop", 0, [][2]int32{{0, 76}}},
+	}
+	for _, test := range tests {
+		text := NewRichText(testFont(), test.text, test.width, 0, 0)
+		layout := text.Layout()
+		if got := lineWidths(layout); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: lines are %v, want %v", test.name, got, test.want)
+		}
+		for i, line := range layout.Lines {
+			if line.Y != int32(i)*10 || line.H != 10 {
+				t.Errorf("%s: line %d is at y %d and %d high, want %d and 10", test.name, i, line.Y, line.H, i*10)
+			}
+		}
+		if want := int32(len(test.want)) * 10; layout.H != want {
+			t.Errorf("%s: layout is %d high, want %d", test.name, layout.H, want)
+		}
+	}
+
+	text := NewRichText(testFont(), "ab cd efgh", 20, 0, 0)
+	text.LineSpacing = 2
+	if w, h := text.Size(); w != 20 || h != 22 {
+		t.Errorf("Size() with line spacing = %d, %d, want 20, 22", w, h)
+	}
+	if w, h := text.Measure(0); w != 40 || h != 10 {
+		t.Errorf("Measure(0) = %d, %d, want 40, 10", w, h)
+	}
+	if w, _ := text.Size(); w != 20 {
+		t.Errorf("Measure changed the text's own layout, it's %d wide", w)
+	}
+}
+
+func TestRichTextAlign(t *testing.T) {
+	tests := []struct {
+		align Align
+		width int32
+		want  [][2]int32
+	}{
+		{AlignLeft, 20, [][2]int32{{0, 8}, {0, 16}}},
+		{AlignCenter, 20, [][2]int32{{6, 8}, {2, 16}}},
+		{AlignRight, 20, [][2]int32{{12, 8}, {4, 16}}},
+		// Without a width lines line up with the widest
+		{AlignCenter, 0, [][2]int32{{4, 8}, {0, 16}}},
+		{AlignRight, 0, [][2]int32{{8, 8}, {0, 16}}},
+	}
+	for _, test := range tests {
+		text := NewRichText(testFont(), "ab\nabcd", test.width, 0, 0)
+		text.Align = test.align
+		if got := lineWidths(text.Layout()); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("align %d in %d: lines are %v, want %v", test.align, test.width, got, test.want)
+		}
+	}
+
+	// Characters are drawn where their line is
+	r := NewSoftwareRenderer(40, 40)
+	text := NewRichText(testFont(), "ab\nabcd", 20, 5, 7)
+	text.Align = AlignRight
+	text.Draw(r, 0, 0)
+	var got []sdl.Rect
+	for _, call := range r.Calls {
+		got = append(got, call.Dst)
+	}
+	want := []sdl.Rect{
+		{X: 17, Y: 7, W: 4, H: 8}, {X: 21, Y: 7, W: 4, H: 8},
+		{X: 9, Y: 17, W: 4, H: 8}, {X: 13, Y: 17, W: 4, H: 8}, {X: 17, Y: 17, W: 4, H: 8}, {X: 21, Y: 17, W: 4, H: 8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("characters drawn at %v, want %v", got, want)
+	}
+}
+
+func TestRichTextFonts(t *testing.T) {
+	regular, bold, italic, mono := testFont(), testFont(), testFont(), testFont()
+	names := map[Texture]string{
+		regular.Pages[0]: "regular",
+		bold.Pages[0]:    "bold",
+		italic.Pages[0]:  "italic",
+		mono.Pages[0]:    "mono",
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"a[b]b[/b]c", []string{"regular", "bold", "regular"}},
+		{"[b]a[i]b[/i]c[/b]d", []string{"bold", "italic", "bold", "regular"}},
+		// Closing tags end their own opener, not whatever was opened last
+		{"a[b]b[font=mono]c[/b]d[/font]e", []string{"regular", "bold", "mono", "mono", "regular"}},
+		{"[i]a[b]b[/i]c[/b]d", []string{"italic", "bold", "bold", "regular"}},
+		{"[font=mono]a[font=mono]b[/font]c[/font]d", []string{"mono", "mono", "mono", "regular"}},
+		// Closing tags with nothing open are dropped
+		{"a[/b]b[/font]c", []string{"regular", "regular", "regular"}},
+	}
+	for _, test := range tests {
+		text := NewRichText(regular, test.text, 0, 0, 0)
+		text.Fonts = map[string]Font{"bold": bold, "italic": italic, "mono": mono}
+		r := NewSoftwareRenderer(100, 10)
+		text.Draw(r, 0, 0)
+		var got []string
+		for _, call := range r.Calls {
+			got = append(got, names[call.Texture])
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q drawn in %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+// testChar is a character laid out by a RichText, where it's cut from testFont's page and what color it's drawn in
+type testChar struct {
+	src   int32
+	color sdl.Color
+	plain bool
+}
+
+// plainChars are the characters of text as testFont draws them without markup, ? standing in for any it doesn't have
+func plainChars(text string) []testChar {
+	var chars []testChar
+	for _, r := range text {
+		i := strings.IndexRune(testChars, r)
+		if i < 0 {
+			i = len(testChars) - 1
+		}
+		chars = append(chars, testChar{src: int32(i) * 4, plain: true})
+	}
+	return chars
+}
+
+func TestRichTextMarkup(t *testing.T) {
+	orange := Colors["orange"]
+	faded := sdl.Color{R: 0x11, G: 0x22, B: 0x33, A: 0x80}
+
+	tests := []struct {
+		text string
+		want []testChar
+	}{
+		{"[color=orange]a[/color]b", []testChar{{0, orange, false}, {4, sdl.Color{}, true}}},
+		{"[color=orange]a[color=#11223380]b[/color]c", []testChar{{0, orange, false}, {4, faded, false}, {8, orange, false}}},
+		{"[color=orange]a[/color]b[/color]c", append([]testChar{{0, orange, false}}, plainChars("bc")...)},
+		{"[[b]", plainChars("[b]")},
+		// Tags that aren't recognised, or name fonts or colors that aren't there, are drawn as written
+		{"[x]", plainChars("[x]")},
+		{"[color=nope]a", plainChars("[color=nope]a")},
+		{"[b]a", plainChars("[b]a")},
+		{"[font=nope]a", plainChars("[font=nope]a")},
+		{"[icon=nope]", plainChars("[icon=nope]")},
+		{"[/color=red]", plainChars("[/color=red]")},
+	}
+	for _, test := range tests {
+		layout := NewRichText(testFont(), test.text, 0, 0, 0).Layout()
+		var got []testChar
+		for _, item := range layout.items {
+			c := testChar{src: item.src.X, plain: item.plain}
+			if !item.plain {
+				c.color = item.color
+			}
+			got = append(got, c)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q laid out as %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestRichTextIcon(t *testing.T) {
+	sheet := &SpriteSheet{Texture: NewImageTexture(image.NewRGBA(image.Rect(0, 0, 12, 12))), Frames: []sdl.Rect{{W: 12, H: 12}}}
+	text := NewRichText(testFont(), "a[icon=key]b", 0, 0, 0)
+	text.Icons = map[string]Icon{"key": {Sheet: sheet}}
+
+	r := NewSoftwareRenderer(40, 20)
+	text.Draw(r, 0, 0)
+	var got []sdl.Rect
+	for _, call := range r.Calls {
+		got = append(got, call.Dst)
+	}
+	// The icon is taller than the font, so the line's baseline drops to fit it
+	want := []sdl.Rect{{X: 0, Y: 4, W: 4, H: 8}, {X: 4, Y: 0, W: 12, H: 12}, {X: 16, Y: 4, W: 4, H: 8}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("drawn at %v, want %v", got, want)
+	}
+	if w, h := text.Size(); w != 20 || h != 14 {
+		t.Errorf("Size() = %d, %d, want 20, 14", w, h)
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"math"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Scene is a screen of the game, like a title menu, a level or a pause menu.
+//   Scenes live on the engine's SceneStack. Enter is called when a scene is added to the stack and
+//   Exit when it's removed. Only the top scene is updated and sent events, but overlay scenes are
+//   drawn over whatever is underneath them.
+//   Assets loaded by a scene while it's on the stack are released once it exits.
+type Scene interface {
+	Enter(e *Engine)
+	Exit(e *Engine)
+	Update(dt float64)
+	Draw(renderer Renderer, alpha float64)
+	HandleEvent(event sdl.Event)
+}
+
+// SceneStack manages the scenes of a game. It satisfies Game, so it can be driven with Engine.Run
+type SceneStack struct {
+	engine     *Engine
+	entries    []sceneEntry
+	transition *activeTransition
+}
+
+// sceneEntry tracks a scene on the stack and whether it draws over the scene beneath it
+type sceneEntry struct {
+	overlay bool
+	scene   Scene
+}
+
+// activeTransition is a transition in progress between what the stack looked like before and after a change
+type activeTransition struct {
+	elapsed    time.Duration
+	from       []sceneEntry
+	transition Transition
+	// exiting are the scenes to Exit once the transition is finished
+	exiting []Scene
+}
+
+// NewSceneStack creates an empty scene stack for the engine
+func NewSceneStack(e *Engine) *SceneStack {
+	return &SceneStack{engine: e}
+}
+
+// Push adds a scene to the top of the stack, covering the scene beneath it.
+//   transition may be nil, or last no time, to switch immediately
+func (s *SceneStack) Push(scene Scene, transition Transition) {
+	s.change(transition, func() []Scene {
+		s.entries = append(s.entries, sceneEntry{scene: scene})
+		s.enter(scene)
+		return nil
+	})
+}
+
+// PushOverlay adds a scene to the top of the stack that is drawn over the scene beneath it,
+//   like a pause menu over a paused level. The scene underneath isn't updated until the overlay is popped.
+func (s *SceneStack) PushOverlay(scene Scene, transition Transition) {
+	s.change(transition, func() []Scene {
+		s.entries = append(s.entries, sceneEntry{overlay: true, scene: scene})
+		s.enter(scene)
+		return nil
+	})
+}
+
+// Pop removes the top scene of the stack
+func (s *SceneStack) Pop(transition Transition) {
+	if len(s.entries) == 0 {
+		return
+	}
+	s.change(transition, func() []Scene {
+		top := s.entries[len(s.entries)-1]
+		s.entries = s.entries[:len(s.entries)-1]
+		return []Scene{top.scene}
+	})
+}
+
+// Replace swaps the top scene of the stack for another, eg: going from the title menu to the first level
+func (s *SceneStack) Replace(scene Scene, transition Transition) {
+	s.change(transition, func() []Scene {
+		var exiting []Scene
+		if len(s.entries) > 0 {
+			exiting = append(exiting, s.entries[len(s.entries)-1].scene)
+			s.entries = s.entries[:len(s.entries)-1]
+		}
+		s.entries = append(s.entries, sceneEntry{scene: scene})
+		s.enter(scene)
+		return exiting
+	})
+}
+
+// Top returns the scene on top of the stack, or nil if the stack is empty
+func (s *SceneStack) Top() Scene {
+	if len(s.entries) == 0 {
+		return nil
+	}
+	return s.entries[len(s.entries)-1].scene
+}
+
+// Len returns how many scenes are on the stack
+func (s *SceneStack) Len() int {
+	return len(s.entries)
+}
+
+// Transitioning reports whether a transition between scenes is playing
+func (s *SceneStack) Transitioning() bool {
+	return s.transition != nil
+}
+
+// change applies a mutation to the stack, exiting the scenes it returns either right away
+//   or once the transition has finished playing
+func (s *SceneStack) change(transition Transition, mutate func() []Scene) {
+	// Finish any transition in progress before starting another
+	s.finishTransition()
+
+	from := append([]sceneEntry{}, s.entries...)
+	exiting := mutate()
+	// A transition that takes no time has nothing to play, so it switches immediately too
+	if transition == nil || transition.Duration() <= 0 {
+		for _, scene := range exiting {
+			s.exit(scene)
+		}
+		return
+	}
+	s.transition = &activeTransition{
+		from:       from,
+		transition: transition,
+		exiting:    exiting,
+	}
+}
+
+// finishTransition exits any scenes the current transition was holding on to
+func (s *SceneStack) finishTransition() {
+	if s.transition == nil {
+		return
+	}
+	exiting := s.transition.exiting
+	s.transition = nil
+	for _, scene := range exiting {
+		s.exit(scene)
+	}
+}
+
+// HandleEvent passes events on to the top scene. Events are dropped while a transition plays
+func (s *SceneStack) HandleEvent(event sdl.Event) {
+	if s.transition != nil {
+		return
+	}
+	if top := s.Top(); top != nil {
+		s.assets().WithOwner(top, func() { top.HandleEvent(event) })
+	}
+}
+
+// Update advances any transition, then updates the top scene.
+//   Once the stack is empty there's nothing left to run, so the engine is stopped.
+func (s *SceneStack) Update(dt float64) {
+	if s.transition != nil {
+		s.transition.elapsed += time.Duration(dt * float64(time.Second))
+		if s.transition.elapsed < s.transition.transition.Duration() {
+			return
+		}
+		s.finishTransition()
+	}
+
+	top := s.Top()
+	if top == nil {
+		s.engine.Stop()
+		return
+	}
+	s.assets().WithOwner(top, func() { top.Update(dt) })
+}
+
+// Draw renders the visible scenes from the bottom up, playing any transition between them
+func (s *SceneStack) Draw(renderer Renderer, alpha float64) {
+	if s.transition == nil {
+		s.drawScenes(s.entries, renderer, alpha)
+		return
+	}
+
+	// Transitions always see progress between 0 and 1, even one whose duration has dropped to 0 since it started
+	progress := 1.0
+	if duration := s.transition.transition.Duration(); duration > 0 {
+		progress = math.Max(0, math.Min(float64(s.transition.elapsed)/float64(duration), 1))
+	}
+	from := s.transition.from
+	to := s.entries
+	s.transition.transition.Draw(
+		renderer,
+		progress,
+		func() { s.drawScenes(from, renderer, alpha) },
+		func() { s.drawScenes(to, renderer, alpha) },
+	)
+}
+
+// drawScenes draws the topmost non-overlay scene and every overlay above it
+func (s *SceneStack) drawScenes(entries []sceneEntry, renderer Renderer, alpha float64) {
+	bottom := len(entries) - 1
+	for bottom > 0 && entries[bottom].overlay {
+		bottom--
+	}
+	for i := bottom; i >= 0 && i < len(entries); i++ {
+		scene := entries[i].scene
+		s.assets().WithOwner(scene, func() { scene.Draw(renderer, alpha) })
+	}
+}
+
+// enter enters a scene, with anything it loads held by it
+func (s *SceneStack) enter(scene Scene) {
+	s.assets().WithOwner(scene, func() { scene.Enter(s.engine) })
+}
+
+// exit exits a scene, then releases everything it loaded
+func (s *SceneStack) exit(scene Scene) {
+	assets := s.assets()
+	assets.WithOwner(scene, func() { scene.Exit(s.engine) })
+	assets.ReleaseOwner(scene)
+}
+
+// assets returns the engine's Assets, which may be nil
+func (s *SceneStack) assets() *Assets {
+	if s.engine == nil {
+		return nil
+	}
+	return s.engine.Assets
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// countingScene counts how many times it's entered and exited
+type countingScene struct {
+	entered, exited int
+}
+
+func (c *countingScene) Enter(*Engine)               { c.entered++ }
+func (c *countingScene) Exit(*Engine)                { c.exited++ }
+func (c *countingScene) Update(float64)              {}
+func (c *countingScene) Draw(Renderer, float64)      {}
+func (c *countingScene) HandleEvent(event sdl.Event) {}
+
+// progressTransition records the progress it's drawn at
+type progressTransition struct {
+	duration time.Duration
+	progress []float64
+}
+
+func (p *progressTransition) Duration() time.Duration { return p.duration }
+func (p *progressTransition) Draw(renderer Renderer, progress float64, from, to func()) {
+	p.progress = append(p.progress, progress)
+}
+
+func TestSceneStackZeroDuration(t *testing.T) {
+	s := NewSceneStack(&Engine{})
+	first, second := &countingScene{}, &countingScene{}
+	s.Push(first, nil)
+	s.Replace(second, NewFade(0))
+
+	if s.Transitioning() {
+		t.Error("a fade lasting no time is still playing")
+	}
+	if first.exited != 1 || second.entered != 1 || s.Top() != second {
+		t.Errorf("first exited %d times, second entered %d times, want both once", first.exited, second.entered)
+	}
+	// Drawing the fade would have made a NaN opacity
+	r := NewSoftwareRenderer(1, 1)
+	s.Draw(r, 0)
+	for _, call := range r.Calls {
+		if call.Op == "FillRect" {
+			t.Errorf("a fade was drawn after switching immediately")
+		}
+	}
+}
+
+func TestSceneStackProgress(t *testing.T) {
+	s := NewSceneStack(&Engine{})
+	s.Push(&countingScene{}, nil)
+	transition := &progressTransition{duration: time.Second}
+	s.Push(&countingScene{}, transition)
+
+	r := NewSoftwareRenderer(1, 1)
+	s.Draw(r, 0)
+	s.Update(0.5)
+	s.Draw(r, 0)
+	// A transition that stops taking any time partway through is finished
+	transition.duration = 0
+	s.Draw(r, 0)
+
+	want := []float64{0, 0.5, 1}
+	if len(transition.progress) != len(want) {
+		t.Fatalf("drawn at %v, want %v", transition.progress, want)
+	}
+	for i, progress := range transition.progress {
+		if math.IsNaN(progress) || math.Abs(progress-want[i]) > 1e-9 {
+			t.Errorf("drawn at %v, want %v", transition.progress, want)
+			break
+		}
+	}
+}
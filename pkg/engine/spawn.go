@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Spawn describes an entity to be placed in a level, eg: from a Tiled object layer or a saved level
+type Spawn struct {
+	// Type is the name the entity's factory was registered under
+	Type string
+	Name string
+	// X, Y are the entity's coordinates on the level, in pixels
+	X, Y       int
+	Properties map[string]string
+}
+
+// EntityFactory builds an entity from a spawn record, loading any textures it needs with textures
+type EntityFactory func(spawn Spawn, textures TextureLoader) (Entity, error)
+
+// entityTypes holds the factories registered with RegisterEntity
+var entityTypes = map[string]EntityFactory{}
+
+// RegisterEntity makes an entity type available to levels loaded from disk. Register your own
+//   entity types from an init func, eg: engine.RegisterEntity("chest", newChest)
+func RegisterEntity(typeName string, factory EntityFactory) {
+	entityTypes[typeName] = factory
+}
+
+// EntityTypes returns the names of every registered entity type, sorted
+func EntityTypes() []string {
+	var names []string
+	for name := range entityTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SpawnEntity builds an entity using the factory registered for spawn.Type. Pass Assets as textures
+//   to share the textures of every entity spawned from the same sprite.
+func SpawnEntity(spawn Spawn, textures TextureLoader) (Entity, error) {
+	factory, ok := entityTypes[spawn.Type]
+	if !ok {
+		return nil, fmt.Errorf("engine: no entity type registered as %q", spawn.Type)
+	}
+	return factory(spawn, textures)
+}
+
+// PlaceEntity moves an entity to x, y and adds it to the level
+func (level *Level) PlaceEntity(x, y int, entity Entity) {
+	entity.SetX(float64(x))
+	entity.SetY(float64(y))
+	level.AddEntity(entity)
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SpriteSheet is a texture cut up into frames, either a grid of equally sized frames or named regions
+type SpriteSheet struct {
+	// Frames are the parts of the texture each frame is cut from, by frame number
+	Frames []sdl.Rect
+	// Image is the path of the image the sheet was loaded from, if it was loaded from one
+	Image string
+	// Slices are named areas of the frames, like hitboxes, from sheets loaded with LoadAseprite
+	Slices map[string]*Slice
+	// Sources are where each frame sits in its untrimmed image, for sheets packed with their empty edges trimmed off.
+	//   X, Y is the offset of the frame and W, H is the size of the untrimmed image. They're nil for grid sheets
+	Sources []sdl.Rect
+	Texture Texture
+
+	columns int
+	// origin is where the sheet's image is on the atlas page it was moved to with Atlas.Relocate, 0,0 if it wasn't
+	origin  sdl.Point
+	regions map[string]int
+}
+
+// NewSpriteSheet cuts a texture into a grid of frameW by frameH frames.
+//   Frames are numbered row by row, so frame 0 is the top left and the frame below it is Columns() along.
+func NewSpriteSheet(texture Texture, frameW, frameH int32) (*SpriteSheet, error) {
+	if frameW <= 0 || frameH <= 0 {
+		return nil, fmt.Errorf("engine: sprite sheet frames can't be %dx%d", frameW, frameH)
+	}
+	w, h := texture.Size()
+	sheet := &SpriteSheet{
+		Slices:  map[string]*Slice{},
+		Texture: texture,
+		columns: int(w / frameW),
+		regions: map[string]int{},
+	}
+	for y := int32(0); y+frameH <= h; y += frameH {
+		for x := int32(0); x+frameW <= w; x += frameW {
+			sheet.Frames = append(sheet.Frames, sdl.Rect{X: x, Y: y, W: frameW, H: frameH})
+		}
+	}
+	return sheet, nil
+}
+
+// NewRegionSheet creates a sheet with no frames, for adding named regions to with AddRegion
+func NewRegionSheet(texture Texture) *SpriteSheet {
+	return &SpriteSheet{
+		Slices:  map[string]*Slice{},
+		Texture: texture,
+		regions: map[string]int{},
+	}
+}
+
+// AddRegion adds a named frame cut from rect, returning its frame number
+func (s *SpriteSheet) AddRegion(name string, rect sdl.Rect) int {
+	s.Frames = append(s.Frames, rect)
+	s.regions[name] = len(s.Frames) - 1
+	return len(s.Frames) - 1
+}
+
+// Region returns the frame number of a named region
+func (s *SpriteSheet) Region(name string) (int, bool) {
+	frame, ok := s.regions[name]
+	return frame, ok
+}
+
+// Columns returns how many frames there are in each row of a grid sheet
+func (s *SpriteSheet) Columns() int {
+	return s.columns
+}
+
+// Index returns the number of the frame in a column and row of a grid sheet
+func (s *SpriteSheet) Index(column, row int) int {
+	return row*s.columns + column
+}
+
+// Row returns the numbers of count frames along a row of a grid sheet, starting at column
+func (s *SpriteSheet) Row(row, column, count int) []int {
+	frames := make([]int, count)
+	for i := range frames {
+		frames[i] = s.Index(column+i, row)
+	}
+	return frames
+}
+
+// Frame returns the part of the texture a frame is cut from
+func (s *SpriteSheet) Frame(frame int) (sdl.Rect, bool) {
+	if frame < 0 || frame >= len(s.Frames) {
+		return sdl.Rect{}, false
+	}
+	return s.Frames[frame], true
+}
+
+// Draw draws a frame of the sheet to dst on the screen.
+//   Trimmed frames are drawn where they sat in their untrimmed image, as if dst were the whole image.
+func (s *SpriteSheet) Draw(renderer Renderer, frame int, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	cmd, err := s.Command(frame, *dst, angle, flip)
+	if err != nil {
+		return err
+	}
+	if angle != 0 || flip != sdl.FLIP_NONE {
+		return renderer.CopyEx(s.Texture, &cmd.Src, &cmd.Dst, angle, nil, flip)
+	}
+	return renderer.Copy(s.Texture, &cmd.Src, &cmd.Dst)
+}
+
+// Command returns a draw command that draws a frame of the sheet to dst, like Draw does, for submitting to a RenderQueue.
+//   Set its Layer and SortKey before submitting it.
+func (s *SpriteSheet) Command(frame int, dst sdl.Rect, angle float64, flip sdl.RendererFlip) (DrawCommand, error) {
+	src, ok := s.Frame(frame)
+	if !ok {
+		return DrawCommand{}, fmt.Errorf("engine: sprite sheet has no frame %d", frame)
+	}
+	if frame < len(s.Sources) {
+		source := s.Sources[frame]
+		if source.W > 0 && source.H > 0 && (source.W != src.W || source.H != src.H) {
+			// A flipped frame's offset is measured from the other side
+			x, y := source.X, source.Y
+			if flip&sdl.FLIP_HORIZONTAL != 0 {
+				x = source.W - source.X - src.W
+			}
+			if flip&sdl.FLIP_VERTICAL != 0 {
+				y = source.H - source.Y - src.H
+			}
+			dst = sdl.Rect{
+				X: dst.X + x*dst.W/source.W,
+				Y: dst.Y + y*dst.H/source.H,
+				W: src.W * dst.W / source.W,
+				H: src.H * dst.H / source.H,
+			}
+		}
+	}
+	return DrawCommand{Angle: angle, Dst: dst, Flip: flip, Src: src, Texture: s.Texture}, nil
+}
@@ -2,30 +2,44 @@ package engine
 
 import (
 	"github.com/veandco/go-sdl2/sdl"
-	"github.com/veandco/go-sdl2/ttf"
 )
 
 // Text exists to render text to the screen.
 type Text struct {
+	// Color is what the text is drawn in, its alpha fades the text in and out
 	Color sdl.Color
 	// Effect allows the developer a way to mutate the object
 	Effects []func(*Text)
-	Font    *ttf.Font
-	Surface *sdl.Surface
-	Text    string
-	X, Y    float64
+	// Font is a TrueType font's GlyphAtlas, see Glyphs, or a BitmapFont
+	Font Font
+	Text string
+	X, Y float64
+
+	// glyphs are where each character is drawn from and to, laidOut is what they were laid out from
+	//   so it's only redone when that changes
+	glyphs  []textGlyph
+	laidOut textKey
+	w, h    int32
+}
+
+// textKey is everything a Text's layout depends on, its color is applied as it's drawn
+type textKey struct {
+	font Font
+	text string
+}
+
+// textGlyph is a character of laid out text, Dst is relative to the top left of the text
+type textGlyph struct {
+	Dst, Src sdl.Rect
+	Page     int
 }
 
 // NewText is a helper factory for Text on screen
 // Ex:
-// font, _ := ttf.OpenFont(exampleFont, 32)
-// t := Text{}
-// surface, err := font.RenderUTF8Solid("example", sdl.Color{R: 255, G: 255, B: 255, A:255})
-// t.Font = font
-// t.Surface = surface
-// t.X = 400
-// t.Y = 400
-func NewText(font *ttf.Font, text string, x float64, y float64) *Text {
+// font, _ := engine.OpenFont(exampleFont, 32)
+// t := engine.NewText(engine.Glyphs(font, false), "example", 400, 400)
+// t.Color = sdl.Color{R: 255, G: 255, B: 255, A: 255}
+func NewText(font Font, text string, x float64, y float64) *Text {
 	return &Text{
 		Font: font,
 		Text: text,
@@ -34,18 +48,46 @@ func NewText(font *ttf.Font, text string, x float64, y float64) *Text {
 	}
 }
 
-// Draw for Text requires creating the surface, then copying the surface object onto a rectangle,
-//   then rendering that rectangle to the screen
-func (t *Text) Draw(renderer *sdl.Renderer, x, y int) {
-	surface, err := t.Font.RenderUTF8Solid(t.Text, t.Color)
-	checkErr(err)
-	t.Surface = surface
-	box := sdl.Rect{X: int32(t.X), Y: int32(t.Y), W: t.Surface.W, H: t.Surface.H}
-	var texture *sdl.Texture
-	if texture, err = renderer.CreateTextureFromSurface(surface); err != nil {
-		panic(err)
+// Draw for Text copies each character from its font's glyphs, tinted with the text's color.
+//   TrueType characters are only rendered the first time the font draws them, and the text is only laid out
+//   again when it or its font change.
+func (t *Text) Draw(renderer Renderer, x, y int) {
+	if t.Font == nil || t.Text == "" {
+		return
+	}
+	t.layOut()
+	var texture Texture
+	page := -1
+	for _, glyph := range t.glyphs {
+		if glyph.Page != page {
+			var err error
+			texture, err = t.Font.Texture(renderer, glyph.Page)
+			checkErr(err)
+			texture.SetColorMod(t.Color.R, t.Color.G, t.Color.B)
+			texture.SetAlphaMod(t.Color.A)
+			page = glyph.Page
+		}
+		dst := glyph.Dst
+		dst.X += int32(t.X)
+		dst.Y += int32(t.Y)
+		renderer.Copy(texture, &glyph.Src, &dst)
+	}
+}
+
+// Size returns how wide and high the text is drawn, laying it out if it's changed
+func (t *Text) Size() (int32, int32) {
+	if t.Font == nil || t.Text == "" {
+		return 0, 0
 	}
-	renderer.Copy(texture, nil, &box)
+	t.layOut()
+	return t.w, t.h
+}
+
+// Free lets go of the text's layout, the glyphs it was drawn with stay with its font
+func (t *Text) Free() {
+	t.glyphs = nil
+	t.laidOut = textKey{}
+	t.w, t.h = 0, 0
 }
 
 // Update exists to fulfill the entities interface contract
@@ -54,3 +96,42 @@ func (t *Text) Update(x, y int) {
 		effect(t)
 	}
 }
+
+// layOut places each character of the text after the last, kerned, starting a new line at each newline
+func (t *Text) layOut() {
+	key := textKey{font: t.Font, text: t.Text}
+	if t.glyphs != nil && t.laidOut == key {
+		return
+	}
+
+	t.glyphs = t.glyphs[:0]
+	height, skip := t.Font.Ascent()+t.Font.Descent(), t.Font.LineSkip()
+	var penX, penY int32
+	var prev rune
+	t.w, t.h = 0, height
+	for _, r := range t.Text {
+		if r == '\n' {
+			penX, penY, prev = 0, penY+skip, 0
+			t.h = penY + height
+			continue
+		}
+		glyph, err := t.Font.Glyph(r)
+		checkErr(err)
+		if prev != 0 {
+			penX += t.Font.Kerning(prev, r)
+		}
+		if glyph.Src.W > 0 && glyph.Src.H > 0 {
+			dst := sdl.Rect{X: penX + glyph.XOffset, Y: penY + glyph.YOffset, W: glyph.Src.W, H: glyph.Src.H}
+			t.glyphs = append(t.glyphs, textGlyph{Dst: dst, Page: glyph.Page, Src: glyph.Src})
+		}
+		penX += glyph.Advance
+		if penX > t.w {
+			t.w = penX
+		}
+		prev = r
+	}
+	if t.glyphs == nil {
+		t.glyphs = []textGlyph{}
+	}
+	t.laidOut = key
+}
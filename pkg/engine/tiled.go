@@ -0,0 +1,800 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Flags Tiled stores in the high bits of a tile's global id
+const (
+	tiledFlipHorizontal = 0x80000000
+	tiledFlipVertical   = 0x40000000
+	tiledFlipDiagonal   = 0x20000000
+	tiledRotateHex      = 0x10000000
+	tiledFlagMask       = tiledFlipHorizontal | tiledFlipVertical | tiledFlipDiagonal | tiledRotateHex
+)
+
+// tiledMap is a Tiled map read from either TMX or JSON
+type tiledMap struct {
+	Width, Height         int
+	TileWidth, TileHeight int
+	Tilesets              []*tiledTileset
+	TileLayers            []tiledTileLayer
+	Objects               []tiledObject
+
+	// layers counts tile layers as they're added, chunks of the same layer share a z index
+	layers int
+}
+
+// tiledTileset is a tileset, either a single image sliced into tiles or a collection of images
+type tiledTileset struct {
+	FirstGID              uint32
+	Name                  string
+	TileWidth, TileHeight int
+	Spacing, Margin       int
+	Columns               int
+	Image                 string
+	// Images holds the image of each tile in an image collection tileset, by local tile id
+	Images map[uint32]string
+	// Collisions holds the "collision" property of each tile that has one, by local tile id
+	Collisions map[uint32]Collision
+
+	texture  Texture
+	textures map[uint32]Texture
+}
+
+// tiledTileLayer is a layer of global tile ids, cells are stored row by row
+type tiledTileLayer struct {
+	Name string
+	// Z is the layer's index in the stack of tile layers
+	Z             int
+	Width, Height int
+	// X, Y offset the layer in tiles, used by the chunks of infinite maps
+	X, Y int
+	GIDs []uint32
+}
+
+// tiledObject is an object from an object layer
+type tiledObject struct {
+	Name       string
+	Type       string
+	X, Y       float64
+	Properties map[string]string
+}
+
+// LoadTiledLevel builds a level from a map made in the Tiled editor (https://www.mapeditor.org).
+//   Both TMX (XML) and TMJ (JSON) maps are supported, with embedded or external tilesets. Tiles must be square.
+//   Each tile layer becomes a layer of the TileMap in order, so the first layer is drawn first.
+//   Objects in object layers are spawned with SpawnEntity using their class (or type) as the entity type,
+//   any object whose type isn't registered is skipped.
+//   Tiles with a "collision" property, eg: "solid" or "one_way", collide as that Collision.
+func LoadTiledLevel(path string, renderer Renderer) (*Level, error) {
+	return loadTiledLevel(path, renderer, renderer)
+}
+
+// loadTiledLevel builds a level from a Tiled map, spawning its objects with entities loading their textures.
+//   The tilesets are loaded with renderer, since Level.Destroy frees them.
+//   A nil entities skips spawning the objects, for when only the tiles are wanted.
+func loadTiledLevel(path string, renderer Renderer, entities TextureLoader) (*Level, error) {
+	data, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tm *tiledMap
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx", ".xml":
+		tm, err = parseTMX(data, filepath.Dir(path))
+	case ".tmj", ".json":
+		tm, err = parseTMJ(data, filepath.Dir(path))
+	default:
+		return nil, fmt.Errorf("engine: %s isn't a Tiled map", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("engine: loading %s: %v", path, err)
+	}
+	// Levels index their tiles by a single TileSize, so tiles have to be square
+	if tm.TileWidth != tm.TileHeight {
+		return nil, fmt.Errorf("engine: %s has %dx%d tiles, only square tiles are supported", path, tm.TileWidth, tm.TileHeight)
+	}
+
+	level := &Level{
+		Camera:      NewCamera(640, 480),
+		TileSize:    tm.TileWidth,
+		ScrollSpeed: 8,
+		Sounds:      make(map[string][]*mix.Chunk),
+		Textures:    map[string]Texture{},
+	}
+
+	// Free the textures of the tilesets already loaded if a later one, or anything after, fails
+	for _, ts := range tm.Tilesets {
+		if err := ts.load(renderer, level.Textures); err != nil {
+			level.Destroy()
+			return nil, err
+		}
+	}
+
+	// Chunks of infinite maps can sit at negative coordinates, so shift everything to start at 0,0
+	var minX, minY, maxX, maxY int
+	maxX, maxY = tm.Width, tm.Height
+	for _, layer := range tm.TileLayers {
+		if layer.X < minX {
+			minX = layer.X
+		}
+		if layer.Y < minY {
+			minY = layer.Y
+		}
+		if layer.X+layer.Width > maxX {
+			maxX = layer.X + layer.Width
+		}
+		if layer.Y+layer.Height > maxY {
+			maxY = layer.Y + layer.Height
+		}
+	}
+	level.TileMap = NewTileMap(maxX-minX, maxY-minY)
+	level.XSize = level.TileMap.Width * tm.TileWidth
+	level.YSize = level.TileMap.Height * tm.TileHeight
+	level.Camera.Bounds = level.Bounds()
+
+	// Tiles are decoded once per global id and flip combination, then shared through the tileset
+	ids := map[uint32]TileID{}
+	for _, layer := range tm.TileLayers {
+		if layer.Width <= 0 {
+			continue
+		}
+		for i, gid := range layer.GIDs {
+			if gid&^tiledFlagMask == 0 {
+				continue
+			}
+			id, ok := ids[gid]
+			if !ok {
+				tile, found := tm.tile(gid)
+				if !found {
+					level.Destroy()
+					return nil, fmt.Errorf("engine: %s layer %q has tile %d which isn't in any tileset", path, layer.Name, gid&^tiledFlagMask)
+				}
+				if id, err = level.TileMap.Tileset.Add(tile); err != nil {
+					level.Destroy()
+					return nil, fmt.Errorf("engine: %s: %v", path, err)
+				}
+				ids[gid] = id
+			}
+			x := layer.X + i%layer.Width - minX
+			y := layer.Y + i/layer.Width - minY
+			level.TileMap.Set(x, y, layer.Z, id)
+		}
+	}
+
+	if entities == nil {
+		return level, nil
+	}
+	// Objects are shifted along with the tiles, so they stay where they were on an infinite map
+	for _, obj := range tm.Objects {
+		spawn := Spawn{
+			Type:       obj.Type,
+			Name:       obj.Name,
+			X:          int(obj.X) - minX*tm.TileWidth,
+			Y:          int(obj.Y) - minY*tm.TileHeight,
+			Properties: obj.Properties,
+		}
+		if _, ok := entityTypes[spawn.Type]; !ok {
+			continue
+		}
+		entity, err := SpawnEntity(spawn, entities)
+		if err != nil {
+			level.Destroy()
+			return nil, err
+		}
+		level.PlaceEntity(spawn.X, spawn.Y, entity)
+	}
+
+	return level, nil
+}
+
+// tile looks up a global tile id in the map's tilesets, decoding its flip flags
+func (tm *tiledMap) tile(gid uint32) (Tile, bool) {
+	flags := gid & tiledFlagMask
+	gid &^= tiledFlagMask
+
+	// Tilesets are sorted by first gid, the last one that starts at or before gid has the tile
+	var ts *tiledTileset
+	for _, candidate := range tm.Tilesets {
+		if candidate.FirstGID <= gid {
+			ts = candidate
+		}
+	}
+	if ts == nil {
+		return Tile{}, false
+	}
+	id := gid - ts.FirstGID
+
+	tile := Tile{Collision: ts.Collisions[id], Name: fmt.Sprintf("%s:%d", ts.Name, id)}
+	if texture, ok := ts.textures[id]; ok {
+		w, h := texture.Size()
+		tile.Texture = texture
+		tile.X1, tile.Y1 = w, h
+	} else {
+		if ts.texture == nil || ts.Columns <= 0 {
+			return Tile{}, false
+		}
+		col := int(id) % ts.Columns
+		row := int(id) / ts.Columns
+		tile.Texture = ts.texture
+		tile.X0 = int32(ts.Margin + col*(ts.TileWidth+ts.Spacing))
+		tile.Y0 = int32(ts.Margin + row*(ts.TileHeight+ts.Spacing))
+		tile.X1 = tile.X0 + int32(ts.TileWidth)
+		tile.Y1 = tile.Y0 + int32(ts.TileHeight)
+	}
+
+	// Tiled flips diagonally (swapping x and y) before flipping horizontally and vertically.
+	//   SDL flips before rotating clockwise, so the diagonal cases become rotations.
+	h := flags&tiledFlipHorizontal != 0
+	v := flags&tiledFlipVertical != 0
+	if flags&tiledFlipDiagonal != 0 {
+		switch {
+		case h && v:
+			tile.Angle, tile.Flip = 90, sdl.FLIP_HORIZONTAL
+		case h:
+			tile.Angle = 90
+		case v:
+			tile.Angle = 270
+		default:
+			tile.Angle, tile.Flip = 90, sdl.FLIP_VERTICAL
+		}
+	} else {
+		if h {
+			tile.Flip |= sdl.FLIP_HORIZONTAL
+			// A mirrored slope slopes the other way
+			switch tile.Collision {
+			case CollisionSlopeUp:
+				tile.Collision = CollisionSlopeDown
+			case CollisionSlopeDown:
+				tile.Collision = CollisionSlopeUp
+			}
+		}
+		if v {
+			tile.Flip |= sdl.FLIP_VERTICAL
+		}
+	}
+	return tile, true
+}
+
+// setCollision sets a tile's collision from the value of its "collision" property, eg: "solid"
+func (ts *tiledTileset) setCollision(id uint32, value string) error {
+	collision, err := ParseCollision(value)
+	if err != nil {
+		return fmt.Errorf("tileset %s tile %d: %v", ts.Name, id, err)
+	}
+	ts.Collisions[id] = collision
+	return nil
+}
+
+// load creates the textures for a tileset, adding them to textures by path
+func (ts *tiledTileset) load(renderer Renderer, textures map[string]Texture) error {
+	if ts.Image != "" {
+		texture, err := loadShared(renderer, textures, ts.Image)
+		if err != nil {
+			return err
+		}
+		ts.texture = texture
+		if ts.Columns <= 0 {
+			w, _ := texture.Size()
+			ts.Columns = (int(w) - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+		}
+	}
+	ts.textures = make(map[uint32]Texture)
+	for id, image := range ts.Images {
+		texture, err := loadShared(renderer, textures, image)
+		if err != nil {
+			return err
+		}
+		ts.textures[id] = texture
+	}
+	return nil
+}
+
+// loadShared loads the texture at path once, tilesets using the same image share it through textures
+func loadShared(renderer Renderer, textures map[string]Texture, path string) (Texture, error) {
+	if texture, ok := textures[path]; ok {
+		return texture, nil
+	}
+	texture, err := renderer.LoadTexture(path)
+	if err != nil {
+		return nil, err
+	}
+	textures[path] = texture
+	return texture, nil
+}
+
+// decodeTiledData turns a layer's encoded data into global tile ids
+func decodeTiledData(encoding, compression, data string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(data, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, err
+		}
+		switch compression {
+		case "":
+		case "zlib":
+			r, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				return nil, err
+			}
+			if raw, err = ioutil.ReadAll(r); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			r, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				return nil, err
+			}
+			if raw, err = ioutil.ReadAll(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported compression %q", compression)
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("tile data is %d bytes, which isn't a whole number of tiles", len(raw))
+		}
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// TMX (XML) format
+
+type tmxMap struct {
+	Width      int          `xml:"width,attr"`
+	Height     int          `xml:"height,attr"`
+	TileWidth  int          `xml:"tilewidth,attr"`
+	TileHeight int          `xml:"tileheight,attr"`
+	Tilesets   []tmxTileset `xml:"tileset"`
+	// Layers are the map's layers, object groups and groups in the order they're stacked
+	Layers []tmxLayer `xml:",any"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32    `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Margin     int       `xml:"margin,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Image      *tmxImage `xml:"image"`
+	Tiles      []struct {
+		ID         uint32        `xml:"id,attr"`
+		Image      *tmxImage     `xml:"image"`
+		Properties []tmxProperty `xml:"properties>property"`
+	} `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+// tmxLayer is any element that can hold a place in the stack of layers, told apart by XMLName:
+//   a <layer> of tiles, an <objectgroup>, or a <group> of more of them. They're decoded into the one slice
+//   so the order they're stacked in is kept, and anything else is skipped.
+type tmxLayer struct {
+	XMLName xml.Name
+	Name    string  `xml:"name,attr"`
+	Width   int     `xml:"width,attr"`
+	Height  int     `xml:"height,attr"`
+	Data    tmxData `xml:"data"`
+	// Objects are the objects of an object group
+	Objects []tmxObject `xml:"object"`
+	// Layers are the layers of a group
+	Layers []tmxLayer `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	Chunks []struct {
+		X      int    `xml:"x,attr"`
+		Y      int    `xml:"y,attr"`
+		Width  int    `xml:"width,attr"`
+		Height int    `xml:"height,attr"`
+		Text   string `xml:",chardata"`
+		Tiles  []struct {
+			GID uint32 `xml:"gid,attr"`
+		} `xml:"tile"`
+	} `xml:"chunk"`
+}
+
+type tmxObject struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+// parseTMX reads a TMX map, dir is where paths in the map are relative to
+func parseTMX(data []byte, dir string) (*tiledMap, error) {
+	var m tmxMap
+	if err := xml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	tm := &tiledMap{
+		Width:      m.Width,
+		Height:     m.Height,
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+	}
+
+	for _, ts := range m.Tilesets {
+		tileset, err := ts.convert(dir)
+		if err != nil {
+			return nil, err
+		}
+		tm.Tilesets = append(tm.Tilesets, tileset)
+	}
+	if err := tm.addTMXLayers(m.Layers); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// addTMXLayers flattens layers, and the layers of any groups, into the map in the order they're stacked
+func (tm *tiledMap) addTMXLayers(layers []tmxLayer) error {
+	for _, layer := range layers {
+		switch layer.XMLName.Local {
+		case "layer":
+			if err := tm.addTMXTileLayer(layer); err != nil {
+				return err
+			}
+		case "objectgroup":
+			for _, obj := range layer.Objects {
+				props := map[string]string{}
+				for _, p := range obj.Properties {
+					props[p.Name] = p.Value
+					// Multi-line string properties keep their value in the element body
+					if p.Value == "" {
+						props[p.Name] = p.Text
+					}
+				}
+				typ := obj.Class
+				if typ == "" {
+					typ = obj.Type
+				}
+				tm.Objects = append(tm.Objects, tiledObject{Name: obj.Name, Type: typ, X: obj.X, Y: obj.Y, Properties: props})
+			}
+		case "group":
+			if err := tm.addTMXLayers(layer.Layers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addTMXTileLayer adds a tile layer to the top of the map, as a chunk per layer for infinite maps
+func (tm *tiledMap) addTMXTileLayer(layer tmxLayer) error {
+	data := layer.Data
+	z := tm.layers
+	tm.layers++
+	if len(data.Chunks) == 0 {
+		gids, err := tmxGIDs(data.Encoding, data.Compression, data.Text, data.Tiles)
+		if err != nil {
+			return err
+		}
+		tm.TileLayers = append(tm.TileLayers, tiledTileLayer{Name: layer.Name, Z: z, Width: layer.Width, Height: layer.Height, GIDs: gids})
+	}
+	for _, chunk := range data.Chunks {
+		gids, err := tmxGIDs(data.Encoding, data.Compression, chunk.Text, chunk.Tiles)
+		if err != nil {
+			return err
+		}
+		tm.TileLayers = append(tm.TileLayers, tiledTileLayer{
+			Name:   layer.Name,
+			Z:      z,
+			Width:  chunk.Width,
+			Height: chunk.Height,
+			X:      chunk.X,
+			Y:      chunk.Y,
+			GIDs:   gids,
+		})
+	}
+	return nil
+}
+
+// tmxGIDs decodes layer data, which is either encoded text or a list of <tile> elements
+func tmxGIDs(encoding, compression, text string, tiles []struct {
+	GID uint32 `xml:"gid,attr"`
+}) ([]uint32, error) {
+	if encoding == "" {
+		gids := make([]uint32, len(tiles))
+		for i, t := range tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	}
+	return decodeTiledData(encoding, compression, text)
+}
+
+// convert loads an external tileset if there is one and resolves image paths relative to dir
+func (ts tmxTileset) convert(dir string) (*tiledTileset, error) {
+	firstGID := ts.FirstGID
+	if ts.Source != "" {
+		path := filepath.Join(dir, ts.Source)
+		data, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(filepath.Ext(path), ".tsx") || strings.EqualFold(filepath.Ext(path), ".xml") {
+			var external tmxTileset
+			if err := xml.Unmarshal(data, &external); err != nil {
+				return nil, fmt.Errorf("tileset %s: %v", path, err)
+			}
+			external.FirstGID = firstGID
+			return external.convert(filepath.Dir(path))
+		}
+		var external tmjTileset
+		if err := json.Unmarshal(data, &external); err != nil {
+			return nil, fmt.Errorf("tileset %s: %v", path, err)
+		}
+		external.FirstGID = firstGID
+		return external.convert(filepath.Dir(path))
+	}
+
+	tileset := &tiledTileset{
+		FirstGID:   firstGID,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		Spacing:    ts.Spacing,
+		Margin:     ts.Margin,
+		Columns:    ts.Columns,
+		Images:     map[uint32]string{},
+		Collisions: map[uint32]Collision{},
+	}
+	if ts.Image != nil {
+		tileset.Image = filepath.Join(dir, ts.Image.Source)
+	}
+	for _, tile := range ts.Tiles {
+		if tile.Image != nil {
+			tileset.Images[tile.ID] = filepath.Join(dir, tile.Image.Source)
+		}
+		for _, p := range tile.Properties {
+			if p.Name == "collision" {
+				if err := tileset.setCollision(tile.ID, p.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return tileset, nil
+}
+
+// TMJ (JSON) format
+
+type tmjMap struct {
+	Width      int          `json:"width"`
+	Height     int          `json:"height"`
+	TileWidth  int          `json:"tilewidth"`
+	TileHeight int          `json:"tileheight"`
+	Tilesets   []tmjTileset `json:"tilesets"`
+	Layers     []tmjLayer   `json:"layers"`
+}
+
+type tmjTileset struct {
+	FirstGID   uint32 `json:"firstgid"`
+	Source     string `json:"source"`
+	Name       string `json:"name"`
+	TileWidth  int    `json:"tilewidth"`
+	TileHeight int    `json:"tileheight"`
+	Spacing    int    `json:"spacing"`
+	Margin     int    `json:"margin"`
+	Columns    int    `json:"columns"`
+	Image      string `json:"image"`
+	Tiles      []struct {
+		ID         uint32 `json:"id"`
+		Image      string `json:"image"`
+		Properties []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"properties"`
+	} `json:"tiles"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []struct {
+		X      int             `json:"x"`
+		Y      int             `json:"y"`
+		Width  int             `json:"width"`
+		Height int             `json:"height"`
+		Data   json.RawMessage `json:"data"`
+	} `json:"chunks"`
+	Objects []struct {
+		Name       string  `json:"name"`
+		Type       string  `json:"type"`
+		Class      string  `json:"class"`
+		X          float64 `json:"x"`
+		Y          float64 `json:"y"`
+		Properties []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"properties"`
+	} `json:"objects"`
+	Layers []tmjLayer `json:"layers"`
+}
+
+// parseTMJ reads a JSON map, dir is where paths in the map are relative to
+func parseTMJ(data []byte, dir string) (*tiledMap, error) {
+	var m tmjMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	tm := &tiledMap{
+		Width:      m.Width,
+		Height:     m.Height,
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+	}
+	for _, ts := range m.Tilesets {
+		tileset, err := ts.convert(dir)
+		if err != nil {
+			return nil, err
+		}
+		tm.Tilesets = append(tm.Tilesets, tileset)
+	}
+	if err := tm.addTMJLayers(m.Layers); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// addTMJLayers flattens layers, and the layers of any groups, into the map
+func (tm *tiledMap) addTMJLayers(layers []tmjLayer) error {
+	for _, layer := range layers {
+		switch layer.Type {
+		case "tilelayer":
+			z := tm.layers
+			tm.layers++
+			if len(layer.Chunks) == 0 {
+				gids, err := tmjGIDs(layer.Encoding, layer.Compression, layer.Data)
+				if err != nil {
+					return err
+				}
+				tm.TileLayers = append(tm.TileLayers, tiledTileLayer{Name: layer.Name, Z: z, Width: layer.Width, Height: layer.Height, GIDs: gids})
+			}
+			for _, chunk := range layer.Chunks {
+				gids, err := tmjGIDs(layer.Encoding, layer.Compression, chunk.Data)
+				if err != nil {
+					return err
+				}
+				tm.TileLayers = append(tm.TileLayers, tiledTileLayer{
+					Name:   layer.Name,
+					Z:      z,
+					Width:  chunk.Width,
+					Height: chunk.Height,
+					X:      chunk.X,
+					Y:      chunk.Y,
+					GIDs:   gids,
+				})
+			}
+		case "objectgroup":
+			for _, obj := range layer.Objects {
+				props := map[string]string{}
+				for _, p := range obj.Properties {
+					props[p.Name] = fmt.Sprint(p.Value)
+				}
+				typ := obj.Class
+				if typ == "" {
+					typ = obj.Type
+				}
+				tm.Objects = append(tm.Objects, tiledObject{Name: obj.Name, Type: typ, X: obj.X, Y: obj.Y, Properties: props})
+			}
+		case "group":
+			if err := tm.addTMJLayers(layer.Layers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tmjGIDs decodes layer data, which is either an array of ids or an encoded string
+func tmjGIDs(encoding, compression string, data json.RawMessage) ([]uint32, error) {
+	if encoding == "base64" {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, err
+		}
+		return decodeTiledData(encoding, compression, text)
+	}
+	var gids []uint32
+	if err := json.Unmarshal(data, &gids); err != nil {
+		return nil, err
+	}
+	return gids, nil
+}
+
+// convert loads an external tileset if there is one and resolves image paths relative to dir
+func (ts tmjTileset) convert(dir string) (*tiledTileset, error) {
+	if ts.Source != "" {
+		// An external tileset can be TSX even when the map is JSON
+		return tmxTileset{FirstGID: ts.FirstGID, Source: ts.Source}.convert(dir)
+	}
+	tileset := &tiledTileset{
+		FirstGID:   ts.FirstGID,
+		Name:       ts.Name,
+		TileWidth:  ts.TileWidth,
+		TileHeight: ts.TileHeight,
+		Spacing:    ts.Spacing,
+		Margin:     ts.Margin,
+		Columns:    ts.Columns,
+		Images:     map[uint32]string{},
+		Collisions: map[uint32]Collision{},
+	}
+	if ts.Image != "" {
+		tileset.Image = filepath.Join(dir, ts.Image)
+	}
+	for _, tile := range ts.Tiles {
+		if tile.Image != "" {
+			tileset.Images[tile.ID] = filepath.Join(dir, tile.Image)
+		}
+		for _, p := range tile.Properties {
+			if p.Name == "collision" {
+				if err := tileset.setCollision(tile.ID, fmt.Sprint(p.Value)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return tileset, nil
+}
@@ -0,0 +1,302 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// withFS swaps FS for files until the test is over
+func withFS(t *testing.T, files fstest.MapFS) {
+	old := FS
+	FS = files
+	t.Cleanup(func() { FS = old })
+}
+
+// testPNG encodes a blank w x h png
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// loadCounter is a software renderer that keeps the textures it loads
+type loadCounter struct {
+	*SoftwareRenderer
+	loaded []*ImageTexture
+}
+
+func (l *loadCounter) LoadTexture(path string) (Texture, error) {
+	texture, err := l.SoftwareRenderer.LoadTexture(path)
+	if err == nil {
+		l.loaded = append(l.loaded, texture.(*ImageTexture))
+	}
+	return texture, err
+}
+
+func TestLoadTiledInfinite(t *testing.T) {
+	// One chunk up and to the left of the origin and one at it, with a marker in the top left chunk
+	chunk := "[" + strings.TrimSuffix(strings.Repeat("1,", 16*16), ",") + "]"
+	withFS(t, fstest.MapFS{
+		"maps/tiles.png": {Data: testPNG(t, 32, 16)},
+		"maps/infinite.tmj": {Data: []byte(`{
+			"width": 16, "height": 16, "tilewidth": 16, "tileheight": 16, "infinite": true,
+			"tilesets": [{"firstgid": 1, "name": "tiles", "tilewidth": 16, "tileheight": 16, "columns": 2, "image": "tiles.png"}],
+			"layers": [
+				{"type": "tilelayer", "name": "ground", "chunks": [
+					{"x": -16, "y": -16, "width": 16, "height": 16, "data": ` + chunk + `},
+					{"x": 0, "y": 0, "width": 16, "height": 16, "data": ` + chunk + `}
+				]},
+				{"type": "objectgroup", "objects": [{"name": "a", "class": "test_marker", "x": 8, "y": -40}]}
+			]
+		}`)},
+	})
+
+	level, err := LoadTiledLevel("maps/infinite.tmj", NewSoftwareRenderer(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.TileMap.Width != 32 || level.TileMap.Height != 32 || level.XSize != 512 || level.YSize != 512 {
+		t.Errorf("level is %dx%d tiles, %dx%d pixels, want 32x32 and 512x512", level.TileMap.Width, level.TileMap.Height, level.XSize, level.YSize)
+	}
+	if level.TileMap.Get(0, 0, 0) == 0 || level.TileMap.Get(31, 31, 0) == 0 || level.TileMap.Get(16, 0, 0) != 0 {
+		t.Errorf("chunks aren't shifted to start at 0,0")
+	}
+	items := level.Entities.Items()
+	if len(items) != 1 {
+		t.Fatalf("level has %d entities, want 1", len(items))
+	}
+	// Shifted by the same 16 tiles as the chunk it's in
+	if x, y := items[0].(Entity).GetLevelCoords(); x != 8+256 || y != -40+256 {
+		t.Errorf("marker is at %d,%d, want %d,%d", x, y, 8+256, -40+256)
+	}
+}
+
+func TestLoadTiledNonSquare(t *testing.T) {
+	withFS(t, fstest.MapFS{
+		"wide.tmj": {Data: []byte(`{"width": 1, "height": 1, "tilewidth": 32, "tileheight": 16, "layers": []}`)},
+	})
+	if _, err := LoadTiledLevel("wide.tmj", NewSoftwareRenderer(1, 1)); err == nil {
+		t.Error("loaded a map with 32x16 tiles")
+	}
+}
+
+func TestLoadTiledMissingTileset(t *testing.T) {
+	withFS(t, fstest.MapFS{
+		"tiles.png": {Data: testPNG(t, 16, 16)},
+		"broken.tmx": {Data: []byte(`<map width="1" height="1" tilewidth="16" tileheight="16">
+			<tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16"><image source="tiles.png"/></tileset>
+			<tileset firstgid="2" name="shared" tilewidth="16" tileheight="16"><image source="tiles.png"/></tileset>
+			<tileset firstgid="3" name="missing" tilewidth="16" tileheight="16"><image source="missing.png"/></tileset>
+			<layer name="ground" width="1" height="1"><data encoding="csv">1</data></layer>
+		</map>`)},
+	})
+
+	renderer := &loadCounter{SoftwareRenderer: NewSoftwareRenderer(1, 1)}
+	if _, err := LoadTiledLevel("broken.tmx", renderer); err == nil {
+		t.Fatal("loaded a map with a missing tileset image")
+	}
+	// Tilesets sharing an image share its texture
+	if len(renderer.loaded) != 1 {
+		t.Fatalf("loaded %d textures, want 1", len(renderer.loaded))
+	}
+	if !renderer.loaded[0].destroyed {
+		t.Error("the texture of the tileset loaded before the missing one wasn't destroyed")
+	}
+}
+
+// tileAt returns the tile at x, y on layer z of a level, failing the test if there isn't one
+func tileAt(t *testing.T, level *Level, x, y, z int) Tile {
+	t.Helper()
+	tile, ok := level.TileMap.Tileset.Tile(level.TileMap.Get(x, y, z))
+	if !ok {
+		t.Fatalf("no tile at %d,%d on layer %d", x, y, z)
+	}
+	return tile
+}
+
+// tmjTiles is the tilesets of a JSON map, a single image of three 16x16 tiles in a row
+const tmjTiles = `"tilesets": [{"firstgid": 1, "name": "tiles", "tilewidth": 16, "tileheight": 16, "columns": 3, "image": "tiles.png"}]`
+
+func TestLoadTiledLayerOrder(t *testing.T) {
+	tsx := `<tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" columns="3"><image source="tiles.png"/></tileset>`
+	layer := func(name, gid string) string {
+		return `<layer name="` + name + `" width="1" height="1"><data encoding="csv">` + gid + `</data></layer>`
+	}
+	withFS(t, fstest.MapFS{
+		"tiles.png": {Data: testPNG(t, 48, 16)},
+		// A group between two layers, and one below a layer
+		"between.tmx": {Data: []byte(`<map width="1" height="1" tilewidth="16" tileheight="16">` + tsx +
+			layer("a", "1") + `<group name="g">` + layer("b", "2") + `</group>` + layer("c", "3") + `</map>`)},
+		"below.tmx": {Data: []byte(`<map width="1" height="1" tilewidth="16" tileheight="16">` + tsx +
+			`<group name="g"><objectgroup/>` + layer("a", "1") + `<group>` + layer("b", "2") + `</group></group>` + layer("c", "3") + `</map>`)},
+		"between.tmj": {Data: []byte(`{"width": 1, "height": 1, "tilewidth": 16, "tileheight": 16, ` + tmjTiles + `, "layers": [
+			{"type": "tilelayer", "name": "a", "width": 1, "height": 1, "data": [1]},
+			{"type": "group", "layers": [{"type": "tilelayer", "name": "b", "width": 1, "height": 1, "data": [2]}]},
+			{"type": "tilelayer", "name": "c", "width": 1, "height": 1, "data": [3]}
+		]}`)},
+	})
+
+	for _, path := range []string{"between.tmx", "below.tmx", "between.tmj"} {
+		level, err := LoadTiledLevel(path, NewSoftwareRenderer(1, 1))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if level.TileMap.Layers() != 3 {
+			t.Errorf("%s has %d layers, want 3", path, level.TileMap.Layers())
+			continue
+		}
+		for z, want := range []string{"tiles:0", "tiles:1", "tiles:2"} {
+			if tile := tileAt(t, level, 0, 0, z); tile.Name != want {
+				t.Errorf("%s layer %d is %s, want %s", path, z, tile.Name, want)
+			}
+		}
+	}
+}
+
+func TestLoadTiledFlips(t *testing.T) {
+	const h, v, d = tiledFlipHorizontal, tiledFlipVertical, tiledFlipDiagonal
+	tests := []struct {
+		flags     uint32
+		angle     float64
+		flip      sdl.RendererFlip
+		collision Collision
+	}{
+		{0, 0, sdl.FLIP_NONE, CollisionSlopeUp},
+		{h, 0, sdl.FLIP_HORIZONTAL, CollisionSlopeDown},
+		{v, 0, sdl.FLIP_VERTICAL, CollisionSlopeUp},
+		{h | v, 0, sdl.FLIP_HORIZONTAL | sdl.FLIP_VERTICAL, CollisionSlopeDown},
+		{d, 90, sdl.FLIP_VERTICAL, CollisionSlopeUp},
+		{d | h, 90, sdl.FLIP_NONE, CollisionSlopeUp},
+		{d | v, 270, sdl.FLIP_NONE, CollisionSlopeUp},
+		{d | h | v, 90, sdl.FLIP_HORIZONTAL, CollisionSlopeUp},
+	}
+	var gids []string
+	for _, test := range tests {
+		gids = append(gids, fmt.Sprint(test.flags|1))
+	}
+	withFS(t, fstest.MapFS{
+		"tiles.png": {Data: testPNG(t, 48, 16)},
+		"flips.tmx": {Data: []byte(`<map width="` + fmt.Sprint(len(tests)) + `" height="1" tilewidth="16" tileheight="16">
+			<tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" columns="3">
+				<image source="tiles.png"/>
+				<tile id="0"><properties><property name="collision" value="slope_up"/></properties></tile>
+			</tileset>
+			<layer name="ground" width="` + fmt.Sprint(len(tests)) + `" height="1"><data encoding="csv">` + strings.Join(gids, ",") + `</data></layer>
+		</map>`)},
+	})
+
+	level, err := LoadTiledLevel("flips.tmx", NewSoftwareRenderer(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x, test := range tests {
+		tile := tileAt(t, level, x, 0, 0)
+		if tile.Angle != test.angle || tile.Flip != test.flip || tile.Collision != test.collision {
+			t.Errorf("flags %#x made a tile at %v degrees flipped %v colliding %v, want %v degrees flipped %v colliding %v",
+				test.flags, tile.Angle, tile.Flip, tile.Collision, test.angle, test.flip, test.collision)
+		}
+	}
+}
+
+func TestLoadTiledEncodings(t *testing.T) {
+	gids := []uint32{1, 2, 3, 0, 3, 2}
+	raw := make([]byte, len(gids)*4)
+	for i, gid := range gids {
+		binary.LittleEndian.PutUint32(raw[i*4:], gid)
+	}
+	compress := func(w io.WriteCloser, buf *bytes.Buffer) string {
+		w.Write(raw)
+		w.Close()
+		return base64.StdEncoding.EncodeToString(buf.Bytes())
+	}
+	var zlibbed, gzipped bytes.Buffer
+	data := map[string]string{
+		"csv":    `<data encoding="csv">1,2,3,` + "\n" + `0,3,2</data>`,
+		"xml":    `<data><tile gid="1"/><tile gid="2"/><tile gid="3"/><tile/><tile gid="3"/><tile gid="2"/></data>`,
+		"base64": `<data encoding="base64">` + base64.StdEncoding.EncodeToString(raw) + `</data>`,
+		"zlib":   `<data encoding="base64" compression="zlib">` + compress(zlib.NewWriter(&zlibbed), &zlibbed) + `</data>`,
+		"gzip":   `<data encoding="base64" compression="gzip">` + compress(gzip.NewWriter(&gzipped), &gzipped) + `</data>`,
+	}
+	files := fstest.MapFS{"tiles.png": {Data: testPNG(t, 48, 16)}}
+	for name, d := range data {
+		files[name+".tmx"] = &fstest.MapFile{Data: []byte(`<map width="3" height="2" tilewidth="16" tileheight="16">
+			<tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" columns="3"><image source="tiles.png"/></tileset>
+			<layer name="ground" width="3" height="2">` + d + `</layer>
+		</map>`)}
+	}
+	// and the same base64 data in a JSON map
+	files["base64.tmj"] = &fstest.MapFile{Data: []byte(`{"width": 3, "height": 2, "tilewidth": 16, "tileheight": 16, ` + tmjTiles + `,
+		"layers": [{"type": "tilelayer", "width": 3, "height": 2, "encoding": "base64", "compression": "zlib",
+			"data": "` + base64.StdEncoding.EncodeToString(zlibbed.Bytes()) + `"}]}`)}
+	withFS(t, files)
+
+	for _, path := range []string{"csv.tmx", "xml.tmx", "base64.tmx", "zlib.tmx", "gzip.tmx", "base64.tmj"} {
+		level, err := LoadTiledLevel(path, NewSoftwareRenderer(1, 1))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		for i, gid := range gids {
+			x, y := i%3, i/3
+			if gid == 0 {
+				if id := level.TileMap.Get(x, y, 0); id != 0 {
+					t.Errorf("%s has tile %d at %d,%d, want none", path, id, x, y)
+				}
+				continue
+			}
+			if tile := tileAt(t, level, x, y, 0); tile.Name != fmt.Sprintf("tiles:%d", gid-1) {
+				t.Errorf("%s has %s at %d,%d, want tiles:%d", path, tile.Name, x, y, gid-1)
+			}
+		}
+	}
+}
+
+func TestLoadTiledExternalTileset(t *testing.T) {
+	withFS(t, fstest.MapFS{
+		// The tileset's image is relative to the tileset, not the map
+		"tilesets/tiles.png": {Data: testPNG(t, 36, 16)},
+		"tilesets/walls.tsx": {Data: []byte(`<tileset name="walls" tilewidth="16" tileheight="16" spacing="2" margin="1">
+			<image source="tiles.png"/>
+			<tile id="1"><properties><property name="collision" value="solid"/></properties></tile>
+		</tileset>`)},
+		"maps/external.tmx": {Data: []byte(`<map width="2" height="1" tilewidth="16" tileheight="16">
+			<tileset firstgid="5" source="../tilesets/walls.tsx"/>
+			<layer name="ground" width="2" height="1"><data encoding="csv">5,6</data></layer>
+		</map>`)},
+		"maps/external.tmj": {Data: []byte(`{"width": 2, "height": 1, "tilewidth": 16, "tileheight": 16,
+			"tilesets": [{"firstgid": 5, "source": "../tilesets/walls.tsx"}],
+			"layers": [{"type": "tilelayer", "width": 2, "height": 1, "data": [5, 6]}]}`)},
+	})
+
+	for _, path := range []string{"maps/external.tmx", "maps/external.tmj"} {
+		level, err := LoadTiledLevel(path, NewSoftwareRenderer(1, 1))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if _, ok := level.Textures["tilesets/tiles.png"]; !ok {
+			t.Errorf("%s loaded %v, want tilesets/tiles.png", path, level.Textures)
+		}
+		floor, wall := tileAt(t, level, 0, 0, 0), tileAt(t, level, 1, 0, 0)
+		if floor.Name != "walls:0" || floor.Collision != CollisionNone || floor.X0 != 1 || floor.Y0 != 1 {
+			t.Errorf("%s first tile is %+v, want walls:0 at 1,1 without collision", path, floor)
+		}
+		// Two columns fit across the margin and spacing
+		if wall.Name != "walls:1" || wall.Collision != CollisionSolid || wall.X0 != 19 || wall.X1 != 35 {
+			t.Errorf("%s second tile is %+v, want walls:1 at 19,1 colliding solid", path, wall)
+		}
+	}
+}
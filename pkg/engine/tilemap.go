@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"errors"
+	"math"
+)
+
+// ChunkSize is how many tiles wide and high each chunk of a TileMap is
+const ChunkSize = 32
+
+// chunkArea is how many cells are in a layer of a chunk
+const chunkArea = ChunkSize * ChunkSize
+
+// TileID references a Tile in a Tileset. 0 is always an empty cell
+type TileID uint16
+
+// ErrTilesetFull is returned when a tileset already has as many tiles as a TileID can refer to
+var ErrTilesetFull = errors.New("engine: tileset is full")
+
+// Tileset holds the tiles a TileMap's cells refer to, so each cell stores a small id instead of a whole Tile
+type Tileset struct {
+	ids   map[Tile]TileID
+	tiles []Tile
+}
+
+// NewTileset creates an empty tileset
+func NewTileset() *Tileset {
+	return &Tileset{
+		ids: make(map[Tile]TileID),
+		// Reserve id 0 for empty cells
+		tiles: []Tile{{}},
+	}
+}
+
+// Add returns the id of a tile, adding it to the tileset if it isn't already there.
+//   ErrTilesetFull is returned if it isn't there and there's no id left for it
+func (ts *Tileset) Add(tile Tile) (TileID, error) {
+	if id, ok := ts.ids[tile]; ok {
+		return id, nil
+	}
+	if len(ts.tiles) > math.MaxUint16 {
+		return 0, ErrTilesetFull
+	}
+	id := TileID(len(ts.tiles))
+	ts.tiles = append(ts.tiles, tile)
+	ts.ids[tile] = id
+	return id, nil
+}
+
+// Replace swaps the tile an id refers to, changing every cell that uses it
+func (ts *Tileset) Replace(id TileID, tile Tile) {
+	if id == 0 || int(id) >= len(ts.tiles) {
+		return
+	}
+	if ts.ids[ts.tiles[id]] == id {
+		delete(ts.ids, ts.tiles[id])
+	}
+	ts.tiles[id] = tile
+	if _, ok := ts.ids[tile]; !ok {
+		ts.ids[tile] = id
+	}
+}
+
+// Tile returns the tile with an id, false if it's empty or isn't in the tileset
+func (ts *Tileset) Tile(id TileID) (Tile, bool) {
+	if id == 0 || int(id) >= len(ts.tiles) {
+		return Tile{}, false
+	}
+	return ts.tiles[id], true
+}
+
+// Len returns how many tiles are in the tileset
+func (ts *Tileset) Len() int {
+	return len(ts.tiles) - 1
+}
+
+// TileMap is a grid of tile ids, indexed by tile coordinates rather than pixels.
+//   Cells are stored in ChunkSize x ChunkSize chunks held in a slice, and chunks are only
+//   allocated once something is written to them, so large sparse maps stay small.
+//   Each cell has a stack of layers, layer 0 is drawn first and each layer above is drawn on top of it.
+type TileMap struct {
+	// Width and Height are the size of the map in tiles
+	Width, Height int
+	Tileset       *Tileset
+
+	chunks  []*tileChunk
+	chunksX int
+	layers  int
+}
+
+// tileChunk is a ChunkSize x ChunkSize piece of a TileMap, with a grid of ids per layer
+type tileChunk struct {
+	layers []*[chunkArea]TileID
+}
+
+// NewTileMap creates an empty tile map that is width by height tiles
+func NewTileMap(width, height int) *TileMap {
+	chunksX := (width + ChunkSize - 1) / ChunkSize
+	chunksY := (height + ChunkSize - 1) / ChunkSize
+	return &TileMap{
+		Width:   width,
+		Height:  height,
+		Tileset: NewTileset(),
+		chunks:  make([]*tileChunk, chunksX*chunksY),
+		chunksX: chunksX,
+	}
+}
+
+// Layers returns how many layers deep the map's tallest cell is
+func (m *TileMap) Layers() int {
+	return m.layers
+}
+
+// InBounds reports whether tile coordinates are on the map
+func (m *TileMap) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < m.Width && y < m.Height
+}
+
+// chunk returns the chunk holding a cell and the cell's index in it
+func (m *TileMap) chunk(x, y int) (*tileChunk, int, int) {
+	index := (y/ChunkSize)*m.chunksX + x/ChunkSize
+	cell := (y%ChunkSize)*ChunkSize + x%ChunkSize
+	return m.chunks[index], index, cell
+}
+
+// Get returns the id at a cell and layer, 0 if it's empty or off the map
+func (m *TileMap) Get(x, y, layer int) TileID {
+	if !m.InBounds(x, y) || layer < 0 {
+		return 0
+	}
+	c, _, cell := m.chunk(x, y)
+	if c == nil || layer >= len(c.layers) || c.layers[layer] == nil {
+		return 0
+	}
+	return c.layers[layer][cell]
+}
+
+// Set puts an id at a cell and layer. Coordinates off the map are ignored
+func (m *TileMap) Set(x, y, layer int, id TileID) {
+	if !m.InBounds(x, y) || layer < 0 {
+		return
+	}
+	c, index, cell := m.chunk(x, y)
+	if c == nil {
+		if id == 0 {
+			return
+		}
+		c = &tileChunk{}
+		m.chunks[index] = c
+	}
+	for len(c.layers) <= layer {
+		c.layers = append(c.layers, nil)
+	}
+	if c.layers[layer] == nil {
+		if id == 0 {
+			return
+		}
+		c.layers[layer] = new([chunkArea]TileID)
+	}
+	c.layers[layer][cell] = id
+	if layer >= m.layers {
+		m.layers = layer + 1
+	}
+}
+
+// SetTile adds a tile to the tileset and puts it at a cell and layer
+func (m *TileMap) SetTile(x, y, layer int, tile Tile) error {
+	id, err := m.Tileset.Add(tile)
+	if err != nil {
+		return err
+	}
+	m.Set(x, y, layer, id)
+	return nil
+}
+
+// Push puts a tile on top of a cell's stack, in the layer above its current top tile
+func (m *TileMap) Push(x, y int, tile Tile) error {
+	layer := 0
+	for z := m.layers - 1; z >= 0; z-- {
+		if m.Get(x, y, z) != 0 {
+			layer = z + 1
+			break
+		}
+	}
+	return m.SetTile(x, y, layer, tile)
+}
+
+// Tiles returns the tiles stacked on a cell from the bottom up, skipping empty layers
+func (m *TileMap) Tiles(x, y int) []Tile {
+	var tiles []Tile
+	for z := 0; z < m.layers; z++ {
+		if tile, ok := m.Tileset.Tile(m.Get(x, y, z)); ok {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
@@ -0,0 +1,263 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestTileMapGetSet(t *testing.T) {
+	m := NewTileMap(ChunkSize*2+5, ChunkSize*2+5)
+	// Cells either side of the boundaries between chunks, and the corners of the map
+	cells := [][2]int{
+		{0, 0},
+		{ChunkSize - 1, ChunkSize - 1},
+		{ChunkSize, ChunkSize - 1},
+		{ChunkSize - 1, ChunkSize},
+		{ChunkSize, ChunkSize},
+		{ChunkSize * 2, 3},
+		{m.Width - 1, m.Height - 1},
+	}
+	for i, cell := range cells {
+		m.Set(cell[0], cell[1], 0, TileID(i+1))
+	}
+	for i, cell := range cells {
+		if got := m.Get(cell[0], cell[1], 0); got != TileID(i+1) {
+			t.Errorf("Get(%d, %d, 0) = %d, want %d", cell[0], cell[1], got, i+1)
+		}
+	}
+	// Neighbours in other chunks are left alone
+	for _, cell := range [][2]int{{1, 0}, {ChunkSize + 1, ChunkSize}, {ChunkSize, ChunkSize + 1}, {ChunkSize - 2, ChunkSize - 1}} {
+		if got := m.Get(cell[0], cell[1], 0); got != 0 {
+			t.Errorf("Get(%d, %d, 0) = %d, want it empty", cell[0], cell[1], got)
+		}
+	}
+
+	// Overwriting and clearing a cell
+	m.Set(ChunkSize, ChunkSize, 0, 9)
+	if got := m.Get(ChunkSize, ChunkSize, 0); got != 9 {
+		t.Errorf("Get after overwriting = %d, want 9", got)
+	}
+	m.Set(ChunkSize, ChunkSize, 0, 0)
+	if got := m.Get(ChunkSize, ChunkSize, 0); got != 0 {
+		t.Errorf("Get after clearing = %d, want 0", got)
+	}
+}
+
+func TestTileMapOffMap(t *testing.T) {
+	m := NewTileMap(ChunkSize+1, ChunkSize+1)
+	m.Set(0, 0, 0, 1)
+	// Negative coordinates would land in the first chunk if they weren't checked
+	for _, cell := range [][3]int{{-1, 0, 0}, {0, -1, 0}, {-1, -1, 0}, {-ChunkSize, 0, 0}, {0, 0, -1}, {m.Width, 0, 0}, {0, m.Height, 0}} {
+		m.Set(cell[0], cell[1], cell[2], 2)
+		if got := m.Get(cell[0], cell[1], cell[2]); got != 0 {
+			t.Errorf("Get(%d, %d, %d) = %d, want 0 off the map", cell[0], cell[1], cell[2], got)
+		}
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			want := TileID(0)
+			if x == 0 && y == 0 {
+				want = 1
+			}
+			if got := m.Get(x, y, 0); got != want {
+				t.Fatalf("Get(%d, %d, 0) = %d, want %d after setting cells off the map", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestTileMapSparse(t *testing.T) {
+	m := NewTileMap(ChunkSize*4, ChunkSize*4)
+	// Clearing cells in chunks that were never written doesn't allocate them
+	m.Set(ChunkSize*3, ChunkSize*3, 2, 0)
+	m.Set(ChunkSize+1, 1, 0, 1)
+	allocated := 0
+	for _, c := range m.chunks {
+		if c != nil {
+			allocated++
+		}
+	}
+	if allocated != 1 {
+		t.Errorf("%d chunks are allocated, want 1", allocated)
+	}
+	if m.Layers() != 1 {
+		t.Errorf("Layers() = %d, want 1", m.Layers())
+	}
+}
+
+func TestTileMapPush(t *testing.T) {
+	m := NewTileMap(4, 4)
+	grass, wall := Tile{Name: "grass"}, Tile{Collision: CollisionSolid, Name: "wall"}
+	if err := m.Push(1, 1, grass); err != nil {
+		t.Fatal(err)
+	}
+	m.Push(1, 1, wall)
+	m.Push(2, 2, grass)
+
+	tiles := m.Tiles(1, 1)
+	if len(tiles) != 2 || tiles[0] != grass || tiles[1] != wall {
+		t.Errorf("Tiles(1, 1) = %v, want grass then wall", tiles)
+	}
+	if m.Tileset.Len() != 2 {
+		t.Errorf("tileset has %d tiles, want grass and wall once each", m.Tileset.Len())
+	}
+	if m.Get(2, 2, 0) != m.Get(1, 1, 0) {
+		t.Errorf("the same tile has two ids")
+	}
+}
+
+func TestTilesetFull(t *testing.T) {
+	ts := NewTileset()
+	for i := 1; i <= 0xffff; i++ {
+		id, err := ts.Add(Tile{Name: fmt.Sprint(i)})
+		if err != nil {
+			t.Fatalf("adding tile %d: %v", i, err)
+		}
+		if id != TileID(i) {
+			t.Fatalf("tile %d got id %d", i, id)
+		}
+	}
+	if id, err := ts.Add(Tile{Name: "one too many"}); err != ErrTilesetFull {
+		t.Errorf("Add to a full tileset = %d, %v, want ErrTilesetFull", id, err)
+	}
+	// Tiles that are already there can still be looked up
+	if id, err := ts.Add(Tile{Name: "1"}); err != nil || id != 1 {
+		t.Errorf("Add of an existing tile = %d, %v, want 1", id, err)
+	}
+}
+
+// copyCounter is a renderer that only counts what's copied, so benchmarks measure the tiles and not the pixels
+type copyCounter struct {
+	*SoftwareRenderer
+	copies int
+}
+
+func (c *copyCounter) Copy(texture Texture, src, dst *sdl.Rect) error {
+	c.copies++
+	return nil
+}
+
+func (c *copyCounter) CopyEx(texture Texture, src, dst *sdl.Rect, angle float64, center *sdl.Point, flip sdl.RendererFlip) error {
+	c.copies++
+	return nil
+}
+
+// benchW and benchH are the size in tiles of the level NewRandomizedLevel makes, benchTile the size of its tiles
+const benchW, benchH, benchTile = 250, 188, 32
+
+// benchTileMap builds the benchmark level as a TileMap
+func benchTileMap() *TileMap {
+	m := NewTileMap(benchW, benchH)
+	grass, _ := m.Tileset.Add(Tile{Name: "grass", X1: 16, Y1: 16})
+	wall, _ := m.Tileset.Add(Tile{Collision: CollisionSolid, Name: "wall", X0: 272, X1: 288, Y0: 464, Y1: 480})
+	for x := 0; x < benchW; x++ {
+		for y := 0; y < benchH; y++ {
+			m.Set(x, y, 0, grass)
+			if x == 0 || y == 0 {
+				m.Set(x, y, 1, wall)
+			}
+		}
+	}
+	return m
+}
+
+// benchNestedMap builds the benchmark level the way levels stored their tiles before TileMap
+func benchNestedMap() map[int]map[int][]Tile {
+	grass := Tile{Name: "grass", X1: 16, Y1: 16}
+	wall := Tile{Collision: CollisionSolid, Name: "wall", X0: 272, X1: 288, Y0: 464, Y1: 480}
+	m := map[int]map[int][]Tile{}
+	for x := 0; x < benchW; x++ {
+		m[x] = map[int][]Tile{}
+		for y := 0; y < benchH; y++ {
+			m[x][y] = []Tile{grass}
+			if x == 0 || y == 0 {
+				m[x][y] = append(m[x][y], wall)
+			}
+		}
+	}
+	return m
+}
+
+// BenchmarkTileMapGet looks up the bottom tile of every cell
+func BenchmarkTileMapGet(b *testing.B) {
+	b.Run("TileMap", func(b *testing.B) {
+		m := benchTileMap()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < benchH; y++ {
+				for x := 0; x < benchW; x++ {
+					if _, ok := m.Tileset.Tile(m.Get(x, y, 0)); !ok {
+						b.Fatal("missing tile")
+					}
+				}
+			}
+		}
+	})
+	b.Run("NestedMap", func(b *testing.B) {
+		m := benchNestedMap()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for y := 0; y < benchH; y++ {
+				for x := 0; x < benchW; x++ {
+					if len(m[x][y]) == 0 {
+						b.Fatal("missing tile")
+					}
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkTileMapDraw draws an 800x600 view of the middle of the level, every layer of every tile in it
+func BenchmarkTileMapDraw(b *testing.B) {
+	const firstX, firstY, viewW, viewH = 100, 80, 800 / benchTile, 600 / benchTile
+	texture := NewImageTexture(NewSoftwareRenderer(1, 1).Image())
+	draw := func(renderer Renderer, tile Tile, x, y int) {
+		src := sdl.Rect{X: tile.X0, Y: tile.Y0, W: tile.X1 - tile.X0, H: tile.Y1 - tile.Y0}
+		dst := sdl.Rect{X: int32((x - firstX) * benchTile), Y: int32((y - firstY) * benchTile), W: benchTile, H: benchTile}
+		renderer.Copy(texture, &src, &dst)
+	}
+
+	b.Run("TileMap", func(b *testing.B) {
+		m := benchTileMap()
+		renderer := &copyCounter{SoftwareRenderer: NewSoftwareRenderer(1, 1)}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for z := 0; z < m.Layers(); z++ {
+				for y := firstY; y <= firstY+viewH; y++ {
+					for x := firstX; x <= firstX+viewW; x++ {
+						if tile, ok := m.Tileset.Tile(m.Get(x, y, z)); ok {
+							draw(renderer, tile, x, y)
+						}
+					}
+				}
+			}
+		}
+	})
+	b.Run("NestedMap", func(b *testing.B) {
+		m := benchNestedMap()
+		renderer := &copyCounter{SoftwareRenderer: NewSoftwareRenderer(1, 1)}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for y := firstY; y <= firstY+viewH; y++ {
+				for x := firstX; x <= firstX+viewW; x++ {
+					for _, tile := range m[x][y] {
+						draw(renderer, tile, x, y)
+					}
+				}
+			}
+		}
+	})
+	// Level draws the same view through its render queue, as games do
+	b.Run("Level", func(b *testing.B) {
+		level := &Level{Camera: NewCamera(800, 600), Texture: texture, TileMap: benchTileMap(), TileSize: benchTile}
+		level.Camera.X, level.Camera.Y = firstX*benchTile, firstY*benchTile
+		renderer := &copyCounter{SoftwareRenderer: NewSoftwareRenderer(1, 1)}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			level.Draw(renderer)
+		}
+	})
+}
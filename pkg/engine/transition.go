@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Transition animates the change between two states of the SceneStack.
+//   Draw is given how far through the transition we are (0 to 1) and functions
+//   that draw the scenes before and after the change.
+type Transition interface {
+	Duration() time.Duration
+	Draw(renderer Renderer, progress float64, from, to func())
+}
+
+// FadeTransition fades the outgoing scenes out to Color, then fades the incoming scenes in from it
+type FadeTransition struct {
+	Color sdl.Color
+	Time  time.Duration
+}
+
+// NewFade creates a fade through black that lasts d
+func NewFade(d time.Duration) *FadeTransition {
+	return &FadeTransition{
+		Color: sdl.Color{R: 0, G: 0, B: 0, A: 255},
+		Time:  d,
+	}
+}
+
+// Duration returns how long the fade lasts
+func (f *FadeTransition) Duration() time.Duration {
+	return f.Time
+}
+
+// Draw renders the outgoing scenes for the first half of the fade, and the incoming for the second,
+//   with a box of Color over them that is opaque at the midpoint
+func (f *FadeTransition) Draw(renderer Renderer, progress float64, from, to func()) {
+	var opacity float64
+	if progress < 0.5 {
+		from()
+		opacity = progress * 2
+	} else {
+		to()
+		opacity = (1 - progress) * 2
+	}
+
+	w, h, err := renderer.GetOutputSize()
+	if err != nil {
+		return
+	}
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	renderer.SetDrawColor(f.Color.R, f.Color.G, f.Color.B, uint8(float64(f.Color.A)*opacity))
+	renderer.FillRect(&sdl.Rect{X: 0, Y: 0, W: w, H: h})
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	renderer.SetDrawColor(0, 0, 0, 255)
+}
+
+// SlideDirection is the direction scenes move across the screen during a slide
+type SlideDirection int
+
+// Directions a SlideTransition can push scenes in
+const (
+	SlideLeft SlideDirection = iota
+	SlideRight
+	SlideUp
+	SlideDown
+)
+
+// SlideTransition pushes the outgoing scenes off screen as the incoming scenes slide on
+type SlideTransition struct {
+	Direction SlideDirection
+	Time      time.Duration
+}
+
+// NewSlide creates a slide in direction that lasts d
+func NewSlide(direction SlideDirection, d time.Duration) *SlideTransition {
+	return &SlideTransition{
+		Direction: direction,
+		Time:      d,
+	}
+}
+
+// Duration returns how long the slide lasts
+func (s *SlideTransition) Duration() time.Duration {
+	return s.Time
+}
+
+// Draw offsets the viewport for each set of scenes so they appear side by side, moving across the screen
+func (s *SlideTransition) Draw(renderer Renderer, progress float64, from, to func()) {
+	w, h, err := renderer.GetOutputSize()
+	if err != nil {
+		to()
+		return
+	}
+
+	var dx, dy int32
+	switch s.Direction {
+	case SlideLeft:
+		dx = -w
+	case SlideRight:
+		dx = w
+	case SlideUp:
+		dy = -h
+	case SlideDown:
+		dy = h
+	}
+	offsetX := int32(float64(dx) * progress)
+	offsetY := int32(float64(dy) * progress)
+
+	renderer.SetViewport(&sdl.Rect{X: offsetX, Y: offsetY, W: w, H: h})
+	from()
+	renderer.SetViewport(&sdl.Rect{X: offsetX - dx, Y: offsetY - dy, W: w, H: h})
+	to()
+	renderer.SetViewport(nil)
+}
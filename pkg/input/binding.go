@@ -0,0 +1,134 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Kind is the type of device a Binding reads from
+type Kind int
+
+// Kinds of device an action can be bound to
+const (
+	Key Kind = iota
+	MouseButton
+	GamepadButton
+	GamepadAxis
+)
+
+// Binding ties an action to a single key, mouse button, gamepad button or half of a gamepad axis
+type Binding struct {
+	Kind Kind
+	// Code is the sdl scancode, mouse button, controller button or controller axis being bound
+	Code int
+	// Direction is which half of an axis triggers the action, 1 for positive, -1 for negative
+	Direction int
+}
+
+// KeyBinding binds a keyboard key by its scancode, eg: sdl.SCANCODE_W
+func KeyBinding(code sdl.Scancode) Binding {
+	return Binding{Kind: Key, Code: int(code)}
+}
+
+// MouseBinding binds a mouse button, eg: sdl.BUTTON_LEFT
+func MouseBinding(button int) Binding {
+	return Binding{Kind: MouseButton, Code: button}
+}
+
+// ButtonBinding binds a game controller button, eg: sdl.CONTROLLER_BUTTON_A
+func ButtonBinding(button int) Binding {
+	return Binding{Kind: GamepadButton, Code: button}
+}
+
+// AxisBinding binds one half of a game controller axis, eg: sdl.CONTROLLER_AXIS_LEFTX and -1 for left
+func AxisBinding(axis int, direction int) Binding {
+	if direction < 0 {
+		direction = -1
+	} else {
+		direction = 1
+	}
+	return Binding{Kind: GamepadAxis, Code: axis, Direction: direction}
+}
+
+var mouseButtonNames = map[int]string{
+	sdl.BUTTON_LEFT:   "left",
+	sdl.BUTTON_MIDDLE: "middle",
+	sdl.BUTTON_RIGHT:  "right",
+	sdl.BUTTON_X1:     "x1",
+	sdl.BUTTON_X2:     "x2",
+}
+
+// String formats a binding the way it's written in a bindings file, eg: "key:W", "mouse:left",
+//   "button:a" or "axis:leftx-"
+func (b Binding) String() string {
+	switch b.Kind {
+	case Key:
+		return "key:" + sdl.GetScancodeName(sdl.Scancode(b.Code))
+	case MouseButton:
+		if name, ok := mouseButtonNames[b.Code]; ok {
+			return "mouse:" + name
+		}
+		return fmt.Sprintf("mouse:%d", b.Code)
+	case GamepadButton:
+		return "button:" + sdl.GameControllerGetStringForButton(sdl.GameControllerButton(b.Code))
+	case GamepadAxis:
+		sign := "+"
+		if b.Direction < 0 {
+			sign = "-"
+		}
+		return "axis:" + sdl.GameControllerGetStringForAxis(sdl.GameControllerAxis(b.Code)) + sign
+	}
+	return fmt.Sprintf("unknown:%d", b.Code)
+}
+
+// ParseBinding reads a binding in the format written by Binding.String
+func ParseBinding(s string) (Binding, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return Binding{}, fmt.Errorf("input: malformed binding %q", s)
+	}
+	kind, name := parts[0], parts[1]
+
+	switch kind {
+	case "key":
+		code := sdl.GetScancodeFromName(name)
+		if code == sdl.SCANCODE_UNKNOWN {
+			return Binding{}, fmt.Errorf("input: unknown key %q", name)
+		}
+		return KeyBinding(code), nil
+	case "mouse":
+		for code, buttonName := range mouseButtonNames {
+			if buttonName == name {
+				return MouseBinding(code), nil
+			}
+		}
+		var code int
+		if _, err := fmt.Sscanf(name, "%d", &code); err != nil {
+			return Binding{}, fmt.Errorf("input: unknown mouse button %q", name)
+		}
+		return MouseBinding(code), nil
+	case "button":
+		button := sdl.GameControllerGetButtonFromString(name)
+		if button == sdl.CONTROLLER_BUTTON_INVALID {
+			return Binding{}, fmt.Errorf("input: unknown controller button %q", name)
+		}
+		return ButtonBinding(int(button)), nil
+	case "axis":
+		direction := 1
+		switch {
+		case strings.HasSuffix(name, "-"):
+			direction = -1
+			name = strings.TrimSuffix(name, "-")
+		case strings.HasSuffix(name, "+"):
+			name = strings.TrimSuffix(name, "+")
+		}
+		axis := sdl.GameControllerGetAxisFromString(name)
+		if axis == sdl.CONTROLLER_AXIS_INVALID {
+			return Binding{}, fmt.Errorf("input: unknown controller axis %q", name)
+		}
+		return AxisBinding(int(axis), direction), nil
+	}
+	return Binding{}, fmt.Errorf("input: unknown binding kind %q", kind)
+}
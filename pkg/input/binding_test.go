@@ -0,0 +1,76 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestBindingString(t *testing.T) {
+	tests := []struct {
+		binding Binding
+		want    string
+	}{
+		{KeyBinding(sdl.SCANCODE_W), "key:W"},
+		{KeyBinding(sdl.SCANCODE_LSHIFT), "key:Left Shift"},
+		{MouseBinding(sdl.BUTTON_LEFT), "mouse:left"},
+		{MouseBinding(sdl.BUTTON_X2), "mouse:x2"},
+		{MouseBinding(9), "mouse:9"},
+		{ButtonBinding(sdl.CONTROLLER_BUTTON_A), "button:a"},
+		{ButtonBinding(sdl.CONTROLLER_BUTTON_START), "button:start"},
+		{AxisBinding(sdl.CONTROLLER_AXIS_LEFTX, -1), "axis:leftx-"},
+		{AxisBinding(sdl.CONTROLLER_AXIS_RIGHTY, 1), "axis:righty+"},
+		{AxisBinding(sdl.CONTROLLER_AXIS_TRIGGERLEFT, 5), "axis:lefttrigger+"},
+	}
+	for _, test := range tests {
+		if got := test.binding.String(); got != test.want {
+			t.Errorf("%+v.String() = %q, want %q", test.binding, got, test.want)
+		}
+		parsed, err := ParseBinding(test.want)
+		if err != nil {
+			t.Errorf("ParseBinding(%q) failed: %v", test.want, err)
+			continue
+		}
+		if parsed != test.binding {
+			t.Errorf("ParseBinding(%q) = %+v, want %+v", test.want, parsed, test.binding)
+		}
+	}
+}
+
+func TestParseBinding(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Binding
+	}{
+		{"key:space", KeyBinding(sdl.SCANCODE_SPACE)},
+		{"mouse:3", MouseBinding(sdl.BUTTON_RIGHT)},
+		{"axis:lefty", AxisBinding(sdl.CONTROLLER_AXIS_LEFTY, 1)},
+	}
+	for _, test := range tests {
+		got, err := ParseBinding(test.in)
+		if err != nil {
+			t.Errorf("ParseBinding(%q) failed: %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseBinding(%q) = %+v, want %+v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseBindingErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"W",
+		"key:",
+		"key:NotAKey",
+		"mouse:thumb",
+		"button:z",
+		"axis:sideways-",
+		"wheel:up",
+	} {
+		if b, err := ParseBinding(in); err == nil {
+			t.Errorf("ParseBinding(%q) = %+v, want an error", in, b)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package input
+
+import (
+	"math"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// DefaultControllers is the set of controllers used by any Map that isn't given its own
+var DefaultControllers = NewControllers()
+
+// Controller is a connected game controller and its state as of the last event we saw from it
+type Controller struct {
+	// DeadZone is how far a stick or trigger must be pushed, out of 1.0, before it counts as input
+	DeadZone float64
+	// ID is the joystick instance id SDL uses in controller events
+	ID   sdl.JoystickID
+	Name string
+	// Slot is the player this controller belongs to. Slots are handed out lowest first as controllers connect
+	Slot int
+
+	axes    [sdl.CONTROLLER_AXIS_MAX]int16
+	buttons [sdl.CONTROLLER_BUTTON_MAX]bool
+	pad     *sdl.GameController
+}
+
+// Controllers tracks game controllers as they're plugged in and out.
+//   Feed it events with HandleEvent, the engine does this for you in Run.
+type Controllers struct {
+	// OnConnect and OnDisconnect are optional hooks, eg: to pause the game when a controller is unplugged
+	OnConnect    func(*Controller)
+	OnDisconnect func(*Controller)
+
+	byID  map[sdl.JoystickID]*Controller
+	slots []*Controller
+}
+
+// NewControllers creates an empty set of controllers
+func NewControllers() *Controllers {
+	return &Controllers{
+		byID: make(map[sdl.JoystickID]*Controller),
+	}
+}
+
+// HandleEvent opens and closes controllers as they're connected and records their axes and buttons.
+//   SDL sends a device added event for every controller already plugged in when it starts up,
+//   so there's no need to scan for them separately.
+func (c *Controllers) HandleEvent(event sdl.Event) {
+	switch t := event.(type) {
+	case *sdl.ControllerDeviceEvent:
+		switch t.Type {
+		case sdl.CONTROLLERDEVICEADDED:
+			// For added events Which is the device index rather than the instance id
+			c.open(int(t.Which))
+		case sdl.CONTROLLERDEVICEREMOVED:
+			c.close(t.Which)
+		}
+	case *sdl.ControllerAxisEvent:
+		if ctrl, ok := c.byID[t.Which]; ok && int(t.Axis) < len(ctrl.axes) {
+			ctrl.axes[t.Axis] = t.Value
+		}
+	case *sdl.ControllerButtonEvent:
+		if ctrl, ok := c.byID[t.Which]; ok && int(t.Button) < len(ctrl.buttons) {
+			ctrl.buttons[t.Button] = t.State == sdl.PRESSED
+		}
+	}
+}
+
+// open opens the controller at a device index and gives it the first free slot
+func (c *Controllers) open(index int) {
+	if !sdl.IsGameController(index) {
+		return
+	}
+	pad := sdl.GameControllerOpen(index)
+	if pad == nil {
+		return
+	}
+	id := pad.Joystick().InstanceID()
+	if _, ok := c.byID[id]; ok {
+		return
+	}
+
+	ctrl := &Controller{
+		DeadZone: DefaultDeadZone,
+		ID:       id,
+		Name:     pad.Name(),
+		pad:      pad,
+	}
+	ctrl.Slot = len(c.slots)
+	for i, taken := range c.slots {
+		if taken == nil {
+			ctrl.Slot = i
+			break
+		}
+	}
+	if ctrl.Slot == len(c.slots) {
+		c.slots = append(c.slots, ctrl)
+	} else {
+		c.slots[ctrl.Slot] = ctrl
+	}
+	c.byID[id] = ctrl
+
+	if c.OnConnect != nil {
+		c.OnConnect(ctrl)
+	}
+}
+
+// close closes a controller that has been unplugged and frees up its slot
+func (c *Controllers) close(id sdl.JoystickID) {
+	ctrl, ok := c.byID[id]
+	if !ok {
+		return
+	}
+	delete(c.byID, id)
+	c.slots[ctrl.Slot] = nil
+	ctrl.pad.Close()
+
+	if c.OnDisconnect != nil {
+		c.OnDisconnect(ctrl)
+	}
+}
+
+// Slot returns the controller for a player, or nil if they don't have one plugged in
+func (c *Controllers) Slot(slot int) *Controller {
+	if slot < 0 || slot >= len(c.slots) {
+		return nil
+	}
+	return c.slots[slot]
+}
+
+// ByID returns the controller with a joystick instance id, or nil if it isn't connected
+func (c *Controllers) ByID(id sdl.JoystickID) *Controller {
+	return c.byID[id]
+}
+
+// Connected returns every connected controller in slot order
+func (c *Controllers) Connected() []*Controller {
+	var connected []*Controller
+	for _, ctrl := range c.slots {
+		if ctrl != nil {
+			connected = append(connected, ctrl)
+		}
+	}
+	return connected
+}
+
+// Close closes every connected controller
+func (c *Controllers) Close() {
+	for _, ctrl := range c.Connected() {
+		c.close(ctrl.ID)
+	}
+}
+
+// Button reports whether a button, eg: sdl.CONTROLLER_BUTTON_A, is held down
+func (ctrl *Controller) Button(button int) bool {
+	if button < 0 || button >= len(ctrl.buttons) {
+		return false
+	}
+	return ctrl.buttons[button]
+}
+
+// Axis returns an axis, eg: sdl.CONTROLLER_AXIS_LEFTX, from -1 to 1 with the dead zone removed.
+//   The sticks use a radial dead zone so diagonals aren't clipped, triggers run from 0 to 1.
+func (ctrl *Controller) Axis(axis int) float64 {
+	switch axis {
+	case sdl.CONTROLLER_AXIS_LEFTX:
+		x, _ := ctrl.Stick(sdl.CONTROLLER_AXIS_LEFTX, sdl.CONTROLLER_AXIS_LEFTY)
+		return x
+	case sdl.CONTROLLER_AXIS_LEFTY:
+		_, y := ctrl.Stick(sdl.CONTROLLER_AXIS_LEFTX, sdl.CONTROLLER_AXIS_LEFTY)
+		return y
+	case sdl.CONTROLLER_AXIS_RIGHTX:
+		x, _ := ctrl.Stick(sdl.CONTROLLER_AXIS_RIGHTX, sdl.CONTROLLER_AXIS_RIGHTY)
+		return x
+	case sdl.CONTROLLER_AXIS_RIGHTY:
+		_, y := ctrl.Stick(sdl.CONTROLLER_AXIS_RIGHTX, sdl.CONTROLLER_AXIS_RIGHTY)
+		return y
+	}
+	if axis < 0 || axis >= len(ctrl.axes) {
+		return 0
+	}
+	return deadZone(normalizeAxis(ctrl.axes[axis]), ctrl.DeadZone)
+}
+
+// Stick returns the x and y of a stick from -1 to 1. Anything inside the dead zone is 0,
+//   and the rest of the stick's travel is rescaled so its magnitude ramps smoothly up to 1.
+func (ctrl *Controller) Stick(xAxis, yAxis int) (x, y float64) {
+	return radialDeadZone(normalizeAxis(ctrl.axes[xAxis]), normalizeAxis(ctrl.axes[yAxis]), ctrl.DeadZone)
+}
+
+// Rumble shakes the controller for d. low and high are the strength, from 0 to 1,
+//   of the low and high frequency motors
+func (ctrl *Controller) Rumble(low, high float64, d time.Duration) error {
+	return ctrl.pad.Rumble(motor(low), motor(high), uint32(d/time.Millisecond))
+}
+
+// normalizeAxis turns a raw axis value into -1 to 1
+func normalizeAxis(raw int16) float64 {
+	if raw < 0 {
+		return float64(raw) / 32768
+	}
+	return float64(raw) / 32767
+}
+
+// deadZone zeroes a normalized value inside the dead zone and rescales what's left back to the full range
+func deadZone(v float64, zone float64) float64 {
+	sign := 1.0
+	if v < 0 {
+		sign = -1
+		v = -v
+	}
+	if v <= zone {
+		return 0
+	}
+	return sign * math.Min((v-zone)/(1-zone), 1)
+}
+
+// radialDeadZone applies a dead zone to a stick's distance from the centre rather than to each axis,
+//   keeping its direction and rescaling its magnitude so it ramps smoothly from the edge of the zone up to 1
+func radialDeadZone(x, y float64, zone float64) (float64, float64) {
+	magnitude := math.Hypot(x, y)
+	if magnitude <= zone {
+		return 0, 0
+	}
+	scaled := deadZone(math.Min(magnitude, 1), zone)
+	return x / magnitude * scaled, y / magnitude * scaled
+}
+
+// motor turns a strength from 0 to 1 into the range SDL expects for a rumble motor
+func motor(strength float64) uint16 {
+	return uint16(math.Max(0, math.Min(strength, 1)) * 0xFFFF)
+}
@@ -0,0 +1,138 @@
+package input
+
+import (
+	"math"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const epsilon = 1e-9
+
+func TestDeadZone(t *testing.T) {
+	tests := []struct {
+		name string
+		v    float64
+		want float64
+	}{
+		{"centre", 0, 0},
+		{"inside", 0.1, 0},
+		{"inside negative", -0.2, 0},
+		{"edge", 0.25, 0},
+		{"edge negative", -0.25, 0},
+		{"half way out", 0.625, 0.5},
+		{"half way out negative", -0.625, -0.5},
+		{"full tilt", 1, 1},
+		{"full tilt negative", -1, -1},
+		{"past full tilt", 1.5, 1},
+	}
+	for _, test := range tests {
+		if got := deadZone(test.v, 0.25); math.Abs(got-test.want) > epsilon {
+			t.Errorf("%s: deadZone(%v, 0.25) = %v, want %v", test.name, test.v, got, test.want)
+		}
+	}
+}
+
+func TestRadialDeadZone(t *testing.T) {
+	diagonal := math.Sqrt(0.5)
+	// 0.2, 0.2 is about 0.283 from the centre, 0.044 of the way from the zone's edge to full tilt
+	small := diagonal * (math.Hypot(0.2, 0.2) - 0.25) / 0.75
+	tests := []struct {
+		name         string
+		x, y         float64
+		wantX, wantY float64
+	}{
+		{"centre", 0, 0, 0, 0},
+		{"inside", 0.1, -0.1, 0, 0},
+		{"edge", 0, 0.25, 0, 0},
+		{"half way out", -0.625, 0, -0.5, 0},
+		{"full tilt", 0, -1, 0, -1},
+		// Each axis of a diagonal is inside the zone on its own but the stick isn't
+		{"small diagonal", 0.2, 0.2, small, small},
+		{"full diagonal", diagonal, diagonal, diagonal, diagonal},
+		// Raw corners go past 1 and are clamped back onto the circle
+		{"corner", 1, -1, diagonal, -diagonal},
+	}
+	for _, test := range tests {
+		x, y := radialDeadZone(test.x, test.y, 0.25)
+		if math.Abs(x-test.wantX) > epsilon || math.Abs(y-test.wantY) > epsilon {
+			t.Errorf("%s: radialDeadZone(%v, %v, 0.25) = %v, %v, want %v, %v", test.name, test.x, test.y, x, y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestNormalizeAxis(t *testing.T) {
+	tests := []struct {
+		raw  int16
+		want float64
+	}{
+		{0, 0},
+		{32767, 1},
+		{-32768, -1},
+		{16384, 16384.0 / 32767},
+	}
+	for _, test := range tests {
+		if got := normalizeAxis(test.raw); got != test.want {
+			t.Errorf("normalizeAxis(%d) = %v, want %v", test.raw, got, test.want)
+		}
+	}
+}
+
+func TestAxisActions(t *testing.T) {
+	ctrl := &Controller{DeadZone: DefaultDeadZone}
+	controllers := NewControllers()
+	controllers.slots = []*Controller{ctrl}
+
+	m := NewDefaultMap()
+	m.Controllers = controllers
+	m.Bind("brake", AxisBinding(sdl.CONTROLLER_AXIS_TRIGGERLEFT, 1))
+
+	tests := []struct {
+		name         string
+		leftX, leftY int16
+		trigger      int16
+		left, right  float64
+		up, down     float64
+		brake        float64
+	}{
+		{"centred", 0, 0, 0, 0, 0, 0, 0, 0},
+		{"inside the dead zone", -4000, 4000, 4000, 0, 0, 0, 0, 0},
+		{"full left", -32768, 0, 0, 1, 0, 0, 0, 0},
+		{"full right", 32767, 0, 0, 0, 1, 0, 0, 0},
+		{"full up", 0, -32768, 0, 0, 0, 1, 0, 0},
+		{"full down and braking", 0, 32767, 32767, 0, 0, 0, 1, 1},
+	}
+	for _, test := range tests {
+		ctrl.axes[sdl.CONTROLLER_AXIS_LEFTX] = test.leftX
+		ctrl.axes[sdl.CONTROLLER_AXIS_LEFTY] = test.leftY
+		ctrl.axes[sdl.CONTROLLER_AXIS_TRIGGERLEFT] = test.trigger
+		m.Update()
+
+		for action, want := range map[string]float64{
+			MoveLeft:  test.left,
+			MoveRight: test.right,
+			MoveUp:    test.up,
+			MoveDown:  test.down,
+			"brake":   test.brake,
+		} {
+			if got := m.Value(action); math.Abs(got-want) > epsilon {
+				t.Errorf("%s: %s = %v, want %v", test.name, action, got, want)
+			}
+		}
+	}
+
+	// Buttons on the map's controller count too
+	ctrl.axes = [sdl.CONTROLLER_AXIS_MAX]int16{}
+	ctrl.buttons[sdl.CONTROLLER_BUTTON_DPAD_UP] = true
+	m.Update()
+	if m.Value(MoveUp) != 1 {
+		t.Errorf("move_up = %v with the d-pad held, want 1", m.Value(MoveUp))
+	}
+
+	// A map reading another slot ignores this controller
+	m.Gamepad = 1
+	m.Update()
+	if m.Held(MoveUp) {
+		t.Error("a map read a controller from the wrong slot")
+	}
+}
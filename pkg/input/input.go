@@ -0,0 +1,343 @@
+// Package input maps named actions like "move_up" or "confirm" to keys, mouse buttons
+// and game controller buttons and axes, so games don't need to care what device was used.
+package input
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Actions the engine and the default bindings know about
+const (
+	MoveUp    = "move_up"
+	MoveDown  = "move_down"
+	MoveLeft  = "move_left"
+	MoveRight = "move_right"
+	Sprint    = "sprint"
+	Confirm   = "confirm"
+	Cancel    = "cancel"
+	Pause     = "pause"
+)
+
+// DefaultDeadZone is how far a stick must be pushed, out of 1.0, before it counts as input
+const DefaultDeadZone = 0.25
+
+// Default is the map used by anything that isn't given one of its own
+var Default = NewDefaultMap()
+
+// Map tracks the bindings and per tick state of a set of actions.
+//   Call Update once per tick to sample the bound devices, then query actions with
+//   Held, Pressed, Released and Value.
+type Map struct {
+	// Controllers is where the map finds its gamepad, DefaultControllers is used when it's nil
+	Controllers *Controllers
+	// Gamepad is the controller slot this map reads from, so two players can each have a map
+	Gamepad int
+
+	bindings map[string][]Binding
+	states   map[string]*state
+	// latched holds anything pressed since the last Update, so taps shorter than a tick aren't missed
+	latched map[Binding]bool
+}
+
+// state is the value of an action this tick and last tick
+type state struct {
+	previous float64
+	value    float64
+}
+
+// NewMap creates a map with no bindings
+func NewMap() *Map {
+	return &Map{
+		bindings: make(map[string][]Binding),
+		states:   make(map[string]*state),
+		latched:  make(map[Binding]bool),
+	}
+}
+
+// NewDefaultMap creates a map with WASD, arrow key and controller bindings for the engine's actions
+func NewDefaultMap() *Map {
+	m := NewMap()
+	m.Bind(MoveUp,
+		KeyBinding(sdl.SCANCODE_W),
+		KeyBinding(sdl.SCANCODE_UP),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_DPAD_UP),
+		AxisBinding(sdl.CONTROLLER_AXIS_LEFTY, -1),
+	)
+	m.Bind(MoveDown,
+		KeyBinding(sdl.SCANCODE_S),
+		KeyBinding(sdl.SCANCODE_DOWN),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_DPAD_DOWN),
+		AxisBinding(sdl.CONTROLLER_AXIS_LEFTY, 1),
+	)
+	m.Bind(MoveLeft,
+		KeyBinding(sdl.SCANCODE_A),
+		KeyBinding(sdl.SCANCODE_LEFT),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_DPAD_LEFT),
+		AxisBinding(sdl.CONTROLLER_AXIS_LEFTX, -1),
+	)
+	m.Bind(MoveRight,
+		KeyBinding(sdl.SCANCODE_D),
+		KeyBinding(sdl.SCANCODE_RIGHT),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_DPAD_RIGHT),
+		AxisBinding(sdl.CONTROLLER_AXIS_LEFTX, 1),
+	)
+	m.Bind(Sprint,
+		KeyBinding(sdl.SCANCODE_LSHIFT),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_X),
+	)
+	m.Bind(Confirm,
+		KeyBinding(sdl.SCANCODE_SPACE),
+		KeyBinding(sdl.SCANCODE_RETURN),
+		MouseBinding(sdl.BUTTON_LEFT),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_A),
+	)
+	m.Bind(Cancel,
+		KeyBinding(sdl.SCANCODE_ESCAPE),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_B),
+	)
+	m.Bind(Pause,
+		KeyBinding(sdl.SCANCODE_ESCAPE),
+		ButtonBinding(sdl.CONTROLLER_BUTTON_START),
+	)
+	return m
+}
+
+// Bind adds bindings to an action, creating the action if it doesn't exist yet
+func (m *Map) Bind(action string, bindings ...Binding) {
+	m.bindings[action] = append(m.bindings[action], bindings...)
+	if m.states[action] == nil {
+		m.states[action] = &state{}
+	}
+}
+
+// Rebind replaces all of an action's bindings, eg: from a controls menu
+func (m *Map) Rebind(action string, bindings ...Binding) {
+	m.bindings[action] = nil
+	m.Bind(action, bindings...)
+}
+
+// Unbind removes a single binding from an action
+func (m *Map) Unbind(action string, binding Binding) {
+	bindings := m.bindings[action][:0]
+	for _, b := range m.bindings[action] {
+		if b != binding {
+			bindings = append(bindings, b)
+		}
+	}
+	m.bindings[action] = bindings
+}
+
+// Bindings returns a copy of the bindings for an action
+func (m *Map) Bindings(action string) []Binding {
+	return append([]Binding{}, m.bindings[action]...)
+}
+
+// Actions returns the names of every action in the map, sorted
+func (m *Map) Actions() []string {
+	var actions []string
+	for action := range m.bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// HandleEvent latches presses that arrive between ticks so a quick tap still registers on the next Update
+func (m *Map) HandleEvent(event sdl.Event) {
+	switch t := event.(type) {
+	case *sdl.KeyboardEvent:
+		if t.State == sdl.PRESSED && t.Repeat == 0 {
+			m.latched[KeyBinding(t.Keysym.Scancode)] = true
+		}
+	case *sdl.MouseButtonEvent:
+		if t.State == sdl.PRESSED {
+			m.latched[MouseBinding(int(t.Button))] = true
+		}
+	case *sdl.ControllerButtonEvent:
+		if pad := m.gamepad(); t.State == sdl.PRESSED && pad != nil && pad.ID == t.Which {
+			m.latched[ButtonBinding(int(t.Button))] = true
+		}
+	}
+}
+
+// Update samples every bound device and advances each action a tick, call it once per tick
+func (m *Map) Update() {
+	keys := sdl.GetKeyboardState()
+	_, _, mouse := sdl.GetMouseState()
+	pad := m.gamepad()
+
+	for action, s := range m.states {
+		s.previous = s.value
+		s.value = 0
+		for _, b := range m.bindings[action] {
+			if v := m.sample(b, keys, mouse, pad); v > s.value {
+				s.value = v
+			}
+		}
+	}
+	m.latched = make(map[Binding]bool)
+}
+
+// sample returns how far, from 0 to 1, a single binding is pushed
+func (m *Map) sample(b Binding, keys []uint8, mouse uint32, pad *Controller) float64 {
+	if m.latched[b] {
+		return 1
+	}
+	switch b.Kind {
+	case Key:
+		if b.Code < len(keys) && keys[b.Code] == 1 {
+			return 1
+		}
+	case MouseButton:
+		if mouse&sdl.Button(uint32(b.Code)) != 0 {
+			return 1
+		}
+	case GamepadButton:
+		if pad != nil && pad.Button(b.Code) {
+			return 1
+		}
+	case GamepadAxis:
+		if pad != nil {
+			if v := pad.Axis(b.Code) * float64(b.Direction); v > 0 {
+				return v
+			}
+		}
+	}
+	return 0
+}
+
+// gamepad returns the controller plugged into the map's slot, if there is one
+func (m *Map) gamepad() *Controller {
+	controllers := m.Controllers
+	if controllers == nil {
+		controllers = DefaultControllers
+	}
+	return controllers.Slot(m.Gamepad)
+}
+
+// Snapshot is the value of every action that was pushed during a tick, keyed by action name.
+//   Actions that weren't pushed are left out.
+type Snapshot map[string]float64
+
+// Snapshot captures the value of the map's actions this tick, eg: to record a replay
+func (m *Map) Snapshot() Snapshot {
+	snapshot := Snapshot{}
+	for action, s := range m.states {
+		if s.value != 0 {
+			snapshot[action] = s.value
+		}
+	}
+	return snapshot
+}
+
+// Apply advances the map a tick using a recorded snapshot instead of sampling devices,
+//   so a replay drives the game exactly like the input it was recorded from
+func (m *Map) Apply(snapshot Snapshot) {
+	for action, s := range m.states {
+		s.previous = s.value
+		s.value = snapshot[action]
+	}
+	for action, value := range snapshot {
+		if _, ok := m.states[action]; !ok {
+			m.states[action] = &state{value: value}
+		}
+	}
+	m.latched = make(map[Binding]bool)
+}
+
+// Value returns how far an action is pushed this tick, from 0 to 1.
+//   Keys and buttons are always 0 or 1, axes can be anywhere in between.
+func (m *Map) Value(action string) float64 {
+	if s, ok := m.states[action]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// Held reports whether an action is down this tick
+func (m *Map) Held(action string) bool {
+	return m.Value(action) > 0
+}
+
+// Pressed reports whether an action went down this tick
+func (m *Map) Pressed(action string) bool {
+	s, ok := m.states[action]
+	return ok && s.value > 0 && s.previous == 0
+}
+
+// Released reports whether an action came up this tick
+func (m *Map) Released(action string) bool {
+	s, ok := m.states[action]
+	return ok && s.value == 0 && s.previous > 0
+}
+
+// bindingsFile is the on disk format for a map's bindings
+type bindingsFile struct {
+	Bindings map[string][]string `json:"bindings"`
+}
+
+// Save writes the map's bindings as JSON
+func (m *Map) Save(w io.Writer) error {
+	file := bindingsFile{
+		Bindings: make(map[string][]string),
+	}
+	for action, bindings := range m.bindings {
+		names := []string{}
+		for _, b := range bindings {
+			names = append(names, b.String())
+		}
+		file.Bindings[action] = names
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// Load replaces the map's bindings with those read from JSON written by Save.
+//   Actions not in the file keep their current bindings.
+func (m *Map) Load(r io.Reader) error {
+	var file bindingsFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return err
+	}
+
+	loaded := make(map[string][]Binding)
+	for action, names := range file.Bindings {
+		for _, name := range names {
+			b, err := ParseBinding(name)
+			if err != nil {
+				return err
+			}
+			loaded[action] = append(loaded[action], b)
+		}
+	}
+	for action, bindings := range loaded {
+		m.Rebind(action, bindings...)
+	}
+	return nil
+}
+
+// SaveFile writes the map's bindings to a file at path
+func (m *Map) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.Save(f)
+}
+
+// LoadFile reads the map's bindings from a file at path
+func (m *Map) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.Load(f)
+}
@@ -0,0 +1,220 @@
+package input
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func press(m *Map, code sdl.Scancode) {
+	m.HandleEvent(&sdl.KeyboardEvent{
+		Type:   sdl.KEYDOWN,
+		State:  sdl.PRESSED,
+		Keysym: sdl.Keysym{Scancode: code},
+	})
+}
+
+func TestMapBindings(t *testing.T) {
+	m := NewMap()
+	m.Bind("jump", KeyBinding(sdl.SCANCODE_SPACE))
+	m.Bind("jump", ButtonBinding(sdl.CONTROLLER_BUTTON_A), KeyBinding(sdl.SCANCODE_W))
+	m.Bind("fire", MouseBinding(sdl.BUTTON_LEFT))
+
+	if got, want := m.Actions(), []string{"fire", "jump"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions() = %v, want %v", got, want)
+	}
+	want := []Binding{KeyBinding(sdl.SCANCODE_SPACE), ButtonBinding(sdl.CONTROLLER_BUTTON_A), KeyBinding(sdl.SCANCODE_W)}
+	if got := m.Bindings("jump"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings(jump) = %v, want %v", got, want)
+	}
+
+	// Bindings is a copy, changing it mustn't change the map
+	m.Bindings("jump")[0] = KeyBinding(sdl.SCANCODE_Q)
+	if got := m.Bindings("jump")[0]; got != KeyBinding(sdl.SCANCODE_SPACE) {
+		t.Errorf("Bindings(jump)[0] = %v after editing a copy, want key:Space", got)
+	}
+
+	m.Unbind("jump", ButtonBinding(sdl.CONTROLLER_BUTTON_A))
+	want = []Binding{KeyBinding(sdl.SCANCODE_SPACE), KeyBinding(sdl.SCANCODE_W)}
+	if got := m.Bindings("jump"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings(jump) after Unbind = %v, want %v", got, want)
+	}
+
+	m.Rebind("jump", KeyBinding(sdl.SCANCODE_UP))
+	want = []Binding{KeyBinding(sdl.SCANCODE_UP)}
+	if got := m.Bindings("jump"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings(jump) after Rebind = %v, want %v", got, want)
+	}
+
+	if got := m.Bindings("missing"); len(got) != 0 {
+		t.Errorf("Bindings(missing) = %v, want none", got)
+	}
+	if m.Value("missing") != 0 || m.Held("missing") || m.Pressed("missing") || m.Released("missing") {
+		t.Error("an unknown action reports input")
+	}
+}
+
+func TestMapEdges(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot Snapshot
+		held     bool
+		pressed  bool
+		released bool
+	}{
+		{"idle", Snapshot{}, false, false, false},
+		{"down", Snapshot{"jump": 1}, true, true, false},
+		{"still down", Snapshot{"jump": 1}, true, false, false},
+		{"half way", Snapshot{"jump": 0.5}, true, false, false},
+		{"up", Snapshot{}, false, false, true},
+		{"still up", Snapshot{}, false, false, false},
+		{"down again", Snapshot{"jump": 0.25}, true, true, false},
+	}
+
+	m := NewMap()
+	m.Bind("jump", KeyBinding(sdl.SCANCODE_SPACE))
+	for _, test := range tests {
+		m.Apply(test.snapshot)
+		if got := m.Held("jump"); got != test.held {
+			t.Errorf("%s: Held = %v, want %v", test.name, got, test.held)
+		}
+		if got := m.Pressed("jump"); got != test.pressed {
+			t.Errorf("%s: Pressed = %v, want %v", test.name, got, test.pressed)
+		}
+		if got := m.Released("jump"); got != test.released {
+			t.Errorf("%s: Released = %v, want %v", test.name, got, test.released)
+		}
+		if got, want := m.Value("jump"), test.snapshot["jump"]; got != want {
+			t.Errorf("%s: Value = %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestMapLatch(t *testing.T) {
+	m := NewMap()
+	m.Bind("jump", KeyBinding(sdl.SCANCODE_SPACE))
+	m.Bind("fire", MouseBinding(sdl.BUTTON_LEFT))
+
+	// A tap that comes and goes between ticks still counts for one tick
+	press(m, sdl.SCANCODE_SPACE)
+	m.HandleEvent(&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, State: sdl.PRESSED, Button: sdl.BUTTON_LEFT})
+	m.Update()
+	for _, action := range []string{"jump", "fire"} {
+		if !m.Pressed(action) || !m.Held(action) {
+			t.Errorf("%s isn't pressed the tick after it was tapped", action)
+		}
+	}
+
+	m.Update()
+	for _, action := range []string{"jump", "fire"} {
+		if m.Held(action) || !m.Released(action) {
+			t.Errorf("%s isn't released the tick after the tap", action)
+		}
+	}
+
+	// Key repeats and releases aren't presses
+	m.HandleEvent(&sdl.KeyboardEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Repeat: 1, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_SPACE}})
+	m.HandleEvent(&sdl.KeyboardEvent{Type: sdl.KEYUP, State: sdl.RELEASED, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_SPACE}})
+	m.Update()
+	if m.Held("jump") {
+		t.Error("a key repeat or release pressed jump")
+	}
+
+	// Apply replaces sampling, so anything latched before it is dropped
+	press(m, sdl.SCANCODE_SPACE)
+	m.Apply(Snapshot{})
+	m.Update()
+	if m.Held("jump") {
+		t.Error("a press latched before Apply carried over to the next Update")
+	}
+}
+
+func TestMapSnapshot(t *testing.T) {
+	m := NewMap()
+	m.Bind("jump", KeyBinding(sdl.SCANCODE_SPACE))
+	m.Bind("fire", KeyBinding(sdl.SCANCODE_F))
+
+	press(m, sdl.SCANCODE_SPACE)
+	m.Update()
+	if got, want := m.Snapshot(), (Snapshot{"jump": 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() = %v, want %v", got, want)
+	}
+
+	// Applying a snapshot to another map reproduces the same state, including actions it hasn't bound
+	replay := NewMap()
+	replay.Bind("jump")
+	replay.Apply(Snapshot{"jump": 1, "extra": 0.5})
+	if got, want := replay.Snapshot(), (Snapshot{"jump": 1, "extra": 0.5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() after Apply = %v, want %v", got, want)
+	}
+	if !replay.Pressed("extra") || replay.Value("extra") != 0.5 {
+		t.Error("an action only in the snapshot wasn't pressed")
+	}
+
+	replay.Apply(Snapshot{})
+	if len(replay.Snapshot()) != 0 {
+		t.Errorf("Snapshot() after an empty Apply = %v, want empty", replay.Snapshot())
+	}
+	if !replay.Released("jump") || !replay.Released("extra") {
+		t.Error("actions left out of a snapshot weren't released")
+	}
+}
+
+func TestMapSaveLoad(t *testing.T) {
+	m := NewDefaultMap()
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := NewMap()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Actions(), m.Actions()) {
+		t.Errorf("loaded actions %v, want %v", loaded.Actions(), m.Actions())
+	}
+	for _, action := range m.Actions() {
+		if got, want := loaded.Bindings(action), m.Bindings(action); !reflect.DeepEqual(got, want) {
+			t.Errorf("loaded %s bindings %v, want %v", action, got, want)
+		}
+	}
+}
+
+func TestMapLoad(t *testing.T) {
+	m := NewDefaultMap()
+	err := m.Load(strings.NewReader(`{"bindings": {"move_up": ["key:I", "axis:righty-"], "dash": ["mouse:right"]}}`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		action string
+		want   []Binding
+	}{
+		{MoveUp, []Binding{KeyBinding(sdl.SCANCODE_I), AxisBinding(sdl.CONTROLLER_AXIS_RIGHTY, -1)}},
+		{"dash", []Binding{MouseBinding(sdl.BUTTON_RIGHT)}},
+		// Actions left out of the file keep their bindings
+		{Cancel, NewDefaultMap().Bindings(Cancel)},
+	}
+	for _, test := range tests {
+		if got := m.Bindings(test.action); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s bindings = %v, want %v", test.action, got, test.want)
+		}
+	}
+
+	// A bad binding fails the whole load without touching the map
+	before := m.Bindings(MoveDown)
+	if err := m.Load(strings.NewReader(`{"bindings": {"move_down": ["key:K", "key:Nope"]}}`)); err == nil {
+		t.Error("Load accepted an unknown key")
+	}
+	if got := m.Bindings(MoveDown); !reflect.DeepEqual(got, before) {
+		t.Errorf("move_down bindings = %v after a failed load, want %v", got, before)
+	}
+	if err := m.Load(strings.NewReader(`{"bindings": `)); err == nil {
+		t.Error("Load accepted truncated JSON")
+	}
+}
@@ -0,0 +1,77 @@
+package physics
+
+// Body is a rigid body in a World
+type Body struct {
+	// Data is for the owner of the body, eg: the entity it moves, so it can be found from a collision
+	Data interface{}
+	// Friction slows bodies sliding against each other, from 0 (ice) to 1 (rubber)
+	Friction float64
+	// GravityScale multiplies the world's gravity for this body, 0 makes it float
+	GravityScale float64
+	// Mass is how heavy the body is. A mass of 0 makes the body static, it's never moved by the world
+	Mass float64
+	// OnCollide is called with each body this body touches during a step
+	OnCollide func(Collision)
+	// OnGround is set when the body is resting on something below it after the last step
+	OnGround bool
+	// Position is the top left of the body's shape, in pixels
+	Position Vec
+	// Restitution is how bouncy the body is, from 0 (a sack of sand) to 1 (a rubber ball)
+	Restitution float64
+	// Sensor bodies report collisions but don't push or get pushed
+	Sensor bool
+	Shape  Shape
+	// Velocity is in pixels per second
+	Velocity Vec
+
+	force Vec
+}
+
+// Collision is a body touching another body, given to OnCollide
+type Collision struct {
+	// Other is the body that was touched
+	Other *Body
+	// Normal points from the body towards Other, eg: 0,1 when Other is below
+	Normal Vec
+	// Depth is how far the bodies overlapped before they were pushed apart
+	Depth float64
+}
+
+// NewBody creates a body with a shape and mass that's affected by gravity
+func NewBody(shape Shape, mass float64) *Body {
+	return &Body{
+		Friction:     0.2,
+		GravityScale: 1,
+		Mass:         mass,
+		Shape:        shape,
+	}
+}
+
+// ApplyForce pushes the body during the next step, eg: a jet pack
+func (b *Body) ApplyForce(force Vec) {
+	b.force = b.force.Add(force)
+}
+
+// ApplyImpulse changes the body's velocity immediately, eg: a jump or knockback
+func (b *Body) ApplyImpulse(impulse Vec) {
+	b.Velocity = b.Velocity.Add(impulse.Scale(b.invMass()))
+}
+
+// Center returns the middle of the body's shape
+func (b *Body) Center() Vec {
+	w, h := b.Shape.Size()
+	return b.Position.Add(Vec{w / 2, h / 2})
+}
+
+// Static reports whether the body is immovable
+func (b *Body) Static() bool {
+	return b.Mass <= 0
+}
+
+// invMass returns 1/mass, which is 0 for static bodies so they soak up any impulse
+func (b *Body) invMass() float64 {
+	if b.Static() {
+		return 0
+	}
+	return 1 / b.Mass
+}
@@ -0,0 +1,126 @@
+package physics
+
+import "math"
+
+// Shape is the outline of a body that collides. A body's Position is the top left of its shape's bounds.
+type Shape interface {
+	// Size returns the width and height of the shape's bounding box
+	Size() (float64, float64)
+}
+
+// AABB is an axis aligned box, W wide and H high
+type AABB struct {
+	W, H float64
+}
+
+// Size satisfies the Shape interface
+func (a AABB) Size() (float64, float64) {
+	return a.W, a.H
+}
+
+// Circle is a circle of the given Radius
+type Circle struct {
+	Radius float64
+}
+
+// Size satisfies the Shape interface
+func (c Circle) Size() (float64, float64) {
+	return c.Radius * 2, c.Radius * 2
+}
+
+// manifold describes how two shapes overlap. Normal points from a to b, and Depth is how far they overlap along it.
+type manifold struct {
+	Normal Vec
+	Depth  float64
+}
+
+// collide tests two bodies' shapes against each other
+func collide(a, b *Body) (manifold, bool) {
+	switch sa := a.Shape.(type) {
+	case AABB:
+		switch sb := b.Shape.(type) {
+		case AABB:
+			return boxBox(a.Position, sa, b.Position, sb)
+		case Circle:
+			return boxCircle(a.Position, sa, b.Position, sb)
+		}
+	case Circle:
+		switch sb := b.Shape.(type) {
+		case AABB:
+			m, ok := boxCircle(b.Position, sb, a.Position, sa)
+			m.Normal = m.Normal.Scale(-1)
+			return m, ok
+		case Circle:
+			return circleCircle(a.Position, sa, b.Position, sb)
+		}
+	}
+	return manifold{}, false
+}
+
+// boxBox separates two boxes along whichever axis they overlap least on
+func boxBox(pa Vec, a AABB, pb Vec, b AABB) (manifold, bool) {
+	dx := (pb.X + b.W/2) - (pa.X + a.W/2)
+	dy := (pb.Y + b.H/2) - (pa.Y + a.H/2)
+	overlapX := (a.W+b.W)/2 - math.Abs(dx)
+	overlapY := (a.H+b.H)/2 - math.Abs(dy)
+	if overlapX <= 0 || overlapY <= 0 {
+		return manifold{}, false
+	}
+	if overlapX < overlapY {
+		return manifold{Normal: Vec{sign(dx), 0}, Depth: overlapX}, true
+	}
+	return manifold{Normal: Vec{0, sign(dy)}, Depth: overlapY}, true
+}
+
+// circleCircle separates two circles along the line between their centers
+func circleCircle(pa Vec, a Circle, pb Vec, b Circle) (manifold, bool) {
+	ca := pa.Add(Vec{a.Radius, a.Radius})
+	cb := pb.Add(Vec{b.Radius, b.Radius})
+	d := cb.Sub(ca)
+	dist := d.Len()
+	if dist >= a.Radius+b.Radius {
+		return manifold{}, false
+	}
+	normal := Vec{0, 1}
+	if dist > 0 {
+		normal = d.Scale(1 / dist)
+	}
+	return manifold{Normal: normal, Depth: a.Radius + b.Radius - dist}, true
+}
+
+// boxCircle separates a circle from a box, using the point of the box closest to the circle's center
+func boxCircle(pa Vec, a AABB, pb Vec, b Circle) (manifold, bool) {
+	center := pb.Add(Vec{b.Radius, b.Radius})
+	closest := Vec{
+		math.Max(pa.X, math.Min(center.X, pa.X+a.W)),
+		math.Max(pa.Y, math.Min(center.Y, pa.Y+a.H)),
+	}
+	d := center.Sub(closest)
+	dist := d.Len()
+	if dist > 0 {
+		if dist >= b.Radius {
+			return manifold{}, false
+		}
+		return manifold{Normal: d.Scale(1 / dist), Depth: b.Radius - dist}, true
+	}
+
+	// The center is inside the box, push the circle out of the nearest side
+	left, right := center.X-pa.X, pa.X+a.W-center.X
+	top, bottom := center.Y-pa.Y, pa.Y+a.H-center.Y
+	m := manifold{Normal: Vec{-1, 0}, Depth: left}
+	for _, side := range []manifold{{Vec{1, 0}, right}, {Vec{0, -1}, top}, {Vec{0, 1}, bottom}} {
+		if side.Depth < m.Depth {
+			m = side
+		}
+	}
+	m.Depth += b.Radius
+	return m, true
+}
+
+// sign returns -1 for negative numbers, otherwise 1
+func sign(f float64) float64 {
+	if f < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,42 @@
+package physics
+
+import "math"
+
+// Vec is a 2D vector, used for positions, velocities and forces
+type Vec struct {
+	X, Y float64
+}
+
+// Add returns v + o
+func (v Vec) Add(o Vec) Vec {
+	return Vec{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v - o
+func (v Vec) Sub(o Vec) Vec {
+	return Vec{v.X - o.X, v.Y - o.Y}
+}
+
+// Scale returns v multiplied by s
+func (v Vec) Scale(s float64) Vec {
+	return Vec{v.X * s, v.Y * s}
+}
+
+// Dot returns the dot product of v and o
+func (v Vec) Dot(o Vec) float64 {
+	return v.X*o.X + v.Y*o.Y
+}
+
+// Len returns the length of v
+func (v Vec) Len() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize returns v scaled to a length of 1, or the zero vector if v has no length
+func (v Vec) Normalize() Vec {
+	l := v.Len()
+	if l == 0 {
+		return Vec{}
+	}
+	return Vec{v.X / l, v.Y / l}
+}
@@ -0,0 +1,253 @@
+// Package physics simulates rigid bodies with box and circle shapes, so entities can fall, jump, bounce and push each other around.
+//   Bodies are integrated on a fixed time step, independent of how often the world is updated, so the simulation is deterministic.
+package physics
+
+import (
+	"math"
+
+	"github.com/ryanhartje/gogome/pkg/spatial"
+)
+
+const (
+	// DefaultCellSize is the size of the cells bodies are sorted into to find which might be touching
+	DefaultCellSize = 64
+	// DefaultTimeStep is how many seconds each step of a world simulates
+	DefaultTimeStep = 1.0 / 60
+	// DefaultMaxSteps caps how many steps Update does at once, so a long frame can't stall the game
+	DefaultMaxSteps = 8
+
+	// correction is how much of the overlap between bodies is pushed apart each step, and slop
+	//   is how much overlap is allowed before they are, which stops resting bodies jittering
+	correction = 0.8
+	slop       = 0.01
+	// restingSpeed is how slowly bodies can collide, in pixels per second, before they stop bouncing off each other
+	restingSpeed = 20
+)
+
+// Terrain is static level geometry bodies move through, like the tiles of a level.
+//   MoveBox returns where a box ends up moving dx, dy from x, y, and whether it was stopped on either axis.
+type Terrain interface {
+	MoveBox(x, y, w, h, dx, dy float64) (float64, float64, bool, bool)
+}
+
+// World holds bodies and steps them forward in time
+type World struct {
+	Bodies []*Body
+	// CellSize is the size of the broadphase grid's cells, a few times the size of a typical body works well
+	CellSize float64
+	// Gravity accelerates every body, in pixels per second per second. Positive Y is down
+	Gravity Vec
+	// MaxSteps caps how many steps Update does at once
+	MaxSteps int
+	// OnContact is called with both bodies of every contact in a step, after the bodies' own OnCollide
+	OnContact func(a, b *Body, normal Vec)
+	// Terrain, if set, is what bodies stand on and bump into besides each other
+	Terrain Terrain
+	// TimeStep is how many seconds each step simulates, DefaultTimeStep is used when it's 0
+	TimeStep float64
+
+	accumulator float64
+	grid        *spatial.Grid
+}
+
+// NewWorld creates a world with the given gravity
+func NewWorld(gravity Vec) *World {
+	return &World{
+		CellSize: DefaultCellSize,
+		Gravity:  gravity,
+		MaxSteps: DefaultMaxSteps,
+		TimeStep: DefaultTimeStep,
+	}
+}
+
+// Add puts bodies into the world
+func (w *World) Add(bodies ...*Body) {
+	w.Bodies = append(w.Bodies, bodies...)
+}
+
+// Remove takes a body out of the world
+func (w *World) Remove(body *Body) {
+	for i, b := range w.Bodies {
+		if b == body {
+			w.Bodies = append(w.Bodies[:i], w.Bodies[i+1:]...)
+			if w.grid != nil {
+				w.grid.Remove(body)
+			}
+			return
+		}
+	}
+}
+
+// Update advances the world by dt seconds, in as many fixed steps as fit.
+//   Time that doesn't make up a whole step is carried over to the next update.
+func (w *World) Update(dt float64) {
+	step := w.timeStep()
+	w.accumulator += dt
+	steps := 0
+	for w.accumulator >= step {
+		w.Step()
+		w.accumulator -= step
+		steps++
+		if w.MaxSteps > 0 && steps >= w.MaxSteps {
+			w.accumulator = 0
+			break
+		}
+	}
+}
+
+// timeStep returns how many seconds each step simulates
+func (w *World) timeStep() float64 {
+	if w.TimeStep <= 0 {
+		return DefaultTimeStep
+	}
+	return w.TimeStep
+}
+
+// Step advances the world by a single TimeStep
+func (w *World) Step() {
+	dt := w.timeStep()
+	for _, b := range w.Bodies {
+		b.OnGround = false
+		if b.Static() {
+			b.force = Vec{}
+			continue
+		}
+		// Semi-implicit Euler, velocity first so the new velocity moves the body
+		acceleration := w.Gravity.Scale(b.GravityScale).Add(b.force.Scale(b.invMass()))
+		b.Velocity = b.Velocity.Add(acceleration.Scale(dt))
+		b.force = Vec{}
+		w.move(b, b.Velocity.Scale(dt))
+	}
+
+	// Broadphase: only bodies sharing a cell of the grid can be touching.
+	//   Each pair is tested once, by the body that comes first in Bodies.
+	cellSize := w.CellSize
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	if w.grid == nil || w.grid.CellSize != cellSize {
+		w.grid = spatial.NewGrid(cellSize)
+	}
+	order := make(map[*Body]int, len(w.Bodies))
+	for i, b := range w.Bodies {
+		order[b] = i
+		w.grid.Insert(b, bounds(b))
+	}
+	for i, a := range w.Bodies {
+		for _, item := range w.grid.Query(bounds(a)) {
+			b := item.(*Body)
+			if order[b] <= i || a.Static() && b.Static() {
+				continue
+			}
+			m, ok := collide(a, b)
+			if !ok {
+				continue
+			}
+			if !a.Sensor && !b.Sensor {
+				resolve(a, b, m)
+			}
+			w.report(a, b, m)
+		}
+	}
+}
+
+// bounds returns the rect a body's shape covers
+func bounds(b *Body) spatial.Rect {
+	w, h := b.Shape.Size()
+	return spatial.Rect{X: b.Position.X, Y: b.Position.Y, W: w, H: h}
+}
+
+// move moves a body by delta, through the terrain if there is one
+func (w *World) move(b *Body, delta Vec) {
+	if w.Terrain == nil || b.Sensor {
+		b.Position = b.Position.Add(delta)
+		return
+	}
+	width, height := b.Shape.Size()
+	x, y, hitX, hitY := w.Terrain.MoveBox(b.Position.X, b.Position.Y, width, height, delta.X, delta.Y)
+	b.Position = Vec{x, y}
+	if hitX {
+		b.Velocity.X = -b.Velocity.X * b.Restitution
+		if math.Abs(b.Velocity.X) < restingSpeed {
+			b.Velocity.X = 0
+		}
+	}
+	if hitY {
+		if delta.Y > 0 {
+			b.OnGround = true
+		}
+		// The harder the body lands, the more friction slows it sliding along the ground
+		vy := b.Velocity.Y
+		b.Velocity.Y = -vy * b.Restitution
+		if math.Abs(vy) < restingSpeed {
+			b.Velocity.Y = 0
+		}
+		b.Velocity.X = applyFriction(b.Velocity.X, b.Friction*math.Abs(vy-b.Velocity.Y))
+	}
+}
+
+// resolve pushes two overlapping bodies apart and exchanges impulses between them
+func resolve(a, b *Body, m manifold) {
+	invA, invB := a.invMass(), b.invMass()
+	total := invA + invB
+	if total == 0 {
+		return
+	}
+
+	// Push the bodies out of each other, the lighter body moving furthest
+	if depth := math.Max(m.Depth-slop, 0) * correction / total; depth > 0 {
+		a.Position = a.Position.Sub(m.Normal.Scale(depth * invA))
+		b.Position = b.Position.Add(m.Normal.Scale(depth * invB))
+	}
+	// Whichever body is on top is resting on the other
+	if m.Normal.Y > 0.5 {
+		a.OnGround = true
+	} else if m.Normal.Y < -0.5 {
+		b.OnGround = true
+	}
+
+	relative := b.Velocity.Sub(a.Velocity)
+	closing := relative.Dot(m.Normal)
+	if closing > 0 {
+		// Already moving apart
+		return
+	}
+	restitution := math.Max(a.Restitution, b.Restitution)
+	if -closing < restingSpeed {
+		restitution = 0
+	}
+	j := -(1 + restitution) * closing / total
+	impulse := m.Normal.Scale(j)
+	a.Velocity = a.Velocity.Sub(impulse.Scale(invA))
+	b.Velocity = b.Velocity.Add(impulse.Scale(invB))
+
+	// Friction works against sliding along the contact, and can't exceed the normal impulse
+	tangent := Vec{-m.Normal.Y, m.Normal.X}
+	slide := b.Velocity.Sub(a.Velocity).Dot(tangent)
+	limit := math.Sqrt(a.Friction*b.Friction) * j
+	jt := math.Max(-limit, math.Min(-slide/total, limit))
+	frictionImpulse := tangent.Scale(jt)
+	a.Velocity = a.Velocity.Sub(frictionImpulse.Scale(invA))
+	b.Velocity = b.Velocity.Add(frictionImpulse.Scale(invB))
+}
+
+// report calls the collision callbacks for a contact
+func (w *World) report(a, b *Body, m manifold) {
+	if a.OnCollide != nil {
+		a.OnCollide(Collision{Other: b, Normal: m.Normal, Depth: m.Depth})
+	}
+	if b.OnCollide != nil {
+		b.OnCollide(Collision{Other: a, Normal: m.Normal.Scale(-1), Depth: m.Depth})
+	}
+	if w.OnContact != nil {
+		w.OnContact(a, b, m.Normal)
+	}
+}
+
+// applyFriction slows velocity v towards 0 by up to amount
+func applyFriction(v, amount float64) float64 {
+	if math.Abs(v) <= amount {
+		return 0
+	}
+	return v - math.Copysign(amount, v)
+}
@@ -0,0 +1,240 @@
+package physics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// near reports whether a and b are within a rounding error of each other
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestWorldZeroValue(t *testing.T) {
+	var w World
+	body := NewBody(AABB{W: 10, H: 10}, 1)
+	body.Velocity = Vec{X: 60}
+	w.Add(body)
+
+	done := make(chan struct{})
+	go func() {
+		w.Update(0.5)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Update on a zero World didn't return")
+	}
+	// Stepped at DefaultTimeStep, with no limit on how many steps
+	if !near(body.Position.X, 30) {
+		t.Errorf("body is at %v, want 30 after half a second at 60 pixels a second", body.Position.X)
+	}
+}
+
+func TestWorldIntegrate(t *testing.T) {
+	w := NewWorld(Vec{Y: 100})
+	w.TimeStep = 0.5
+	falling := NewBody(AABB{W: 1, H: 1}, 2)
+	floating := NewBody(AABB{W: 1, H: 1}, 1)
+	floating.Position = Vec{X: 100}
+	floating.GravityScale = 0
+	static := NewBody(AABB{W: 1, H: 1}, 0)
+	static.Position = Vec{X: 200}
+	static.Velocity = Vec{X: 10}
+	w.Add(falling, floating, static)
+
+	// A force lasts one step, and is divided by mass
+	falling.ApplyForce(Vec{X: 8})
+	w.Step()
+	// Velocity is updated before position
+	if !near(falling.Velocity.X, 2) || !near(falling.Velocity.Y, 50) {
+		t.Errorf("falling body's velocity is %v, want 2,50", falling.Velocity)
+	}
+	if !near(falling.Position.X, 1) || !near(falling.Position.Y, 25) {
+		t.Errorf("falling body is at %v, want 1,25", falling.Position)
+	}
+	w.Step()
+	if !near(falling.Velocity.X, 2) || !near(falling.Velocity.Y, 100) {
+		t.Errorf("falling body's velocity is %v after the force stopped, want 2,100", falling.Velocity)
+	}
+
+	if floating.Position != (Vec{X: 100}) {
+		t.Errorf("body without gravity moved to %v", floating.Position)
+	}
+	if static.Position != (Vec{X: 200}) {
+		t.Errorf("static body moved to %v", static.Position)
+	}
+}
+
+func TestWorldImpulse(t *testing.T) {
+	tests := []struct {
+		name        string
+		restitution float64
+		massB       float64
+		wantA       float64
+		wantB       float64
+	}{
+		// Equal bodies swap velocities when they bounce, and stop dead when they don't
+		{"elastic", 1, 1, -100, 100},
+		{"inelastic", 0, 1, 0, 0},
+		// A static body soaks up the whole impulse
+		{"wall", 1, 0, -100, 0},
+	}
+	for _, test := range tests {
+		w := NewWorld(Vec{})
+		a := NewBody(AABB{W: 10, H: 10}, 1)
+		a.Velocity = Vec{X: 100}
+		a.Restitution = test.restitution
+		b := NewBody(AABB{W: 10, H: 10}, test.massB)
+		b.Position = Vec{X: 9}
+		if !b.Static() {
+			b.Velocity = Vec{X: -100}
+		}
+		b.Restitution = test.restitution
+		var contacts int
+		w.OnContact = func(x, y *Body, normal Vec) {
+			contacts++
+			if x != a || y != b || normal != (Vec{X: 1}) {
+				t.Errorf("%s: contact between %p and %p along %v, want a to b along 1,0", test.name, x, y, normal)
+			}
+		}
+		w.Add(a, b)
+		w.Step()
+
+		if !near(a.Velocity.X, test.wantA) || !near(b.Velocity.X, test.wantB) {
+			t.Errorf("%s: velocities are %v and %v, want %v and %v", test.name, a.Velocity.X, b.Velocity.X, test.wantA, test.wantB)
+		}
+		if contacts != 1 {
+			t.Errorf("%s: %d contacts, want 1", test.name, contacts)
+		}
+		// and they're pushed apart
+		if a.Position.X+10-b.Position.X >= 9 {
+			t.Errorf("%s: bodies still overlap by %v", test.name, a.Position.X+10-b.Position.X)
+		}
+	}
+}
+
+func TestWorldSensor(t *testing.T) {
+	w := NewWorld(Vec{})
+	a := NewBody(AABB{W: 10, H: 10}, 1)
+	a.Velocity = Vec{X: 100}
+	sensor := NewBody(AABB{W: 10, H: 10}, 1)
+	sensor.Position = Vec{X: 5}
+	sensor.Sensor = true
+	var touched *Body
+	sensor.OnCollide = func(c Collision) { touched = c.Other }
+	w.Add(a, sensor)
+	w.Step()
+	if touched != a {
+		t.Error("the sensor didn't report the body overlapping it")
+	}
+	if !near(a.Velocity.X, 100) {
+		t.Errorf("the sensor changed the body's velocity to %v", a.Velocity.X)
+	}
+}
+
+func TestWorldOnGroundOnBody(t *testing.T) {
+	// Whichever order the bodies are in, the one on top is on the ground
+	for _, topFirst := range []bool{true, false} {
+		w := NewWorld(Vec{Y: 100})
+		top := NewBody(AABB{W: 10, H: 10}, 1)
+		floor := NewBody(AABB{W: 100, H: 10}, 0)
+		floor.Position = Vec{Y: 10}
+		if topFirst {
+			w.Add(top, floor)
+		} else {
+			w.Add(floor, top)
+		}
+		for i := 0; i < 30; i++ {
+			w.Step()
+		}
+		if !top.OnGround || floor.OnGround {
+			t.Errorf("top first %v: top on ground %v, floor on ground %v, want only the top", topFirst, top.OnGround, floor.OnGround)
+		}
+		if top.Position.Y > 0.1 {
+			t.Errorf("top first %v: top sank to %v", topFirst, top.Position.Y)
+		}
+	}
+}
+
+// platform is terrain with a single one way platform along y = 100, which can only be landed on from above
+type platform struct{}
+
+func (platform) MoveBox(x, y, w, h, dx, dy float64) (float64, float64, bool, bool) {
+	if dy > 0 && y+h <= 100 && y+h+dy > 100 {
+		return x + dx, 100 - h, false, true
+	}
+	return x + dx, y + dy, false, false
+}
+
+func TestWorldOneWayTerrain(t *testing.T) {
+	w := NewWorld(Vec{Y: 400})
+	w.Terrain = platform{}
+	landing := NewBody(AABB{W: 10, H: 10}, 1)
+	landing.Position = Vec{Y: 50}
+	jumping := NewBody(AABB{W: 10, H: 10}, 1)
+	jumping.Position = Vec{X: 50, Y: 120}
+	jumping.Velocity = Vec{Y: -300}
+	w.Add(landing, jumping)
+
+	landed, highest := false, jumping.Position.Y
+	for i := 0; i < 120; i++ {
+		w.Step()
+		landed = landed || landing.OnGround
+		highest = math.Min(highest, jumping.Position.Y)
+	}
+	if !landed || !near(landing.Position.Y, 90) || landing.Velocity.Y != 0 {
+		t.Errorf("falling body is at %v moving %v, want it resting on the platform", landing.Position.Y, landing.Velocity.Y)
+	}
+	// Jumping up through the platform from below, then landing on it on the way down
+	if highest >= 90 {
+		t.Errorf("jumping body only got to %v, want it through the platform", highest)
+	}
+	if !near(jumping.Position.Y, 90) || !jumping.OnGround {
+		t.Errorf("jumping body is at %v on ground %v, want it resting on the platform", jumping.Position.Y, jumping.OnGround)
+	}
+}
+
+func TestWorldMaxSteps(t *testing.T) {
+	tests := []struct {
+		maxSteps int
+		dt       float64
+		want     float64
+	}{
+		{0, 1, 4},
+		{2, 1, 2},
+		{8, 0.375, 1},
+	}
+	for _, test := range tests {
+		w := NewWorld(Vec{})
+		w.TimeStep = 0.25
+		w.MaxSteps = test.maxSteps
+		body := NewBody(AABB{W: 1, H: 1}, 1)
+		body.Velocity = Vec{X: 1}
+		w.Add(body)
+		w.Update(test.dt)
+		if steps := body.Position.X / 0.25; !near(steps, test.want) {
+			t.Errorf("MaxSteps %d: updating %vs took %v steps, want %v", test.maxSteps, test.dt, steps, test.want)
+		}
+	}
+
+	// Leftover time carries over to the next update, unless the step limit was hit
+	w := NewWorld(Vec{})
+	w.TimeStep = 0.25
+	w.MaxSteps = 2
+	body := NewBody(AABB{W: 1, H: 1}, 1)
+	body.Velocity = Vec{X: 1}
+	w.Add(body)
+	w.Update(0.375)
+	w.Update(0.125)
+	if !near(body.Position.X, 0.5) {
+		t.Errorf("body is at %v, want 0.5 after the leftover made a second step", body.Position.X)
+	}
+	w.Update(1)
+	w.Update(0.125)
+	if !near(body.Position.X, 1) {
+		t.Errorf("body is at %v, want 1 once the time over the limit was dropped", body.Position.X)
+	}
+}
@@ -0,0 +1,283 @@
+// Package spatial indexes things by where they are, so finding what's near a point, in view of a camera
+//   or along a line of sight doesn't mean looking at everything in a level.
+package spatial
+
+import (
+	"math"
+	"sort"
+)
+
+// Rect is an axis aligned rectangle, X, Y is its top left
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Intersects reports whether two rects overlap. Rects that only touch along an edge don't overlap.
+func (r Rect) Intersects(o Rect) bool {
+	return r.X < o.X+o.W && o.X < r.X+r.W && r.Y < o.Y+o.H && o.Y < r.Y+r.H
+}
+
+// Hit is an item a ray passed through, Distance along the ray from its origin
+type Hit struct {
+	Item     interface{}
+	Distance float64
+}
+
+// Grid is a uniform grid of square cells, each holding the items whose rects overlap it.
+//   Items can be anything comparable, usually pointers. Queries return items in a stable order,
+//   the order of the cells they're found in and then the order they were added to those cells,
+//   so anything built on top of them stays deterministic.
+type Grid struct {
+	// CellSize is the width and height of each cell. A few times the size of a typical item works well
+	CellSize float64
+
+	cells map[cell][]interface{}
+	items map[interface{}]*entry
+}
+
+// cell is the coordinates of a cell in the grid
+type cell struct {
+	x, y int
+}
+
+// entry is where an item is, and the range of cells it's in
+type entry struct {
+	rect                   Rect
+	minX, minY, maxX, maxY int
+}
+
+// NewGrid creates an empty grid with cells of the given size
+func NewGrid(cellSize float64) *Grid {
+	return &Grid{
+		CellSize: cellSize,
+		cells:    map[cell][]interface{}{},
+		items:    map[interface{}]*entry{},
+	}
+}
+
+// Insert adds an item covering rect r to the grid, or moves it there if it's already in the grid
+func (g *Grid) Insert(item interface{}, r Rect) {
+	if e, ok := g.items[item]; ok {
+		g.move(item, e, r)
+		return
+	}
+	e := g.entry(r)
+	g.items[item] = e
+	g.add(item, e)
+}
+
+// Move updates where an item is, returning false if the item isn't in the grid
+func (g *Grid) Move(item interface{}, r Rect) bool {
+	e, ok := g.items[item]
+	if !ok {
+		return false
+	}
+	g.move(item, e, r)
+	return true
+}
+
+// Remove takes an item out of the grid, returning false if it wasn't in the grid
+func (g *Grid) Remove(item interface{}) bool {
+	e, ok := g.items[item]
+	if !ok {
+		return false
+	}
+	g.remove(item, e)
+	delete(g.items, item)
+	return true
+}
+
+// Rect returns the rect an item covers
+func (g *Grid) Rect(item interface{}) (Rect, bool) {
+	e, ok := g.items[item]
+	if !ok {
+		return Rect{}, false
+	}
+	return e.rect, true
+}
+
+// Items returns every item in the grid, in no particular order
+func (g *Grid) Items() []interface{} {
+	items := make([]interface{}, 0, len(g.items))
+	for item := range g.items {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Len returns how many items are in the grid
+func (g *Grid) Len() int {
+	return len(g.items)
+}
+
+// Query returns every item whose rect overlaps r
+func (g *Grid) Query(r Rect) []interface{} {
+	var found []interface{}
+	seen := map[interface{}]bool{}
+	q := g.entry(r)
+	for y := q.minY; y <= q.maxY; y++ {
+		for x := q.minX; x <= q.maxX; x++ {
+			for _, item := range g.cells[cell{x, y}] {
+				if seen[item] {
+					continue
+				}
+				seen[item] = true
+				if g.items[item].rect.Intersects(r) {
+					found = append(found, item)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// QueryRadius returns every item whose rect is within radius of x, y
+func (g *Grid) QueryRadius(x, y, radius float64) []interface{} {
+	var found []interface{}
+	for _, item := range g.Query(Rect{X: x - radius, Y: y - radius, W: radius * 2, H: radius * 2}) {
+		r := g.items[item].rect
+		// Distance from the point to the closest point of the rect
+		dx := x - math.Max(r.X, math.Min(x, r.X+r.W))
+		dy := y - math.Max(r.Y, math.Min(y, r.Y+r.H))
+		if dx*dx+dy*dy <= radius*radius {
+			found = append(found, item)
+		}
+	}
+	return found
+}
+
+// Raycast returns the items a ray from x, y in direction dx, dy passes through within length, closest first.
+//   Only the cells along the ray are visited, so long rays through sparse levels are cheap.
+func (g *Grid) Raycast(x, y, dx, dy, length float64) []Hit {
+	l := math.Hypot(dx, dy)
+	if l == 0 || length <= 0 {
+		return nil
+	}
+	dx, dy = dx/l, dy/l
+
+	var hits []Hit
+	seen := map[interface{}]bool{}
+	cx, cy := g.cellOf(x), g.cellOf(y)
+	stepX, tMaxX, tDeltaX := g.traverse(x, dx, cx)
+	stepY, tMaxY, tDeltaY := g.traverse(y, dy, cy)
+	for t := 0.0; t <= length; {
+		for _, item := range g.cells[cell{cx, cy}] {
+			if seen[item] {
+				continue
+			}
+			seen[item] = true
+			if d, ok := rayRect(x, y, dx, dy, g.items[item].rect); ok && d <= length {
+				hits = append(hits, Hit{Item: item, Distance: d})
+			}
+		}
+		// Step into whichever neighbouring cell the ray reaches first
+		if tMaxX < tMaxY {
+			t = tMaxX
+			tMaxX += tDeltaX
+			cx += stepX
+		} else {
+			t = tMaxY
+			tMaxY += tDeltaY
+			cy += stepY
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+	return hits
+}
+
+// traverse works out how a ray crosses cells along one axis: which way it steps, how far along
+//   the ray the first cell boundary is, and how far apart boundaries are along the ray
+func (g *Grid) traverse(origin, dir float64, c int) (int, float64, float64) {
+	switch {
+	case dir > 0:
+		return 1, (float64(c+1)*g.CellSize - origin) / dir, g.CellSize / dir
+	case dir < 0:
+		return -1, (float64(c)*g.CellSize - origin) / dir, -g.CellSize / dir
+	}
+	return 0, math.Inf(1), math.Inf(1)
+}
+
+// rayRect returns how far along a ray it first enters a rect, using the slab method
+func rayRect(x, y, dx, dy float64, r Rect) (float64, bool) {
+	tMin, tMax := 0.0, math.Inf(1)
+	for _, axis := range [2][4]float64{{x, dx, r.X, r.X + r.W}, {y, dy, r.Y, r.Y + r.H}} {
+		origin, dir, lo, hi := axis[0], axis[1], axis[2], axis[3]
+		if dir == 0 {
+			if origin < lo || origin > hi {
+				return 0, false
+			}
+			continue
+		}
+		t1, t2 := (lo-origin)/dir, (hi-origin)/dir
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin, tMax = math.Max(tMin, t1), math.Min(tMax, t2)
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+	return tMin, true
+}
+
+// cellOf returns the cell coordinate a world coordinate falls in
+func (g *Grid) cellOf(v float64) int {
+	return int(math.Floor(v / g.CellSize))
+}
+
+// entry works out the range of cells a rect covers. The far edges are exclusive, like Intersects
+func (g *Grid) entry(r Rect) *entry {
+	e := &entry{rect: r, minX: g.cellOf(r.X), minY: g.cellOf(r.Y), maxX: g.cellOf(r.X + r.W), maxY: g.cellOf(r.Y + r.H)}
+	if r.W > 0 && math.Mod(r.X+r.W, g.CellSize) == 0 {
+		e.maxX--
+	}
+	if r.H > 0 && math.Mod(r.Y+r.H, g.CellSize) == 0 {
+		e.maxY--
+	}
+	return e
+}
+
+// move updates an entry, only touching the cells if the item moved into different ones
+func (g *Grid) move(item interface{}, e *entry, r Rect) {
+	next := g.entry(r)
+	if next.minX == e.minX && next.minY == e.minY && next.maxX == e.maxX && next.maxY == e.maxY {
+		e.rect = r
+		return
+	}
+	g.remove(item, e)
+	*e = *next
+	g.add(item, e)
+}
+
+// add puts an item in every cell of its entry
+func (g *Grid) add(item interface{}, e *entry) {
+	for y := e.minY; y <= e.maxY; y++ {
+		for x := e.minX; x <= e.maxX; x++ {
+			c := cell{x, y}
+			g.cells[c] = append(g.cells[c], item)
+		}
+	}
+}
+
+// remove takes an item out of every cell of its entry, dropping cells that end up empty
+func (g *Grid) remove(item interface{}, e *entry) {
+	for y := e.minY; y <= e.maxY; y++ {
+		for x := e.minX; x <= e.maxX; x++ {
+			c := cell{x, y}
+			items := g.cells[c]
+			for i, other := range items {
+				if other == item {
+					items = append(items[:i], items[i+1:]...)
+					break
+				}
+			}
+			if len(items) == 0 {
+				delete(g.cells, c)
+			} else {
+				g.cells[c] = items
+			}
+		}
+	}
+}
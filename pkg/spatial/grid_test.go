@@ -0,0 +1,175 @@
+package spatial
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGridInsertMoveRemove(t *testing.T) {
+	g := NewGrid(32)
+	g.Insert("a", Rect{X: 0, Y: 0, W: 16, H: 16})
+	g.Insert("b", Rect{X: 40, Y: 40, W: 16, H: 16})
+	if g.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", g.Len())
+	}
+
+	if got := g.Query(Rect{X: 0, Y: 0, W: 32, H: 32}); !reflect.DeepEqual(got, []interface{}{"a"}) {
+		t.Errorf("Query of the first cell = %v, want [a]", got)
+	}
+
+	// Moving within a cell just updates the rect
+	if !g.Move("a", Rect{X: 8, Y: 8, W: 16, H: 16}) {
+		t.Fatal("Move(a) = false, want true")
+	}
+	if r, _ := g.Rect("a"); r != (Rect{X: 8, Y: 8, W: 16, H: 16}) {
+		t.Errorf("Rect(a) = %v after a move within its cell", r)
+	}
+	if got := g.Query(Rect{X: 0, Y: 0, W: 8, H: 8}); len(got) != 0 {
+		t.Errorf("Query where a used to be = %v, want nothing", got)
+	}
+
+	// Moving into another cell takes it out of the old one
+	g.Move("a", Rect{X: 100, Y: 100, W: 4, H: 4})
+	if got := g.Query(Rect{X: 0, Y: 0, W: 32, H: 32}); len(got) != 0 {
+		t.Errorf("Query of a's old cell = %v, want nothing", got)
+	}
+	if got := g.Query(Rect{X: 96, Y: 96, W: 32, H: 32}); !reflect.DeepEqual(got, []interface{}{"a"}) {
+		t.Errorf("Query of a's new cell = %v, want [a]", got)
+	}
+	if _, ok := g.cells[cell{0, 0}]; ok {
+		t.Error("a's old cell wasn't dropped once it was empty")
+	}
+
+	// Inserting an item that's already in the grid moves it
+	g.Insert("b", Rect{X: 100, Y: 100, W: 4, H: 4})
+	if g.Len() != 2 {
+		t.Errorf("Len() = %d after inserting b again, want 2", g.Len())
+	}
+	if got := g.Query(Rect{X: 32, Y: 32, W: 32, H: 32}); len(got) != 0 {
+		t.Errorf("Query of b's old cell = %v, want nothing", got)
+	}
+
+	if !g.Remove("a") {
+		t.Error("Remove(a) = false, want true")
+	}
+	if g.Remove("a") {
+		t.Error("Remove(a) twice = true, want false")
+	}
+	if g.Move("a", Rect{}) {
+		t.Error("Move of a removed item = true, want false")
+	}
+	if _, ok := g.Rect("a"); ok {
+		t.Error("Rect of a removed item found it")
+	}
+	if got := g.Items(); !reflect.DeepEqual(got, []interface{}{"b"}) {
+		t.Errorf("Items() = %v, want [b]", got)
+	}
+}
+
+func TestGridQuery(t *testing.T) {
+	g := NewGrid(32)
+	// wide spans four cells across, tall spans three down, corner sits across the corner of four cells
+	g.Insert("wide", Rect{X: 10, Y: 10, W: 100, H: 4})
+	g.Insert("tall", Rect{X: 200, Y: 0, W: 4, H: 80})
+	g.Insert("corner", Rect{X: 24, Y: 24, W: 16, H: 16})
+	// edge exactly fills one cell, so it mustn't leak into the cells its far edges touch
+	g.Insert("edge", Rect{X: 64, Y: 64, W: 32, H: 32})
+
+	// wide and corner share cells 0, 0 and 1, 0
+	if got := len(g.cells); got != 10 {
+		t.Errorf("items fill %d cells, want 10", got)
+	}
+
+	tests := []struct {
+		name string
+		rect Rect
+		want []interface{}
+	}{
+		{"far end of wide", Rect{X: 100, Y: 0, W: 8, H: 20}, []interface{}{"wide"}},
+		{"bottom of tall", Rect{X: 190, Y: 70, W: 20, H: 20}, []interface{}{"tall"}},
+		{"every cell corner touches", Rect{X: 36, Y: 36, W: 2, H: 2}, []interface{}{"corner"}},
+		{"across two items", Rect{X: 0, Y: 0, W: 40, H: 40}, []interface{}{"wide", "corner"}},
+		{"everything", Rect{X: -100, Y: -100, W: 400, H: 400}, []interface{}{"wide", "corner", "tall", "edge"}},
+		{"in a cell but not overlapping", Rect{X: 0, Y: 20, W: 4, H: 4}, nil},
+		{"touching edge's far edge", Rect{X: 96, Y: 64, W: 8, H: 8}, nil},
+		{"touching edge's near edge", Rect{X: 60, Y: 64, W: 4, H: 4}, nil},
+		{"inside edge", Rect{X: 95, Y: 95, W: 1, H: 1}, []interface{}{"edge"}},
+	}
+	for _, test := range tests {
+		if got := g.Query(test.rect); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Query(%v) = %v, want %v", test.name, test.rect, got, test.want)
+		}
+	}
+}
+
+func TestGridNegative(t *testing.T) {
+	g := NewGrid(32)
+	g.Insert("left", Rect{X: -48, Y: 0, W: 16, H: 16})
+	g.Insert("above", Rect{X: 0, Y: -40, W: 8, H: 8})
+	g.Insert("across", Rect{X: -8, Y: -8, W: 16, H: 16})
+	// Ends exactly on the origin, so it's only in cell -1, -1
+	g.Insert("up left", Rect{X: -32, Y: -32, W: 32, H: 32})
+
+	tests := []struct {
+		name string
+		rect Rect
+		want []interface{}
+	}{
+		{"cell -2, 0", Rect{X: -64, Y: 0, W: 32, H: 32}, []interface{}{"left"}},
+		{"cell 0, -2", Rect{X: 0, Y: -64, W: 32, H: 32}, []interface{}{"above"}},
+		{"cell -1, -1", Rect{X: -32, Y: -32, W: 32, H: 32}, []interface{}{"across", "up left"}},
+		{"cell 0, 0", Rect{X: 0, Y: 0, W: 32, H: 32}, []interface{}{"across"}},
+		{"around the origin", Rect{X: -1, Y: -1, W: 2, H: 2}, []interface{}{"across", "up left"}},
+		{"between left and across", Rect{X: -30, Y: 0, W: 20, H: 20}, nil},
+	}
+	for _, test := range tests {
+		if got := g.Query(test.rect); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Query(%v) = %v, want %v", test.name, test.rect, got, test.want)
+		}
+	}
+
+	for c := range g.cells {
+		if c.x > 0 || c.y > 0 {
+			t.Errorf("up left, above or across leaked into cell %v", c)
+		}
+	}
+}
+
+func TestGridQueryRadius(t *testing.T) {
+	g := NewGrid(32)
+	g.Insert("near", Rect{X: 9, Y: 0, W: 4, H: 4})
+	// Inside the radius' bounding box but not the circle
+	g.Insert("corner", Rect{X: 8, Y: 8, W: 4, H: 4})
+	g.Insert("far", Rect{X: 50, Y: 50, W: 4, H: 4})
+
+	if got, want := g.QueryRadius(0, 0, 10), []interface{}{"near"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryRadius(0, 0, 10) = %v, want %v", got, want)
+	}
+}
+
+func TestGridRaycast(t *testing.T) {
+	g := NewGrid(32)
+	g.Insert("first", Rect{X: 40, Y: 0, W: 8, H: 8})
+	g.Insert("second", Rect{X: 100, Y: 0, W: 8, H: 8})
+	g.Insert("off the ray", Rect{X: 70, Y: 20, W: 8, H: 8})
+	g.Insert("behind", Rect{X: -40, Y: 0, W: 8, H: 8})
+
+	tests := []struct {
+		name         string
+		x, y, dx, dy float64
+		length       float64
+		want         []Hit
+	}{
+		{"right", 0, 4, 1, 0, 200, []Hit{{"first", 40}, {"second", 100}}},
+		{"too short", 0, 4, 1, 0, 50, []Hit{{"first", 40}}},
+		{"left", 0, 4, -2, 0, 200, []Hit{{"behind", 32}}},
+		{"back from the far side", 150, 4, -1, 0, 200, []Hit{{"second", 42}, {"first", 102}, {"behind", 182}}},
+		{"no direction", 0, 4, 0, 0, 200, nil},
+	}
+	for _, test := range tests {
+		got := g.Raycast(test.x, test.y, test.dx, test.dy, test.length)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Raycast = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
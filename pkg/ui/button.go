@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+)
+
+// Button does something when it's clicked, or confirm is pressed while it's focused
+type Button struct {
+	Base
+	OnClick func()
+	Text    string
+
+	rich engine.RichText
+}
+
+// NewButton returns a button of text that calls onClick
+func NewButton(text string, onClick func()) *Button {
+	return &Button{OnClick: onClick, Text: text}
+}
+
+// CanFocus reports whether the button can be used
+func (b *Button) CanFocus() bool {
+	return b.usable()
+}
+
+// Activate calls OnClick
+func (b *Button) Activate() {
+	if b.usable() && b.OnClick != nil {
+		b.OnClick()
+	}
+}
+
+// Size fits the text inside the theme's padding
+func (b *Button) Size() (int32, int32) {
+	theme := b.CurrentTheme()
+	w, h := layOutText(&b.rich, theme.Font, escape(b.Text), 0)
+	return w + theme.Padding*2, h + theme.Padding
+}
+
+// Draw draws the button with its text in the middle
+func (b *Button) Draw(renderer engine.Renderer) {
+	theme := b.CurrentTheme()
+	theme.control(renderer, b.bounds, b.focused || b.hovered && b.usable())
+	w, h := layOutText(&b.rich, theme.Font, escape(b.Text), 0)
+	drawText(renderer, &b.rich, b.bounds.X+(b.bounds.W-w)/2, b.bounds.Y+(b.bounds.H-h)/2, b.textColor())
+}
+
+// Free lets go of the button's laid out text
+func (b *Button) Free() {
+	b.rich.Free()
+}
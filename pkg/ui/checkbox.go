@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Checkbox is a box that's checked and unchecked when it's clicked, or confirm is pressed while it's focused
+type Checkbox struct {
+	Base
+	Checked bool
+	// OnChange is called with whether the box is checked after it changes
+	OnChange func(checked bool)
+	Text     string
+
+	rich engine.RichText
+}
+
+// NewCheckbox returns a checkbox labelled with text
+func NewCheckbox(text string, checked bool, onChange func(bool)) *Checkbox {
+	return &Checkbox{Checked: checked, OnChange: onChange, Text: text}
+}
+
+// CanFocus reports whether the checkbox can be used
+func (c *Checkbox) CanFocus() bool {
+	return c.usable()
+}
+
+// Activate checks the box if it's unchecked, and unchecks it if it's checked
+func (c *Checkbox) Activate() {
+	if !c.usable() {
+		return
+	}
+	c.Checked = !c.Checked
+	if c.OnChange != nil {
+		c.OnChange(c.Checked)
+	}
+}
+
+// Size fits a box as high as a line of text, then the text beside it
+func (c *Checkbox) Size() (int32, int32) {
+	theme := c.CurrentTheme()
+	box := theme.lineHeight()
+	w, h := layOutText(&c.rich, theme.Font, escape(c.Text), 0)
+	if h < box {
+		h = box
+	}
+	return box + theme.Spacing + w, h
+}
+
+// Draw draws the box, filled in when it's checked, and its text
+func (c *Checkbox) Draw(renderer engine.Renderer) {
+	theme := c.CurrentTheme()
+	size := theme.lineHeight()
+	box := sdl.Rect{X: c.bounds.X, Y: c.bounds.Y + (c.bounds.H-size)/2, W: size, H: size}
+	theme.control(renderer, box, c.focused || c.hovered && c.usable())
+	if c.Checked {
+		inset := size / 4
+		theme.fill(renderer, sdl.Rect{X: box.X + inset, Y: box.Y + inset, W: size - inset*2, H: size - inset*2}, theme.Accent, nil)
+	}
+	_, h := layOutText(&c.rich, theme.Font, escape(c.Text), 0)
+	drawText(renderer, &c.rich, box.X+size+theme.Spacing, c.bounds.Y+(c.bounds.H-h)/2, c.textColor())
+}
+
+// Free lets go of the checkbox's laid out text
+func (c *Checkbox) Free() {
+	c.rich.Free()
+}
@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// dialogSpeed is how many characters a second a DialogBox reveals when it isn't given a speed
+const dialogSpeed = 40
+
+// Message is something said in a DialogBox
+type Message struct {
+	// Portrait is drawn on the left of the box, at its own size or as high as the box fits
+	Portrait *Image
+	// Speaker is the name shown above the text
+	Speaker string
+	// Text is what's said, which can be styled with markup, see engine.RichText
+	Text string
+}
+
+// DialogBox shows messages a page at a time, drawn over a nine slice image, eg: characters talking in an RPG.
+//   Each page's text is revealed a character at a time, then waits for confirm or a click, which also reveals
+//   the rest of the page when it's pressed early. Text too long for the box is split into pages at its lines.
+//   The box hides itself after the last page of its last message, so put it on a Screen, eg:
+//
+//   dialog := ui.NewDialogBox(func() { menu.Break = true })
+//   dialog.Say(ui.Message{Speaker: "Computer", Text: "Hello, world."})
+type DialogBox struct {
+	Base
+	// Background is drawn behind the box instead of the theme's Panel
+	Background *engine.NineSlice
+	// Lines is the most lines a page has, as many as fit in the box when it's 0
+	Lines int
+	// OnDone is called after the last page of the last message is confirmed
+	OnDone func()
+	// Speed is how many characters are revealed a second, the whole page is shown at once when it's negative
+	Speed float64
+
+	blink   float64
+	message Message
+	name    engine.RichText
+	page    int
+	// pages are the lines each page starts on
+	pages    []int
+	portrait sdl.Rect
+	queue    []Message
+	shown    float64
+	text     engine.RichText
+	textArea sdl.Rect
+}
+
+// NewDialogBox returns a hidden dialog box, anchored to the bottom of the area it's in, that calls onDone
+//   once it's said everything
+func NewDialogBox(onDone func()) *DialogBox {
+	return &DialogBox{Base: Base{Anchor: Bottom, Hidden: true, RelW: 0.9, RelH: 0.3, Y: -16}, OnDone: onDone}
+}
+
+// Say queues messages to show after any that are being shown, and shows the box
+func (d *DialogBox) Say(messages ...Message) {
+	d.queue = append(d.queue, messages...)
+	if d.Hidden || d.message.Text == "" && d.message.Speaker == "" {
+		d.next()
+	}
+}
+
+// Message returns the message being shown
+func (d *DialogBox) Message() Message {
+	return d.message
+}
+
+// Revealed reports whether all of the page being shown has been revealed, and the box is waiting for confirm
+func (d *DialogBox) Revealed() bool {
+	first, end := d.pageItems()
+	return d.Speed < 0 || int(d.shown) >= end-first
+}
+
+// CanFocus reports whether the box is showing a message
+func (d *DialogBox) CanFocus() bool {
+	return d.usable()
+}
+
+// Activate reveals the rest of the page, or moves on to the next page or message once it's all revealed
+func (d *DialogBox) Activate() {
+	if !d.usable() {
+		return
+	}
+	if !d.Revealed() {
+		first, end := d.pageItems()
+		d.shown = float64(end - first)
+		return
+	}
+	if d.page+1 < len(d.pages) {
+		d.page++
+		d.shown, d.blink = 0, 0
+		return
+	}
+	d.next()
+}
+
+// Size is the smallest the box can be, with room for the speaker's name and a line of text
+func (d *DialogBox) Size() (int32, int32) {
+	theme := d.CurrentTheme()
+	return theme.Padding*2 + theme.lineHeight()*10, theme.Padding*2 + theme.lineHeight()*2 + theme.Spacing
+}
+
+// Layout places the portrait, name and text in the box, and splits the text into pages that fit
+func (d *DialogBox) Layout(bounds sdl.Rect) {
+	d.bounds = bounds
+	theme := d.CurrentTheme()
+	inner := sdl.Rect{X: bounds.X + theme.Padding, Y: bounds.Y + theme.Padding, W: bounds.W - theme.Padding*2, H: bounds.H - theme.Padding*2}
+
+	d.portrait = sdl.Rect{}
+	if p := d.message.Portrait; p != nil {
+		w, h := size(p, inner)
+		if h > inner.H && h > 0 {
+			w, h = w*inner.H/h, inner.H
+		}
+		d.portrait = sdl.Rect{X: inner.X, Y: inner.Y + (inner.H-h)/2, W: w, H: h}
+		p.Layout(d.portrait)
+		inner.X += w + theme.Spacing
+		inner.W -= w + theme.Spacing
+	}
+	if d.message.Speaker != "" {
+		_, h := layOutText(&d.name, theme.Font, escape(d.message.Speaker), 0)
+		inner.Y += h + theme.Spacing
+		inner.H -= h + theme.Spacing
+	}
+	d.textArea = inner
+	d.paginate()
+}
+
+// Update reveals more of the page, and blinks the arrow shown when there's more to read
+func (d *DialogBox) Update(dt float64) {
+	d.blink += dt
+	if d.Speed == 0 {
+		d.shown += dialogSpeed * dt
+	} else {
+		d.shown += d.Speed * dt
+	}
+}
+
+// Draw draws the box, the portrait and name of the speaker, and as much of the page as has been revealed
+func (d *DialogBox) Draw(renderer engine.Renderer) {
+	theme := d.CurrentTheme()
+	background := d.Background
+	if background == nil {
+		background = theme.Panel
+	}
+	theme.fill(renderer, d.bounds, theme.Background, background)
+	if d.message.Portrait != nil {
+		d.message.Portrait.Draw(renderer)
+	}
+	if d.message.Speaker != "" {
+		_, h := layOutText(&d.name, theme.Font, escape(d.message.Speaker), 0)
+		drawText(renderer, &d.name, d.textArea.X, d.textArea.Y-h-theme.Spacing, theme.FocusText)
+	}
+
+	layout := d.text.Layout()
+	if d.page < len(d.pages) && d.pages[d.page] < len(layout.Lines) {
+		first, end := d.pageItems()
+		if !d.Revealed() {
+			end = first + int(d.shown)
+		}
+		top := layout.Lines[d.pages[d.page]].Y
+		layout.DrawPart(renderer, d.textArea.X, d.textArea.Y-top, theme.Text, first, end)
+	}
+
+	// An arrow at the bottom right bobs up and down while there's another page or message to read
+	if d.Revealed() && (d.page+1 < len(d.pages) || len(d.queue) > 0) {
+		size := theme.lineHeight() / 4
+		if size < 2 {
+			size = 2
+		}
+		x := d.bounds.X + d.bounds.W - theme.Padding - size*2
+		y := d.bounds.Y + d.bounds.H - theme.Padding - size
+		if int(d.blink*2)%2 == 1 {
+			y -= size / 2
+		}
+		// Drawn a row at a time, each narrower than the last
+		for row := int32(0); row < size; row++ {
+			renderer.Line(x+row, y+row, x+size*2-row-1, y+row, theme.FocusText)
+		}
+	}
+}
+
+// Free lets go of the box's laid out text
+func (d *DialogBox) Free() {
+	d.name.Free()
+	d.text.Free()
+}
+
+// next shows the next message, or hides the box and calls OnDone when there isn't one
+func (d *DialogBox) next() {
+	d.page, d.pages, d.shown, d.blink = 0, nil, 0, 0
+	if len(d.queue) == 0 {
+		d.message = Message{}
+		d.Hidden = true
+		if d.OnDone != nil {
+			d.OnDone()
+		}
+		return
+	}
+	d.message, d.queue = d.queue[0], d.queue[1:]
+	d.Hidden = false
+	d.Layout(d.bounds)
+}
+
+// paginate lays the text out in the text area and splits its lines into pages that fit in it
+func (d *DialogBox) paginate() {
+	d.pages = d.pages[:0]
+	if d.textArea.W <= 0 {
+		return
+	}
+	theme := d.CurrentTheme()
+	d.text.LineSpacing = theme.Spacing / 2
+	layOutText(&d.text, theme.Font, d.message.Text, d.textArea.W)
+	layout := d.text.Layout()
+	for line := 0; line < len(layout.Lines); {
+		d.pages = append(d.pages, line)
+		top := layout.Lines[line].Y
+		count := 0
+		for line < len(layout.Lines) {
+			bottom := layout.Lines[line].Y + layout.Lines[line].H - top
+			if count > 0 && (bottom > d.textArea.H || d.Lines > 0 && count >= d.Lines) {
+				break
+			}
+			line++
+			count++
+		}
+	}
+	if d.page >= len(d.pages) {
+		d.page = 0
+	}
+}
+
+// pageItems returns the characters and icons drawn on the page being shown
+func (d *DialogBox) pageItems() (int, int) {
+	if d.page >= len(d.pages) {
+		return 0, 0
+	}
+	layout := d.text.Layout()
+	first, end := layout.LineStart(d.pages[d.page]), layout.Len()
+	if d.page+1 < len(d.pages) {
+		end = layout.LineStart(d.pages[d.page+1])
+	}
+	return first, end
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Image draws a texture, or part of one, stretched over the widget
+type Image struct {
+	Base
+	// Src is the part of Texture that's drawn, all of it when it's empty
+	Src     sdl.Rect
+	Texture engine.Texture
+}
+
+// NewImage returns an image of a whole texture
+func NewImage(texture engine.Texture) *Image {
+	return &Image{Texture: texture}
+}
+
+// Size is how big the texture, or part of it, is
+func (i *Image) Size() (int32, int32) {
+	if i.Src.W > 0 && i.Src.H > 0 {
+		return i.Src.W, i.Src.H
+	}
+	if i.Texture == nil {
+		return 0, 0
+	}
+	return i.Texture.Size()
+}
+
+// Draw draws the texture over the widget
+func (i *Image) Draw(renderer engine.Renderer) {
+	if i.Texture == nil {
+		return
+	}
+	var src *sdl.Rect
+	if i.Src.W > 0 && i.Src.H > 0 {
+		src = &i.Src
+	}
+	bounds := i.bounds
+	renderer.Copy(i.Texture, src, &bounds)
+}
+
+// NineSlice draws a bordered image, like a frame, stretched over the widget without distorting its borders
+type NineSlice struct {
+	Base
+	Slice *engine.NineSlice
+}
+
+// NewNineSlice returns a widget of a nine slice image
+func NewNineSlice(slice *engine.NineSlice) *NineSlice {
+	return &NineSlice{Slice: slice}
+}
+
+// Size is the smallest the image can be without squashing its borders
+func (n *NineSlice) Size() (int32, int32) {
+	if n.Slice == nil {
+		return 0, 0
+	}
+	return n.Slice.Size()
+}
+
+// Draw draws the image over the widget
+func (n *NineSlice) Draw(renderer engine.Renderer) {
+	if n.Slice != nil {
+		n.Slice.Draw(renderer, n.bounds)
+	}
+}
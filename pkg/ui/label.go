@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Label is text, which can be styled with markup, see engine.RichText
+type Label struct {
+	Base
+	// Align lines the text up in the label, and its lines up with each other
+	Align engine.Align
+	// Color is what the text is drawn in, the theme's text color is used when it's nil
+	Color *sdl.Color
+	// Effects are called every update, to animate the label
+	Effects []func(*Label)
+	// Font is what the text is drawn in, the theme's font is used when it's nil
+	Font engine.Font
+	Text string
+	// Wrap wraps the text to the width of the label
+	Wrap bool
+
+	rich engine.RichText
+}
+
+// NewLabel returns a label of text
+func NewLabel(text string) *Label {
+	return &Label{Text: text}
+}
+
+// Size is how big the text is, wrapped to W when the label wraps
+func (l *Label) Size() (int32, int32) {
+	width := int32(0)
+	if l.Wrap {
+		width = l.W
+		if width <= 0 {
+			width = l.bounds.W
+		}
+	}
+	return l.layOut(width)
+}
+
+// Update calls the label's effects
+func (l *Label) Update(dt float64) {
+	for _, effect := range l.Effects {
+		effect(l)
+	}
+}
+
+// Draw draws the text lined up in the label
+func (l *Label) Draw(renderer engine.Renderer) {
+	width := int32(0)
+	if l.Wrap {
+		width = l.bounds.W
+	}
+	w, _ := l.layOut(width)
+	x := l.bounds.X
+	if width == 0 {
+		switch l.Align {
+		case engine.AlignCenter:
+			x += (l.bounds.W - w) / 2
+		case engine.AlignRight:
+			x += l.bounds.W - w
+		}
+	}
+	color := l.textColor()
+	if l.Color != nil {
+		color = *l.Color
+	}
+	drawText(renderer, &l.rich, x, l.bounds.Y, color)
+}
+
+// Free lets go of the label's laid out text
+func (l *Label) Free() {
+	l.rich.Free()
+}
+
+// layOut sets the text up to be drawn wrapped to width, and returns how big it is
+func (l *Label) layOut(width int32) (int32, int32) {
+	font := l.Font
+	if font == nil {
+		font = l.CurrentTheme().Font
+	}
+	l.rich.Align = l.Align
+	return layOutText(&l.rich, font, l.Text, width)
+}
@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Panel is a box of widgets, each placed inside its padding by their anchors. It's drawn with the theme's Panel image,
+//   or filled with its Background color, unless it's Transparent
+type Panel struct {
+	Base
+	// Background is drawn behind the panel instead of the theme's Panel
+	Background  *engine.NineSlice
+	Transparent bool
+	Widgets     []Widget
+}
+
+// NewPanel returns a panel of widgets
+func NewPanel(widgets ...Widget) *Panel {
+	return &Panel{Widgets: widgets}
+}
+
+// Children returns the panel's widgets
+func (p *Panel) Children() []Widget {
+	return p.Widgets
+}
+
+// Size fits the biggest of the panel's widgets inside its padding
+func (p *Panel) Size() (int32, int32) {
+	var w, h int32
+	for _, child := range p.Widgets {
+		b := child.base()
+		if b.Hidden {
+			continue
+		}
+		cw, ch := size(child, sdl.Rect{})
+		if cw+b.X > w {
+			w = cw + b.X
+		}
+		if ch+b.Y > h {
+			h = ch + b.Y
+		}
+	}
+	padding := p.CurrentTheme().Padding
+	return w + padding*2, h + padding*2
+}
+
+// Layout places the panel's widgets inside its padding
+func (p *Panel) Layout(bounds sdl.Rect) {
+	p.bounds = bounds
+	padding := p.CurrentTheme().Padding
+	inner := sdl.Rect{X: bounds.X + padding, Y: bounds.Y + padding, W: bounds.W - padding*2, H: bounds.H - padding*2}
+	for _, child := range p.Widgets {
+		if !child.base().Hidden {
+			child.Layout(place(child, inner))
+		}
+	}
+}
+
+// Draw draws the panel's background, then its widgets
+func (p *Panel) Draw(renderer engine.Renderer) {
+	if !p.Transparent {
+		theme := p.CurrentTheme()
+		background := p.Background
+		if background == nil {
+			background = theme.Panel
+		}
+		theme.fill(renderer, p.bounds, theme.Background, background)
+	}
+	for _, child := range p.Widgets {
+		if !child.base().Hidden {
+			child.Draw(renderer)
+		}
+	}
+}
+
+// Update updates the panel's widgets
+func (p *Panel) Update(dt float64) {
+	for _, child := range p.Widgets {
+		if !child.base().Hidden {
+			child.Update(dt)
+		}
+	}
+}
+
+// Stack lines its widgets up one after another, down or across. Widgets anchored to Fill are stretched
+//   across the stack, the rest are lined up with each other by Align
+type Stack struct {
+	Base
+	// Align lines widgets up on the left, center or right of a stack going down, or the top, center or bottom of one going across
+	Align      engine.Align
+	Horizontal bool
+	// Spacing is the room left between widgets, the theme's Spacing is used when it's 0
+	Spacing int32
+	Widgets []Widget
+}
+
+// NewStack returns a stack of widgets going down
+func NewStack(widgets ...Widget) *Stack {
+	return &Stack{Widgets: widgets}
+}
+
+// NewRow returns a stack of widgets going across
+func NewRow(widgets ...Widget) *Stack {
+	return &Stack{Horizontal: true, Widgets: widgets}
+}
+
+// Children returns the stack's widgets
+func (s *Stack) Children() []Widget {
+	return s.Widgets
+}
+
+// Size is how long all of the stack's widgets are together, and how wide the widest is
+func (s *Stack) Size() (int32, int32) {
+	var along, across int32
+	count := 0
+	for _, child := range s.Widgets {
+		if child.base().Hidden {
+			continue
+		}
+		w, h := size(child, sdl.Rect{})
+		if s.Horizontal {
+			w, h = h, w
+		}
+		along += h
+		if w > across {
+			across = w
+		}
+		count++
+	}
+	if count > 1 {
+		along += s.spacing() * int32(count-1)
+	}
+	if s.Horizontal {
+		return along, across
+	}
+	return across, along
+}
+
+// Layout lines the stack's widgets up from its top or left
+func (s *Stack) Layout(bounds sdl.Rect) {
+	s.bounds = bounds
+	pos := bounds.Y
+	if s.Horizontal {
+		pos = bounds.X
+	}
+	for _, child := range s.Widgets {
+		b := child.base()
+		if b.Hidden {
+			continue
+		}
+		w, h := size(child, bounds)
+		var r sdl.Rect
+		if s.Horizontal {
+			r = sdl.Rect{X: pos, Y: bounds.Y + s.offset(bounds.H, h), W: w, H: h}
+			if b.Anchor == Fill {
+				r.Y, r.H = bounds.Y, bounds.H
+			}
+			pos += w + s.spacing()
+		} else {
+			r = sdl.Rect{X: bounds.X + s.offset(bounds.W, w), Y: pos, W: w, H: h}
+			if b.Anchor == Fill {
+				r.X, r.W = bounds.X, bounds.W
+			}
+			pos += h + s.spacing()
+		}
+		r.X += b.X
+		r.Y += b.Y
+		child.Layout(r)
+	}
+}
+
+// Draw draws the stack's widgets
+func (s *Stack) Draw(renderer engine.Renderer) {
+	for _, child := range s.Widgets {
+		if !child.base().Hidden {
+			child.Draw(renderer)
+		}
+	}
+}
+
+// Update updates the stack's widgets
+func (s *Stack) Update(dt float64) {
+	for _, child := range s.Widgets {
+		if !child.base().Hidden {
+			child.Update(dt)
+		}
+	}
+}
+
+// offset is how far across the stack a widget size wide is moved to line it up by Align
+func (s *Stack) offset(space, size int32) int32 {
+	switch s.Align {
+	case engine.AlignCenter:
+		return (space - size) / 2
+	case engine.AlignRight:
+		return space - size
+	}
+	return 0
+}
+
+// spacing is how much room is left between widgets
+func (s *Stack) spacing() int32 {
+	if s.Spacing != 0 {
+		return s.Spacing
+	}
+	return s.CurrentTheme().Spacing
+}
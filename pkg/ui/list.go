@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// List is a column of items to pick from. Up and down move the selection while it's focused, scrolling it
+//   if there are more items than Rows, and confirm or clicking an item picks it
+type List struct {
+	Base
+	Items []string
+	// OnSelect is called with the item that's picked, and where it is in Items
+	OnSelect func(index int, item string)
+	// Rows is how many items are shown at once, all of them are when it's 0
+	Rows     int
+	Selected int
+
+	rows   []engine.RichText
+	scroll int
+}
+
+// NewList returns a list of items that calls onSelect when one is picked
+func NewList(items []string, onSelect func(int, string)) *List {
+	return &List{Items: items, OnSelect: onSelect}
+}
+
+// CanFocus reports whether the list has anything to pick
+func (l *List) CanFocus() bool {
+	return l.usable() && len(l.Items) > 0
+}
+
+// Activate picks the selected item
+func (l *List) Activate() {
+	if !l.CanFocus() || l.OnSelect == nil {
+		return
+	}
+	l.clamp()
+	l.OnSelect(l.Selected, l.Items[l.Selected])
+}
+
+// Navigate moves the selection up and down, focus moves on from the first and last items
+func (l *List) Navigate(dx, dy int) bool {
+	next := l.Selected + dy
+	if dy == 0 || next < 0 || next >= len(l.Items) {
+		return false
+	}
+	l.Selected = next
+	l.clamp()
+	return true
+}
+
+// Click selects the item that was clicked
+func (l *List) Click(x, y int32) {
+	height := l.rowHeight()
+	if height <= 0 || y < 0 {
+		return
+	}
+	if row := l.scroll + int(y/height); row < len(l.Items) && row < l.scroll+l.visible() {
+		l.Selected = row
+	}
+}
+
+// HandleEvent scrolls the list with the mouse wheel while it's focused
+func (l *List) HandleEvent(event sdl.Event) {
+	if wheel, ok := event.(*sdl.MouseWheelEvent); ok {
+		l.scroll -= int(wheel.Y)
+		if max := len(l.Items) - l.visible(); l.scroll > max {
+			l.scroll = max
+		}
+		if l.scroll < 0 {
+			l.scroll = 0
+		}
+	}
+}
+
+// Size fits the widest item, and Rows of them
+func (l *List) Size() (int32, int32) {
+	theme := l.CurrentTheme()
+	l.layOut()
+	var w int32
+	for i := range l.rows {
+		if rw, _ := l.rows[i].Size(); rw > w {
+			w = rw
+		}
+	}
+	return w + theme.Padding*2, l.rowHeight() * int32(l.visible())
+}
+
+// Draw draws the items that are scrolled into view, with the selected one highlighted
+func (l *List) Draw(renderer engine.Renderer) {
+	theme := l.CurrentTheme()
+	theme.control(renderer, l.bounds, false)
+	l.layOut()
+	l.clamp()
+	height := l.rowHeight()
+	for i := l.scroll; i < len(l.Items) && i < l.scroll+l.visible(); i++ {
+		row := sdl.Rect{X: l.bounds.X, Y: l.bounds.Y + int32(i-l.scroll)*height, W: l.bounds.W, H: height}
+		color := theme.Text
+		switch {
+		case l.Disabled:
+			color = theme.Disabled
+		case i == l.Selected && l.focused:
+			theme.fill(renderer, row, theme.Focus, theme.ButtonFocus)
+			color = theme.FocusText
+		case i == l.Selected:
+			theme.fill(renderer, row, theme.Accent, nil)
+		}
+		_, h := l.rows[i].Size()
+		drawText(renderer, &l.rows[i], row.X+theme.Padding, row.Y+(height-h)/2, color)
+	}
+}
+
+// Free lets go of the list's laid out text
+func (l *List) Free() {
+	l.rows = nil
+}
+
+// layOut sets up a row of text for each item
+func (l *List) layOut() {
+	if len(l.rows) != len(l.Items) {
+		l.rows = make([]engine.RichText, len(l.Items))
+	}
+	font := l.CurrentTheme().Font
+	for i, item := range l.Items {
+		layOutText(&l.rows[i], font, escape(item), 0)
+	}
+}
+
+// clamp keeps the selection in the list, and scrolls the list to show it
+func (l *List) clamp() {
+	if l.Selected >= len(l.Items) {
+		l.Selected = len(l.Items) - 1
+	}
+	if l.Selected < 0 {
+		l.Selected = 0
+	}
+	if l.Selected < l.scroll {
+		l.scroll = l.Selected
+	}
+	if visible := l.visible(); l.Selected >= l.scroll+visible {
+		l.scroll = l.Selected - visible + 1
+	}
+}
+
+// visible is how many rows are shown at once
+func (l *List) visible() int {
+	if l.Rows > 0 && l.Rows < len(l.Items) {
+		return l.Rows
+	}
+	return len(l.Items)
+}
+
+// rowHeight is how high each item is
+func (l *List) rowHeight() int32 {
+	theme := l.CurrentTheme()
+	return theme.lineHeight() + theme.Padding/2
+}
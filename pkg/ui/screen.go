@@ -0,0 +1,281 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/ryanhartje/gogome/pkg/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Screen is the root of a user interface. It lays its widgets out in the area it's given, usually the window,
+//   and moves focus between them: the directions move it to the nearest widget that way,
+//   confirm activates the focused widget, and the mouse focuses and clicks whatever it's over.
+type Screen struct {
+	Base
+	// Input is the action map navigation is read from, input.Default is used when it's nil
+	Input   *input.Map
+	Widgets []Widget
+
+	focus, pressed Widget
+	// captured is set while the focused widget is taking all input, and for the tick after it stops
+	captured bool
+	// mouseUsed is set when a mouse button was pressed this tick, so it isn't read as confirm as well
+	mouseUsed bool
+}
+
+// NewScreen returns a screen of widgets drawn with theme
+func NewScreen(theme *Theme, widgets ...Widget) *Screen {
+	return &Screen{Base: Base{Anchor: Fill, Theme: theme}, Widgets: widgets}
+}
+
+// Size is 0, screens fill whatever area they're given
+func (s *Screen) Size() (int32, int32) {
+	return 0, 0
+}
+
+// Children returns the screen's widgets
+func (s *Screen) Children() []Widget {
+	return s.Widgets
+}
+
+// FocusedWidget returns the widget with focus, or nil if nothing has it
+func (s *Screen) FocusedWidget() Widget {
+	return s.focus
+}
+
+// Focus moves focus to w, nothing has it when w is nil
+func (s *Screen) Focus(w Widget) {
+	if w == s.focus {
+		return
+	}
+	if s.focus != nil {
+		s.focus.base().focused = false
+	}
+	s.focus = w
+	if w != nil {
+		w.base().focused = true
+	}
+}
+
+// Capturing reports whether the focused widget is taking all input, like a TextInput being typed in.
+//   It stays true for the tick after the widget stops, so the key that stopped it isn't read as an action too
+func (s *Screen) Capturing() bool {
+	return s.captured || s.focusCapturing()
+}
+
+// Layout places the screen's widgets in area by their anchors, and keeps focus on a widget that can take it
+func (s *Screen) Layout(area sdl.Rect) {
+	s.bounds = area
+	for _, w := range s.Widgets {
+		applyTheme(w, s.CurrentTheme())
+		w.Layout(place(w, area))
+	}
+	if s.focus == nil || !s.has(s.focus) || !focusable(s.focus) {
+		s.Focus(nil)
+		if all := s.focusables(); len(all) > 0 {
+			s.Focus(all[0])
+		}
+	}
+}
+
+// Draw draws the screen's widgets in order, so later ones are drawn over earlier ones
+func (s *Screen) Draw(renderer engine.Renderer) {
+	for _, w := range s.Widgets {
+		if !w.base().Hidden {
+			w.Draw(renderer)
+		}
+	}
+}
+
+// Update updates the screen's widgets, then moves focus or activates the focused widget for the actions pressed
+func (s *Screen) Update(dt float64) {
+	for _, w := range s.Widgets {
+		if !w.base().Hidden {
+			w.Update(dt)
+		}
+	}
+
+	mouseUsed := s.mouseUsed
+	s.mouseUsed = false
+	if s.focusCapturing() {
+		s.captured = true
+		return
+	}
+	if s.captured {
+		s.captured = false
+		return
+	}
+
+	actions := s.Input
+	if actions == nil {
+		actions = input.Default
+	}
+	switch {
+	case actions.Pressed(input.MoveUp):
+		s.navigate(0, -1)
+	case actions.Pressed(input.MoveDown):
+		s.navigate(0, 1)
+	case actions.Pressed(input.MoveLeft):
+		s.navigate(-1, 0)
+	case actions.Pressed(input.MoveRight):
+		s.navigate(1, 0)
+	case actions.Pressed(input.Confirm) && !mouseUsed:
+		if a, ok := s.focus.(Activator); ok && focusable(s.focus) {
+			a.Activate()
+		}
+	}
+}
+
+// HandleEvent hovers, focuses and clicks widgets with the mouse, and passes other events to the focused widget
+func (s *Screen) HandleEvent(event sdl.Event) {
+	switch e := event.(type) {
+	case *sdl.MouseMotionEvent:
+		s.hover(e.X, e.Y)
+		if s.pressed != nil && e.State&sdl.ButtonLMask() != 0 {
+			s.click(s.pressed, e.X, e.Y)
+		}
+		return
+	case *sdl.MouseButtonEvent:
+		if e.Button != sdl.BUTTON_LEFT {
+			return
+		}
+		s.mouseUsed = true
+		hit := s.hit(e.X, e.Y)
+		if e.State == sdl.PRESSED {
+			s.pressed = hit
+			if hit != nil {
+				s.Focus(hit)
+				s.click(hit, e.X, e.Y)
+			}
+			return
+		}
+		if hit != nil && hit == s.pressed {
+			if a, ok := hit.(Activator); ok {
+				a.Activate()
+			}
+		}
+		s.pressed = nil
+		return
+	}
+	if s.focus != nil {
+		s.focus.HandleEvent(event)
+	}
+}
+
+// Free lets go of the laid out text of the screen's widgets
+func (s *Screen) Free() {
+	for _, w := range s.Widgets {
+		walk(w, func(w Widget) {
+			if f, ok := w.(freer); ok {
+				f.Free()
+			}
+		})
+	}
+}
+
+// focusCapturing reports whether the focused widget is taking all input now
+func (s *Screen) focusCapturing() bool {
+	c, ok := s.focus.(Capturer)
+	return ok && c.Capturing()
+}
+
+// click tells w where it was clicked, relative to its top left
+func (s *Screen) click(w Widget, x, y int32) {
+	if c, ok := w.(Clicker); ok {
+		bounds := w.base().bounds
+		c.Click(x-bounds.X, y-bounds.Y)
+	}
+}
+
+// hover marks the widgets under the mouse as hovered
+func (s *Screen) hover(x, y int32) {
+	for _, w := range s.Widgets {
+		walk(w, func(w Widget) {
+			w.base().hovered = inside(w.base().bounds, x, y)
+		})
+	}
+}
+
+// hit returns the focusable widget under x, y, the last drawn if they overlap
+func (s *Screen) hit(x, y int32) Widget {
+	var hit Widget
+	for _, w := range s.focusables() {
+		if inside(w.base().bounds, x, y) {
+			hit = w
+		}
+	}
+	return hit
+}
+
+// has reports whether w is on the screen and not hidden
+func (s *Screen) has(w Widget) bool {
+	found := false
+	for _, child := range s.Widgets {
+		walk(child, func(c Widget) {
+			if c == w {
+				found = true
+			}
+		})
+	}
+	return found
+}
+
+// focusables returns every widget on the screen that can take focus, in the order they're drawn
+func (s *Screen) focusables() []Widget {
+	var all []Widget
+	for _, child := range s.Widgets {
+		walk(child, func(w Widget) {
+			if focusable(w) {
+				all = append(all, w)
+			}
+		})
+	}
+	return all
+}
+
+// navigate moves focus to the nearest widget in direction dx, dy, unless the focused widget uses the direction itself.
+//   Focus wraps around to the first or last widget when there's nothing that way.
+func (s *Screen) navigate(dx, dy int) {
+	if n, ok := s.focus.(Navigator); ok && n.Navigate(dx, dy) {
+		return
+	}
+	all := s.focusables()
+	if len(all) == 0 {
+		return
+	}
+	if s.focus == nil {
+		s.Focus(all[0])
+		return
+	}
+
+	from := s.focus.base().bounds
+	fromX, fromY := from.X+from.W/2, from.Y+from.H/2
+	var best Widget
+	var bestScore int64
+	for _, w := range all {
+		if w == s.focus {
+			continue
+		}
+		r := w.base().bounds
+		along := int64(r.X+r.W/2-fromX)*int64(dx) + int64(r.Y+r.H/2-fromY)*int64(dy)
+		if along <= 0 {
+			continue
+		}
+		across := int64(r.X+r.W/2-fromX)*int64(dy) + int64(r.Y+r.H/2-fromY)*int64(dx)
+		if across < 0 {
+			across = -across
+		}
+		// Widgets straight ahead are closer than ones off to the side
+		score := along + across*2
+		if best == nil || score < bestScore {
+			best, bestScore = w, score
+		}
+	}
+	if best == nil {
+		if dx > 0 || dy > 0 {
+			best = all[0]
+		} else {
+			best = all[len(all)-1]
+		}
+	}
+	s.Focus(best)
+}
@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/ryanhartje/gogome/pkg/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// testScreen is a screen of buttons on a grid 100 pixels apart, each 80 by 40, that record their clicks
+type testScreen struct {
+	*Screen
+	buttons map[string]*Button
+	clicked []string
+}
+
+// newTestScreen lays out a screen of buttons, named and placed at the grid cells given
+func newTestScreen(cells map[string][2]int32) *testScreen {
+	ts := &testScreen{buttons: map[string]*Button{}}
+	var widgets []Widget
+	// Add buttons in name order so which comes first doesn't depend on map order
+	var names []string
+	for name := range cells {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		name := name
+		b := NewButton(name, func() { ts.clicked = append(ts.clicked, name) })
+		b.W, b.H = 80, 40
+		b.X, b.Y = cells[name][0]*100, cells[name][1]*100
+		ts.buttons[name] = b
+		widgets = append(widgets, b)
+	}
+	ts.Screen = NewScreen(DefaultTheme(nil), widgets...)
+	ts.Input = input.NewMap()
+	ts.Layout(sdl.Rect{W: 400, H: 300})
+	return ts
+}
+
+// tick presses action for a tick of the screen, then lets go of it for another
+func (ts *testScreen) tick(action string) {
+	ts.Input.Apply(input.Snapshot{action: 1})
+	ts.Update(1.0 / 60)
+	ts.Input.Apply(input.Snapshot{})
+	ts.Update(1.0 / 60)
+}
+
+// focused returns the name of the focused button
+func (ts *testScreen) focused() string {
+	for name, b := range ts.buttons {
+		if ts.FocusedWidget() == b {
+			return name
+		}
+	}
+	return ""
+}
+
+func (ts *testScreen) mouse(x, y int32, state uint8) {
+	ts.HandleEvent(&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Button: sdl.BUTTON_LEFT, State: state, X: x, Y: y})
+}
+
+func TestScreenNavigate(t *testing.T) {
+	ts := newTestScreen(map[string][2]int32{
+		"a": {0, 0}, "b": {1, 0}, "e": {2, 0},
+		"c": {0, 1}, "d": {1, 1},
+	})
+	ts.buttons["e"].Disabled = true
+	ts.Layout(sdl.Rect{W: 400, H: 300})
+
+	if got := ts.focused(); got != "a" {
+		t.Fatalf("%q is focused after Layout, want the first button", got)
+	}
+
+	tests := []struct {
+		from   string
+		action string
+		want   string
+	}{
+		{"a", input.MoveRight, "b"},
+		{"a", input.MoveDown, "c"},
+		{"c", input.MoveUp, "a"},
+		{"c", input.MoveRight, "d"},
+		{"d", input.MoveLeft, "c"},
+		// Straight ahead is nearer than a widget the same distance away but off to the side
+		{"b", input.MoveDown, "d"},
+		{"c", input.MoveRight, "d"},
+		// Disabled widgets are skipped, and with nothing that way focus wraps to the first or last widget
+		{"b", input.MoveRight, "a"},
+		{"d", input.MoveDown, "a"},
+		{"a", input.MoveUp, "d"},
+		{"c", input.MoveLeft, "d"},
+	}
+	for _, test := range tests {
+		ts.Focus(ts.buttons[test.from])
+		ts.tick(test.action)
+		if got := ts.focused(); got != test.want {
+			t.Errorf("%s from %s focused %s, want %s", test.action, test.from, got, test.want)
+		}
+		if !ts.buttons[test.want].Focused() || ts.buttons[test.from].Focused() {
+			t.Errorf("%s from %s left the buttons' focused flags wrong", test.action, test.from)
+		}
+	}
+
+	// Holding a direction only moves focus once
+	ts.Focus(ts.buttons["a"])
+	ts.Input.Apply(input.Snapshot{input.MoveRight: 1})
+	ts.Update(1.0 / 60)
+	ts.Input.Apply(input.Snapshot{input.MoveRight: 1})
+	ts.Update(1.0 / 60)
+	if got := ts.focused(); got != "b" {
+		t.Errorf("holding right from a focused %s, want b", got)
+	}
+
+	// Hiding the focused widget moves focus on at the next layout
+	ts.buttons["b"].Hidden = true
+	ts.Layout(sdl.Rect{W: 400, H: 300})
+	if got := ts.focused(); got != "a" {
+		t.Errorf("%s is focused after hiding b, want a", got)
+	}
+}
+
+func TestScreenConfirm(t *testing.T) {
+	ts := newTestScreen(map[string][2]int32{"a": {0, 0}, "b": {1, 0}})
+	ts.tick(input.Confirm)
+	ts.tick(input.MoveRight)
+	ts.tick(input.Confirm)
+	if want := []string{"a", "b"}; !reflect.DeepEqual(ts.clicked, want) {
+		t.Errorf("confirm clicked %v, want %v", ts.clicked, want)
+	}
+
+	// A disabled widget that kept focus isn't activated
+	ts.clicked = nil
+	ts.buttons["b"].Disabled = true
+	ts.tick(input.Confirm)
+	if len(ts.clicked) != 0 {
+		t.Errorf("confirm clicked %v on a disabled button", ts.clicked)
+	}
+}
+
+func TestScreenNavigator(t *testing.T) {
+	slider := NewSlider("volume", 0, 10, 5, nil)
+	slider.Step = 1
+	slider.W, slider.H = 80, 40
+	after := NewButton("after", nil)
+	after.W, after.H, after.Y = 80, 40, 100
+	s := NewScreen(DefaultTheme(nil), slider, after)
+	s.Input = input.NewMap()
+	s.Layout(sdl.Rect{W: 400, H: 300})
+	ts := &testScreen{Screen: s}
+
+	// Left and right move the slider instead of focus, up and down still move focus
+	ts.tick(input.MoveRight)
+	if slider.Value != 6 || s.FocusedWidget() != slider {
+		t.Errorf("right moved the slider to %v with %v focused, want 6 and the slider", slider.Value, s.FocusedWidget())
+	}
+	ts.tick(input.MoveDown)
+	if s.FocusedWidget() != after {
+		t.Errorf("down from the slider focused %v, want the button", s.FocusedWidget())
+	}
+}
+
+func TestScreenCapture(t *testing.T) {
+	text := NewTextInput("name", nil)
+	text.W, text.H = 200, 40
+	below := NewButton("below", nil)
+	below.W, below.H, below.Y = 80, 40, 100
+	s := NewScreen(DefaultTheme(nil), text, below)
+	s.Input = input.NewMap()
+	s.Layout(sdl.Rect{W: 400, H: 300})
+	ts := &testScreen{Screen: s}
+
+	ts.tick(input.Confirm)
+	if !text.Capturing() || !s.Capturing() {
+		t.Fatal("confirm on a text input didn't start typing in it")
+	}
+
+	// While it's typed in, the input gets events and actions don't move focus
+	s.HandleEvent(&sdl.TextInputEvent{Type: sdl.TEXTINPUT, Text: [32]byte{'h', 'i'}})
+	ts.tick(input.MoveDown)
+	if s.FocusedWidget() != text || text.Text != "hi" {
+		t.Fatalf("typing %q with %v focused, want hi and the text input", text.Text, s.FocusedWidget())
+	}
+
+	// The return that stops typing is also confirm, it mustn't start typing again on the same tick
+	s.HandleEvent(&sdl.KeyboardEvent{Type: sdl.KEYDOWN, State: sdl.PRESSED, Keysym: sdl.Keysym{Sym: sdl.K_RETURN}})
+	if text.Capturing() {
+		t.Fatal("return didn't stop typing")
+	}
+	if !s.Capturing() {
+		t.Error("the screen stopped capturing on the tick typing stopped")
+	}
+	s.Input.Apply(input.Snapshot{input.Confirm: 1})
+	s.Update(1.0 / 60)
+	if text.Capturing() {
+		t.Error("the confirm that stopped typing started it again")
+	}
+	if s.Capturing() {
+		t.Error("the screen is still capturing the tick after typing stopped")
+	}
+
+	s.Input.Apply(input.Snapshot{})
+	s.Update(1.0 / 60)
+	ts.tick(input.MoveDown)
+	if s.FocusedWidget() != below {
+		t.Errorf("down focused %v once typing stopped, want the button", s.FocusedWidget())
+	}
+
+	// Moving focus away with the mouse stops typing too
+	s.Focus(text)
+	ts.tick(input.Confirm)
+	ts.mouse(10, 110, sdl.PRESSED)
+	ts.Update(1.0 / 60)
+	if text.Capturing() {
+		t.Error("the text input is still being typed in after clicking away from it")
+	}
+}
+
+func TestScreenMouse(t *testing.T) {
+	ts := newTestScreen(map[string][2]int32{"a": {0, 0}, "b": {1, 0}, "c": {0, 1}})
+	// over overlaps the right half of a, and is later so it's on top
+	over := NewButton("over", func() { ts.clicked = append(ts.clicked, "over") })
+	over.W, over.H, over.X, over.Y = 40, 40, 40, 0
+	ts.Widgets = append(ts.Widgets, over)
+	ts.buttons["over"] = over
+	ts.Layout(sdl.Rect{W: 400, H: 300})
+
+	tests := []struct {
+		name         string
+		downX, downY int32
+		upX, upY     int32
+		focused      string
+		clicked      []string
+	}{
+		{"click b", 110, 10, 150, 30, "b", []string{"b"}},
+		{"click c's far corner", 79, 139, 79, 139, "c", []string{"c"}},
+		{"click on top of a", 50, 10, 50, 10, "over", []string{"over"}},
+		{"click a", 10, 10, 10, 10, "a", []string{"a"}},
+		{"released over another button", 10, 110, 110, 10, "c", nil},
+		{"dragged off and back", 110, 10, 110, 10, "b", []string{"b"}},
+		{"just past c", 80, 140, 80, 140, "b", nil},
+		{"gap between buttons", 90, 50, 90, 50, "b", nil},
+	}
+	for _, test := range tests {
+		ts.clicked = nil
+		ts.mouse(test.downX, test.downY, sdl.PRESSED)
+		ts.HandleEvent(&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, State: sdl.ButtonLMask(), X: 300, Y: 300})
+		ts.mouse(test.upX, test.upY, sdl.RELEASED)
+		if got := ts.focused(); got != test.focused {
+			t.Errorf("%s: focused %s, want %s", test.name, got, test.focused)
+		}
+		if !reflect.DeepEqual(ts.clicked, test.clicked) {
+			t.Errorf("%s: clicked %v, want %v", test.name, ts.clicked, test.clicked)
+		}
+	}
+
+	// Other buttons don't click
+	ts.clicked = nil
+	ts.HandleEvent(&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Button: sdl.BUTTON_RIGHT, State: sdl.PRESSED, X: 10, Y: 10})
+	ts.HandleEvent(&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONUP, Button: sdl.BUTTON_RIGHT, State: sdl.RELEASED, X: 10, Y: 10})
+	if len(ts.clicked) != 0 || ts.focused() != "b" {
+		t.Errorf("a right click clicked %v and focused %s", ts.clicked, ts.focused())
+	}
+
+	// A click that's read as confirm too only activates once
+	ts.clicked = nil
+	ts.mouse(10, 10, sdl.PRESSED)
+	ts.mouse(10, 10, sdl.RELEASED)
+	ts.Input.Apply(input.Snapshot{input.Confirm: 1})
+	ts.Update(1.0 / 60)
+	if want := []string{"a"}; !reflect.DeepEqual(ts.clicked, want) {
+		t.Errorf("a click on the same tick as confirm clicked %v, want %v", ts.clicked, want)
+	}
+
+	// Hovering marks what's under the mouse, including widgets underneath others
+	ts.HandleEvent(&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 50, Y: 10})
+	for name, want := range map[string]bool{"a": true, "over": true, "b": false, "c": false} {
+		if got := ts.buttons[name].Hovered(); got != want {
+			t.Errorf("%s hovered = %v with the mouse at 50, 10, want %v", name, got, want)
+		}
+	}
+}
+
+func TestScreenDraw(t *testing.T) {
+	ts := newTestScreen(map[string][2]int32{"a": {0, 0}, "b": {1, 0}})
+	theme := ts.CurrentTheme()
+	ts.buttons["b"].Hidden = true
+	ts.tick(input.MoveRight)
+
+	r := engine.NewSoftwareRenderer(400, 300)
+	ts.Draw(r)
+	var boxes []engine.DrawCall
+	for _, call := range r.Calls {
+		if call.Op == "RoundedBox" {
+			boxes = append(boxes, call)
+		}
+	}
+	// a is drawn focused, b hidden isn't drawn at all
+	want := []engine.DrawCall{{Op: "RoundedBox", Color: theme.Focus, Dst: sdl.Rect{W: 80, H: 40}}}
+	if !reflect.DeepEqual(boxes, want) {
+		t.Errorf("drew %v, want %v", boxes, want)
+	}
+
+	ts.buttons["b"].Hidden = false
+	ts.Layout(sdl.Rect{W: 400, H: 300})
+	ts.HandleEvent(&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 110, Y: 10})
+	r.Clear()
+	ts.Draw(r)
+	boxes = nil
+	for _, call := range r.Calls {
+		if call.Op == "RoundedBox" {
+			boxes = append(boxes, call)
+		}
+	}
+	// b is hovered, so it's highlighted like a is
+	want = []engine.DrawCall{
+		{Op: "RoundedBox", Color: theme.Focus, Dst: sdl.Rect{W: 80, H: 40}},
+		{Op: "RoundedBox", Color: theme.Focus, Dst: sdl.Rect{X: 100, W: 80, H: 40}},
+	}
+	if !reflect.DeepEqual(boxes, want) {
+		t.Errorf("drew %v, want %v", boxes, want)
+	}
+}
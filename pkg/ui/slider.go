@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"math"
+
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// sliderWidth is how wide a slider's track is when the slider isn't given a width
+const sliderWidth = 160
+
+// Slider picks a number between Min and Max. Left and right move it a Step while it's focused,
+//   and clicking or dragging along its track moves it to the mouse
+type Slider struct {
+	Base
+	Max, Min float64
+	// OnChange is called with the value after it changes
+	OnChange func(value float64)
+	// Step is how far left and right move the value, and what it's rounded to. A tenth of the range is used when it's 0
+	Step float64
+	// Text labels the slider, on the left of its track
+	Text  string
+	Value float64
+
+	rich  engine.RichText
+	track sdl.Rect
+}
+
+// NewSlider returns a slider labelled with text, from min to max
+func NewSlider(text string, min, max, value float64, onChange func(float64)) *Slider {
+	return &Slider{Max: max, Min: min, OnChange: onChange, Text: text, Value: value}
+}
+
+// CanFocus reports whether the slider can be used
+func (s *Slider) CanFocus() bool {
+	return s.usable()
+}
+
+// Navigate moves the value a step left or right, up and down move focus as usual
+func (s *Slider) Navigate(dx, dy int) bool {
+	if dx == 0 || !s.usable() {
+		return false
+	}
+	s.Set(s.Value + float64(dx)*s.step())
+	return true
+}
+
+// Click moves the value to where the track was clicked
+func (s *Slider) Click(x, y int32) {
+	if !s.usable() || s.track.W <= 0 {
+		return
+	}
+	x -= s.track.X - s.bounds.X
+	s.Set(s.Min + (s.Max-s.Min)*float64(x)/float64(s.track.W))
+}
+
+// Set moves the slider to value, rounded to a step and kept between Min and Max
+func (s *Slider) Set(value float64) {
+	step := s.step()
+	if step > 0 {
+		value = s.Min + math.Round((value-s.Min)/step)*step
+	}
+	value = math.Max(s.Min, math.Min(s.Max, value))
+	if value == s.Value {
+		return
+	}
+	s.Value = value
+	if s.OnChange != nil {
+		s.OnChange(value)
+	}
+}
+
+// Size fits the text, then a track beside it as high as a line of text
+func (s *Slider) Size() (int32, int32) {
+	theme := s.CurrentTheme()
+	w, h := layOutText(&s.rich, theme.Font, escape(s.Text), 0)
+	if w > 0 {
+		w += theme.Spacing
+	}
+	if line := theme.lineHeight(); h < line {
+		h = line
+	}
+	return w + sliderWidth, h
+}
+
+// Layout places the track in what's left of the slider after its text
+func (s *Slider) Layout(bounds sdl.Rect) {
+	s.bounds = bounds
+	theme := s.CurrentTheme()
+	w, _ := layOutText(&s.rich, theme.Font, escape(s.Text), 0)
+	if w > 0 {
+		w += theme.Spacing
+	}
+	height := theme.lineHeight() / 2
+	s.track = sdl.Rect{X: bounds.X + w, Y: bounds.Y + (bounds.H-height)/2, W: bounds.W - w, H: height}
+}
+
+// Draw draws the text, and the track filled up to the value
+func (s *Slider) Draw(renderer engine.Renderer) {
+	theme := s.CurrentTheme()
+	_, h := layOutText(&s.rich, theme.Font, escape(s.Text), 0)
+	drawText(renderer, &s.rich, s.bounds.X, s.bounds.Y+(s.bounds.H-h)/2, s.textColor())
+
+	theme.control(renderer, s.track, s.focused || s.hovered && s.usable())
+	if s.Max > s.Min {
+		filled := s.track
+		filled.W = int32(float64(s.track.W) * (s.Value - s.Min) / (s.Max - s.Min))
+		theme.fill(renderer, filled, theme.Accent, nil)
+	}
+}
+
+// Free lets go of the slider's laid out text
+func (s *Slider) Free() {
+	s.rich.Free()
+}
+
+// step is how far the value moves a step
+func (s *Slider) step() float64 {
+	if s.Step > 0 {
+		return s.Step
+	}
+	return (s.Max - s.Min) / 10
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"unicode/utf8"
+
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// inputWidth is how wide a text input is when it isn't given a width
+const inputWidth = 200
+
+// TextInput is a box to type text into. Activating it starts typing, and return or enter stops,
+//   escape stops too but leaves OnSubmit uncalled. It takes all input while it's being typed in.
+type TextInput struct {
+	Base
+	// MaxLength is the most characters that can be typed, there's no limit when it's 0
+	MaxLength int
+	// OnChange is called with the text as it's typed, OnSubmit with the text when return or enter is pressed
+	OnChange, OnSubmit func(text string)
+	// Placeholder is shown in the theme's disabled color when there's no text
+	Placeholder string
+	Text        string
+
+	blink   float64
+	editing bool
+	rich    engine.RichText
+}
+
+// NewTextInput returns an empty text input showing placeholder
+func NewTextInput(placeholder string, onSubmit func(string)) *TextInput {
+	return &TextInput{OnSubmit: onSubmit, Placeholder: placeholder}
+}
+
+// CanFocus reports whether the input can be typed in
+func (t *TextInput) CanFocus() bool {
+	return t.usable()
+}
+
+// Capturing reports whether the input is being typed in
+func (t *TextInput) Capturing() bool {
+	return t.editing
+}
+
+// Activate starts typing in the input
+func (t *TextInput) Activate() {
+	if t.usable() && !t.editing {
+		t.editing = true
+		t.blink = 0
+		sdl.StartTextInput()
+	}
+}
+
+// HandleEvent adds what's typed to the text, and stops typing when return, enter or escape is pressed
+func (t *TextInput) HandleEvent(event sdl.Event) {
+	if !t.editing {
+		return
+	}
+	switch e := event.(type) {
+	case *sdl.TextInputEvent:
+		text := t.Text + e.GetText()
+		if t.MaxLength > 0 && utf8.RuneCountInString(text) > t.MaxLength {
+			return
+		}
+		t.change(text)
+	case *sdl.KeyboardEvent:
+		if e.State != sdl.PRESSED {
+			return
+		}
+		switch e.Keysym.Sym {
+		case sdl.K_BACKSPACE:
+			if _, size := utf8.DecodeLastRuneInString(t.Text); size > 0 {
+				t.change(t.Text[:len(t.Text)-size])
+			}
+		case sdl.K_RETURN, sdl.K_KP_ENTER:
+			t.stop()
+			if t.OnSubmit != nil {
+				t.OnSubmit(t.Text)
+			}
+		case sdl.K_ESCAPE:
+			t.stop()
+		}
+	}
+}
+
+// Update blinks the caret, and stops typing if the input has lost focus
+func (t *TextInput) Update(dt float64) {
+	t.blink += dt
+	if t.editing && (!t.focused || !t.usable()) {
+		t.stop()
+	}
+}
+
+// Size fits a line of text inside the theme's padding
+func (t *TextInput) Size() (int32, int32) {
+	theme := t.CurrentTheme()
+	return inputWidth, theme.lineHeight() + theme.Padding
+}
+
+// Draw draws the box, its text or placeholder, and a blinking caret while it's being typed in
+func (t *TextInput) Draw(renderer engine.Renderer) {
+	theme := t.CurrentTheme()
+	theme.control(renderer, t.bounds, t.focused || t.hovered && t.usable())
+	text, color := t.Text, t.textColor()
+	if text == "" && !t.editing {
+		text, color = t.Placeholder, theme.Disabled
+	}
+	w, _ := layOutText(&t.rich, theme.Font, escape(text), 0)
+	if text == "" {
+		w = 0
+	}
+	x, y := t.bounds.X+theme.Padding, t.bounds.Y+(t.bounds.H-theme.lineHeight())/2
+	drawText(renderer, &t.rich, x, y, color)
+	// The caret is shown for half of each second
+	if t.editing && int(t.blink*2)%2 == 0 {
+		theme.fill(renderer, sdl.Rect{X: x + w, Y: y, W: 2, H: theme.lineHeight()}, theme.FocusText, nil)
+	}
+}
+
+// Free lets go of the input's laid out text
+func (t *TextInput) Free() {
+	t.rich.Free()
+}
+
+// change sets the text and calls OnChange
+func (t *TextInput) change(text string) {
+	t.Text = text
+	t.blink = 0
+	if t.OnChange != nil {
+		t.OnChange(text)
+	}
+}
+
+// stop stops typing in the input
+func (t *TextInput) stop() {
+	t.editing = false
+	sdl.StopTextInput()
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// defaultTheme is what widgets that aren't on a Screen with a theme are drawn with
+var defaultTheme = DefaultTheme(nil)
+
+// Theme is how widgets are drawn: the font and colors of their text, and the colors or images behind them
+type Theme struct {
+	// Accent is the color of what's been chosen, like a checkbox's check, a slider's fill or a list's selection
+	Accent sdl.Color
+	// Background is the color panels are filled with, unless there's a Panel image
+	Background sdl.Color
+	// Button and ButtonFocus are drawn behind buttons and focused buttons, the Control and Focus colors are used when they're nil
+	Button, ButtonFocus *engine.NineSlice
+	// Control is the color of buttons, checkboxes, sliders, lists and text inputs, Focus the color of the focused one
+	Control, Focus sdl.Color
+	// Disabled is the color of disabled widgets' text, FocusText the color of the focused widget's text
+	Disabled, FocusText sdl.Color
+	// Font is what text is drawn in
+	Font engine.Font
+	// Padding is the room left around what's in panels and controls
+	Padding int32
+	// Panel is drawn behind panels, the Background color is used when it's nil
+	Panel *engine.NineSlice
+	// Radius rounds the corners of the boxes drawn in colors
+	Radius int32
+	// Spacing is the room left between the widgets of a Stack, and a control and its label
+	Spacing int32
+	// Text is the color of text
+	Text sdl.Color
+}
+
+// DefaultTheme returns a plain theme of dark boxes and white text, drawn in font
+func DefaultTheme(font engine.Font) *Theme {
+	return &Theme{
+		Accent:     sdl.Color{R: 90, G: 170, B: 255, A: 255},
+		Background: sdl.Color{R: 20, G: 20, B: 30, A: 220},
+		Control:    sdl.Color{R: 60, G: 60, B: 80, A: 255},
+		Disabled:   sdl.Color{R: 120, G: 120, B: 120, A: 255},
+		Focus:      sdl.Color{R: 100, G: 100, B: 140, A: 255},
+		FocusText:  sdl.Color{R: 255, G: 230, B: 0, A: 255},
+		Font:       font,
+		Padding:    8,
+		Radius:     4,
+		Spacing:    8,
+		Text:       sdl.Color{R: 255, G: 255, B: 255, A: 255},
+	}
+}
+
+// lineHeight is how high a line of text in the theme's font is
+func (t *Theme) lineHeight() int32 {
+	if t.Font == nil {
+		return 0
+	}
+	return t.Font.Ascent() + t.Font.Descent()
+}
+
+// fill draws image over r, or a box in color when there isn't one
+func (t *Theme) fill(renderer engine.Renderer, r sdl.Rect, color sdl.Color, image *engine.NineSlice) {
+	if image != nil {
+		image.Draw(renderer, r)
+		return
+	}
+	if color.A == 0 || r.W <= 0 || r.H <= 0 {
+		return
+	}
+	radius := t.Radius
+	if radius > r.W/2 {
+		radius = r.W / 2
+	}
+	if radius > r.H/2 {
+		radius = r.H / 2
+	}
+	if radius > 0 {
+		renderer.RoundedBox(r.X, r.Y, r.X+r.W-1, r.Y+r.H-1, radius, color)
+		return
+	}
+	renderer.Box(r.X, r.Y, r.X+r.W-1, r.Y+r.H-1, color)
+}
+
+// control draws the box behind a control, highlighted when it's focused
+func (t *Theme) control(renderer engine.Renderer, r sdl.Rect, focused bool) {
+	if focused {
+		t.fill(renderer, r, t.Focus, t.ButtonFocus)
+		return
+	}
+	t.fill(renderer, r, t.Control, t.Button)
+}
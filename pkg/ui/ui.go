@@ -0,0 +1,259 @@
+// Package ui is a retained mode toolkit for menus and other user interfaces drawn over a game.
+//   Widgets are arranged in a tree of Panels and Stacks on a Screen, which lays them out in the window,
+//   moves focus between them with the keyboard, gamepad or mouse, and draws them with a Theme.
+//   A Screen is an engine.Widget, so menus are made of them, eg:
+//
+//   menu := &engine.Menu{Widgets: []engine.Widget{ui.NewScreen(theme, panel)}, WinW: 800, WinH: 600}
+package ui
+
+import (
+	"strings"
+
+	"github.com/ryanhartje/gogome/pkg/engine"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Widget is a part of a user interface. Widgets embed Base for the fields and methods they all share
+type Widget interface {
+	engine.Widget
+	// Size is how big the widget would like to be to fit what's in it
+	Size() (int32, int32)
+	base() *Base
+}
+
+// Focusable widgets can be focused, to be used with the keyboard or a gamepad
+type Focusable interface {
+	CanFocus() bool
+}
+
+// Activator widgets do something when confirm is pressed while they're focused, or when they're clicked
+type Activator interface {
+	Activate()
+}
+
+// Navigator widgets use the directions pressed while they're focused, eg: a slider moves left and right.
+//   Navigate returns false to move focus to the next widget that way instead.
+type Navigator interface {
+	Navigate(dx, dy int) bool
+}
+
+// Clicker widgets are told where they're clicked, and dragged, relative to their top left
+type Clicker interface {
+	Click(x, y int32)
+}
+
+// Capturer widgets take all input while they're Capturing, eg: a TextInput being typed in
+type Capturer interface {
+	Capturing() bool
+}
+
+// Container widgets hold other widgets
+type Container interface {
+	Children() []Widget
+}
+
+// freer is a widget holding on to laid out text it can let go of while it isn't shown
+type freer interface {
+	Free()
+}
+
+// Anchor is where a widget is placed in the area it's laid out in
+type Anchor int
+
+// Anchors go across then down, so TopLeft is 0 and BottomRight is 8
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+	// Fill stretches the widget over the whole area
+	Fill
+)
+
+// Base is embedded in every widget, for where it's placed and whether it's focused
+type Base struct {
+	// Anchor is where the widget is placed in a Panel or on a Screen. Stacks line their widgets up instead
+	Anchor Anchor
+	// Disabled widgets are drawn faded, and can't be focused or used
+	Disabled bool
+	// Hidden widgets aren't drawn, focused or given any room
+	Hidden bool
+	// RelW and RelH size the widget as a fraction of the area it's laid out in, eg: 0.5 for half of it.
+	//   They override W and H when they're set
+	RelW, RelH float64
+	// Theme is how the widget and anything in it is drawn, the theme it's in is used when it's nil
+	Theme *Theme
+	// W and H are how big the widget is, the Size of what's in it is used when they're 0
+	W, H int32
+	// X and Y move the widget from where its anchor puts it
+	X, Y int32
+
+	bounds  sdl.Rect
+	focused bool
+	hovered bool
+	// theme is the theme the widget is in
+	theme *Theme
+}
+
+// base lets the package get at the Base of any widget
+func (b *Base) base() *Base {
+	return b
+}
+
+// Bounds returns where the widget was last laid out
+func (b *Base) Bounds() sdl.Rect {
+	return b.bounds
+}
+
+// Focused reports whether the widget has focus
+func (b *Base) Focused() bool {
+	return b.focused
+}
+
+// Hovered reports whether the mouse is over the widget
+func (b *Base) Hovered() bool {
+	return b.hovered
+}
+
+// CurrentTheme returns the theme the widget is drawn with
+func (b *Base) CurrentTheme() *Theme {
+	if b.Theme != nil {
+		return b.Theme
+	}
+	if b.theme != nil {
+		return b.theme
+	}
+	return defaultTheme
+}
+
+// Layout sets where the widget is, containers lay their widgets out in it too
+func (b *Base) Layout(bounds sdl.Rect) {
+	b.bounds = bounds
+}
+
+// Update does nothing, for widgets that don't change by themselves
+func (b *Base) Update(dt float64) {}
+
+// HandleEvent does nothing, for widgets that don't need the events they're sent while they're focused
+func (b *Base) HandleEvent(event sdl.Event) {}
+
+// usable reports whether the widget can be focused and used
+func (b *Base) usable() bool {
+	return !b.Disabled && !b.Hidden
+}
+
+// textColor is the color of the widget's text in its theme, faded when it's disabled and highlighted when it's focused
+func (b *Base) textColor() sdl.Color {
+	theme := b.CurrentTheme()
+	switch {
+	case b.Disabled:
+		return theme.Disabled
+	case b.focused:
+		return theme.FocusText
+	}
+	return theme.Text
+}
+
+// place works out where a widget goes in area, by its size and anchor
+func place(w Widget, area sdl.Rect) sdl.Rect {
+	b := w.base()
+	if b.Anchor == Fill {
+		return sdl.Rect{X: area.X + b.X, Y: area.Y + b.Y, W: area.W, H: area.H}
+	}
+	width, height := size(w, area)
+	column, row := int32(b.Anchor%3), int32(b.Anchor/3)
+	return sdl.Rect{
+		X: area.X + (area.W-width)*column/2 + b.X,
+		Y: area.Y + (area.H-height)*row/2 + b.Y,
+		W: width,
+		H: height,
+	}
+}
+
+// size is how big a widget is when it's laid out in area
+func size(w Widget, area sdl.Rect) (int32, int32) {
+	b := w.base()
+	width, height := b.W, b.H
+	if b.RelW > 0 {
+		width = int32(b.RelW * float64(area.W))
+	}
+	if b.RelH > 0 {
+		height = int32(b.RelH * float64(area.H))
+	}
+	if width <= 0 || height <= 0 {
+		w, h := w.Size()
+		if width <= 0 {
+			width = w
+		}
+		if height <= 0 {
+			height = h
+		}
+	}
+	return width, height
+}
+
+// walk calls fn for w and everything in it, parents before their children. Hidden widgets are skipped
+func walk(w Widget, fn func(Widget)) {
+	if w.base().Hidden {
+		return
+	}
+	fn(w)
+	if c, ok := w.(Container); ok {
+		for _, child := range c.Children() {
+			walk(child, fn)
+		}
+	}
+}
+
+// applyTheme tells w and everything in it the theme they're in
+func applyTheme(w Widget, theme *Theme) {
+	b := w.base()
+	b.theme = theme
+	if b.Theme != nil {
+		theme = b.Theme
+	}
+	if c, ok := w.(Container); ok {
+		for _, child := range c.Children() {
+			applyTheme(child, theme)
+		}
+	}
+}
+
+// focusable reports whether w can take focus now
+func focusable(w Widget) bool {
+	f, ok := w.(Focusable)
+	return ok && f.CanFocus()
+}
+
+// escape makes text drawn as it is, without reading it as markup
+func escape(text string) string {
+	return strings.ReplaceAll(text, "[", "[[")
+}
+
+// inside reports whether x, y is in r
+func inside(r sdl.Rect, x, y int32) bool {
+	return x >= r.X && y >= r.Y && x < r.X+r.W && y < r.Y+r.H
+}
+
+// layOutText sets text up to be drawn in font, wrapped to width, and returns how big it is
+func layOutText(rich *engine.RichText, font engine.Font, text string, width int32) (int32, int32) {
+	rich.Font = font
+	rich.Text = text
+	rich.Width = width
+	if rich.Font == nil {
+		return 0, 0
+	}
+	return rich.Size()
+}
+
+// drawText draws rich text in color with its top left at x, y
+func drawText(renderer engine.Renderer, text *engine.RichText, x, y int32, color sdl.Color) {
+	text.X, text.Y = float64(x), float64(y)
+	text.Color = color
+	text.Draw(renderer, 0, 0)
+}
@@ -0,0 +1,249 @@
+// Package vfs is a virtual filesystem for game assets. Directories on disk, embedded files and zip archives are
+//   mounted into one fs.FS, with later mounts overlaying earlier ones so mods and patches can replace files.
+package vfs
+
+import (
+	"archive/zip"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS overlays mounted filesystems. A file is opened from the last mount that has it, and directories
+//   list the files of every mount they're in.
+type FS struct {
+	mounts []mount
+}
+
+// mount is a filesystem mounted at a directory of the FS, "" or "." being the root
+type mount struct {
+	fsys   fs.FS
+	prefix string
+}
+
+// New creates an FS with nothing mounted
+func New() *FS {
+	return &FS{}
+}
+
+// Mount mounts fsys at dir, over anything already mounted. Mount at "" to overlay the root,
+//   eg: mount the game's embedded assets first, then a directory of mods over them.
+func (v *FS) Mount(dir string, fsys fs.FS) {
+	v.mounts = append(v.mounts, mount{fsys: fsys, prefix: Clean(dir)})
+}
+
+// Unmount removes every mount of fsys, it must be comparable
+func (v *FS) Unmount(fsys fs.FS) {
+	mounts := v.mounts[:0]
+	for _, m := range v.mounts {
+		if m.fsys != fsys {
+			mounts = append(mounts, m)
+		}
+	}
+	v.mounts = mounts
+}
+
+// Open opens a file from the topmost mount that has it
+func (v *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for i := len(v.mounts) - 1; i >= 0; i-- {
+		rel, ok := v.mounts[i].rel(name)
+		if !ok {
+			continue
+		}
+		f, err := v.mounts[i].fsys.Open(rel)
+		if err == nil {
+			return f, nil
+		}
+		// Anything other than the file not being there is a real problem, don't hide it behind a lower mount
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat returns the file info of name from the topmost mount that has it
+func (v *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	for i := len(v.mounts) - 1; i >= 0; i-- {
+		rel, ok := v.mounts[i].rel(name)
+		if !ok {
+			continue
+		}
+		info, err := fs.Stat(v.mounts[i].fsys, rel)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile reads name from the topmost mount that has it
+func (v *FS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	for i := len(v.mounts) - 1; i >= 0; i-- {
+		rel, ok := v.mounts[i].rel(name)
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(v.mounts[i].fsys, rel)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists a directory across every mount, sorted by name. Where mounts have an entry of the same name,
+//   the topmost one's is listed. Directories that mounts are mounted in are listed too.
+func (v *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries := map[string]fs.DirEntry{}
+	found := false
+	for i := len(v.mounts) - 1; i >= 0; i-- {
+		m := v.mounts[i]
+		// A mount below this directory shows up as a directory in it
+		if child, ok := m.child(name); ok {
+			found = true
+			if _, ok := entries[child]; !ok {
+				entries[child] = mountDir(child)
+			}
+			continue
+		}
+		rel, ok := m.rel(name)
+		if !ok {
+			continue
+		}
+		list, err := fs.ReadDir(m.fsys, rel)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range list {
+			if _, ok := entries[entry.Name()]; !ok {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	list := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+// rel returns the path of name within the mount, or false if name isn't under it
+func (m mount) rel(name string) (string, bool) {
+	if m.prefix == "." {
+		return name, true
+	}
+	if name == m.prefix {
+		return ".", true
+	}
+	if strings.HasPrefix(name, m.prefix+"/") {
+		return name[len(m.prefix)+1:], true
+	}
+	return "", false
+}
+
+// child returns the name of the directory in dir that leads down to the mount, if the mount is below dir
+func (m mount) child(dir string) (string, bool) {
+	if m.prefix == "." || m.prefix == dir {
+		return "", false
+	}
+	rest := m.prefix
+	if dir != "." {
+		if !strings.HasPrefix(m.prefix, dir+"/") {
+			return "", false
+		}
+		rest = m.prefix[len(dir)+1:]
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest, true
+}
+
+// Clean turns an OS path into the slash separated form fs.FS names are in, eg: "./sprites/../assets/x.png" is "assets/x.png".
+//   Absolute paths stay absolute, only OS can open them.
+func Clean(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// Dir is the directory dir on disk
+func Dir(dir string) fs.FS {
+	return os.DirFS(dir)
+}
+
+// OS is the operating system's filesystem as is. Names are OS paths, relative to the working directory unless
+//   they're absolute, so it opens anything os.Open can. Use Dir to keep to one directory.
+func OS() fs.FS {
+	return osFS{}
+}
+
+// osFS opens files with os.Open
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(filepath.FromSlash(name))
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.FromSlash(name))
+}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(filepath.FromSlash(name))
+}
+
+// Zip opens a zip (or pak) archive on disk as a filesystem, close it once it's no longer mounted
+func Zip(path string) (*zip.ReadCloser, error) {
+	return zip.OpenReader(path)
+}
+
+// mountDir is the directory entry of a directory that only exists because something is mounted in it
+type mountDir string
+
+func (d mountDir) Name() string               { return string(d) }
+func (d mountDir) IsDir() bool                { return true }
+func (d mountDir) Type() fs.FileMode          { return fs.ModeDir }
+func (d mountDir) Info() (fs.FileInfo, error) { return mountInfo(d), nil }
+
+// mountInfo is the file info of a mountDir
+type mountInfo string
+
+func (i mountInfo) Name() string       { return string(i) }
+func (i mountInfo) Size() int64        { return 0 }
+func (i mountInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (i mountInfo) ModTime() time.Time { return time.Time{} }
+func (i mountInfo) IsDir() bool        { return true }
+func (i mountInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,261 @@
+package vfs
+
+import (
+	"archive/zip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func file(data string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(data)}
+}
+
+// writeZip writes a zip archive of files to a temporary directory and returns its path
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "test.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for path, data := range files {
+		fw, err := w.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func names(entries []fs.DirEntry) []string {
+	var list []string
+	for _, entry := range entries {
+		list = append(list, entry.Name())
+	}
+	return list
+}
+
+func TestMountPrecedence(t *testing.T) {
+	v := New()
+	v.Mount("", fstest.MapFS{
+		"levels/1.json": file("base 1"),
+		"levels/2.json": file("base 2"),
+		"player.png":    file("base player"),
+	})
+	v.Mount(".", fstest.MapFS{
+		"levels/2.json": file("mod 2"),
+		"levels/3.json": file("mod 3"),
+	})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"levels/1.json", "base 1"},
+		{"levels/2.json", "mod 2"},
+		{"levels/3.json", "mod 3"},
+		{"player.png", "base player"},
+	}
+	for _, test := range tests {
+		data, err := v.ReadFile(test.name)
+		if err != nil {
+			t.Errorf("ReadFile(%q) failed: %v", test.name, err)
+			continue
+		}
+		if string(data) != test.want {
+			t.Errorf("ReadFile(%q) = %q, want %q", test.name, data, test.want)
+		}
+		if data, err := fs.ReadFile(onlyOpen{v}, test.name); err != nil || string(data) != test.want {
+			t.Errorf("Open(%q) read %q, %v, want %q", test.name, data, err, test.want)
+		}
+		if info, err := v.Stat(test.name); err != nil || info.Size() != int64(len(test.want)) {
+			t.Errorf("Stat(%q) = %v, %v, want the size of %q", test.name, info, err, test.want)
+		}
+	}
+
+	entries, err := v.ReadDir("levels")
+	if err != nil {
+		t.Fatalf("ReadDir(levels) failed: %v", err)
+	}
+	if got, want := names(entries), []string{"1.json", "2.json", "3.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDir(levels) = %v, want %v", got, want)
+	}
+
+	if _, err := v.ReadFile("levels/4.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile of a missing file = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := v.ReadDir("music"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadDir of a missing directory = %v, want fs.ErrNotExist", err)
+	}
+}
+
+// onlyOpen hides everything but Open, so fs.ReadFile goes through FS.Open
+type onlyOpen struct {
+	fs.FS
+}
+
+func TestMountPrefix(t *testing.T) {
+	v := New()
+	v.Mount("", fstest.MapFS{
+		"mods/readme.txt": file("base readme"),
+	})
+	v.Mount("mods/fast", fstest.MapFS{
+		"player.json": file("fast player"),
+		"readme.txt":  file("fast readme"),
+	})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"mods/fast/player.json", "fast player"},
+		{"mods/fast/readme.txt", "fast readme"},
+		{"mods/readme.txt", "base readme"},
+	}
+	for _, test := range tests {
+		data, err := v.ReadFile(test.name)
+		if err != nil || string(data) != test.want {
+			t.Errorf("ReadFile(%q) = %q, %v, want %q", test.name, data, err, test.want)
+		}
+	}
+
+	// Names outside the prefix, or that only share its first letters, aren't looked for in the mount
+	for _, name := range []string{"player.json", "mods/player.json", "mods/fastest/player.json"} {
+		if _, err := v.ReadFile(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadFile(%q) = %v, want fs.ErrNotExist", name, err)
+		}
+	}
+
+	// Directories leading down to a mount are listed, even when nothing else is in them
+	listings := []struct {
+		dir  string
+		want []string
+	}{
+		{".", []string{"mods"}},
+		{"mods", []string{"fast", "readme.txt"}},
+		{"mods/fast", []string{"player.json", "readme.txt"}},
+	}
+	for _, test := range listings {
+		entries, err := v.ReadDir(test.dir)
+		if err != nil {
+			t.Errorf("ReadDir(%q) failed: %v", test.dir, err)
+			continue
+		}
+		if got := names(entries); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ReadDir(%q) = %v, want %v", test.dir, got, test.want)
+		}
+	}
+	entries, _ := v.ReadDir("mods")
+	if !entries[0].IsDir() {
+		t.Error("the directory a mount is in isn't listed as a directory")
+	}
+}
+
+func TestMountZip(t *testing.T) {
+	archive, err := Zip(writeZip(t, map[string]string{
+		"levels/1.json":   "zip 1",
+		"sprites/a.png":   "zip a",
+		"sprites/b/c.png": "zip c",
+	}))
+	if err != nil {
+		t.Fatalf("Zip failed: %v", err)
+	}
+	defer archive.Close()
+
+	v := New()
+	v.Mount("", fstest.MapFS{
+		"levels/1.json": file("base 1"),
+		"levels/2.json": file("base 2"),
+	})
+	v.Mount("", archive)
+	v.Mount("pak", archive)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"levels/1.json", "zip 1"},
+		{"levels/2.json", "base 2"},
+		{"sprites/b/c.png", "zip c"},
+		{"pak/sprites/a.png", "zip a"},
+	}
+	for _, test := range tests {
+		data, err := v.ReadFile(test.name)
+		if err != nil || string(data) != test.want {
+			t.Errorf("ReadFile(%q) = %q, %v, want %q", test.name, data, err, test.want)
+		}
+	}
+
+	entries, err := v.ReadDir("sprites")
+	if err != nil {
+		t.Fatalf("ReadDir(sprites) failed: %v", err)
+	}
+	if got, want := names(entries), []string{"a.png", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadDir(sprites) = %v, want %v", got, want)
+	}
+
+	// Unmounting the archive drops both of its mounts
+	v.Unmount(archive)
+	if data, _ := v.ReadFile("levels/1.json"); string(data) != "base 1" {
+		t.Errorf("levels/1.json = %q after unmounting the zip, want the base file", data)
+	}
+	if _, err := v.ReadFile("pak/sprites/a.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile from an unmounted zip = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "."},
+		{".", "."},
+		{"./sprites/player.png", "sprites/player.png"},
+		{"./sprites/../assets/x.png", "assets/x.png"},
+		{"levels//1.json", "levels/1.json"},
+		{"mods/", "mods"},
+		{"../outside.png", "../outside.png"},
+		{"a/../../outside.png", "../outside.png"},
+		{"/abs/../path.png", "/path.png"},
+		{filepath.Join("sprites", "player.png"), "sprites/player.png"},
+	}
+	for _, test := range tests {
+		if got := Clean(test.in); got != test.want {
+			t.Errorf("Clean(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestInvalidPath(t *testing.T) {
+	v := New()
+	v.Mount("", fstest.MapFS{"player.png": file("player")})
+
+	for _, name := range []string{"/player.png", "../player.png", "./player.png", "sprites/", ""} {
+		if _, err := v.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open(%q) = %v, want fs.ErrInvalid", name, err)
+		}
+		if _, err := v.Stat(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Stat(%q) = %v, want fs.ErrInvalid", name, err)
+		}
+		if _, err := v.ReadFile(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("ReadFile(%q) = %v, want fs.ErrInvalid", name, err)
+		}
+		if _, err := v.ReadDir(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("ReadDir(%q) = %v, want fs.ErrInvalid", name, err)
+		}
+	}
+}